@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/base-org/withdrawer/price"
+	"github.com/base-org/withdrawer/store"
+)
+
+// accountingTx is an L1 transaction that proved or finalized a withdrawal, with its cost, as exported by
+// the accounting subcommand.
+type accountingTx struct {
+	Withdrawal     common.Hash    `json:"withdrawal"`
+	WithdrawalHash common.Hash    `json:"withdrawalHash"`
+	Action         string         `json:"action"`
+	Tx             common.Hash    `json:"tx"`
+	TxURL          string         `json:"txUrl,omitempty"`
+	Block          uint64         `json:"block"`
+	Time           time.Time      `json:"time"`
+	From           common.Address `json:"from"`
+	GasUsed        uint64         `json:"gasUsed"`
+	// GasPrice and Fee are in wei and FeeETH in ETH, as exact decimal strings.
+	GasPrice string `json:"gasPrice"`
+	Fee      string `json:"fee"`
+	FeeETH   string `json:"feeEth"`
+	// ETHUSD and FeeUSD are only set with --usd-price.
+	ETHUSD *float64 `json:"ethUsd,omitempty"`
+	FeeUSD *float64 `json:"feeUsd,omitempty"`
+}
+
+// accountingOutput is the JSON printed by the accounting subcommand with --format json.
+type accountingOutput struct {
+	SchemaVersion int            `json:"schemaVersion"`
+	Transactions  []accountingTx `json:"transactions"`
+}
+
+// accountingHeader is the header row of the CSV export.
+var accountingHeader = []string{"withdrawal", "withdrawal_hash", "action", "l1_tx", "block", "time", "from", "gas_used", "gas_price_wei", "fee_wei", "fee_eth", "eth_usd", "fee_usd"}
+
+func (t accountingTx) csvRow() []string {
+	ethUSD, feeUSD := "", ""
+	if t.ETHUSD != nil {
+		ethUSD, feeUSD = fmt.Sprintf("%.2f", *t.ETHUSD), fmt.Sprintf("%.2f", *t.FeeUSD)
+	}
+	return []string{t.Withdrawal.Hex(), t.WithdrawalHash.Hex(), t.Action, t.Tx.Hex(), fmt.Sprint(t.Block), t.Time.Format(time.RFC3339),
+		t.From.Hex(), fmt.Sprint(t.GasUsed), t.GasPrice, t.Fee, t.FeeETH, ethUSD, feeUSD}
+}
+
+// runAccounting implements the accounting subcommand, which exports the prove and finalize transactions
+// of the withdrawals in a state store or journal with their fees and the ETH price at the time, for
+// reconciling the cost of withdrawing.
+func runAccounting(args []string) {
+	fs := flag.NewFlagSet("accounting", flag.ExitOnError)
+	f := registerFlags(fs)
+	sf := registerStoreFlags(fs)
+	var format, out, since, until string
+	fs.StringVar(&format, "format", "csv", "Export format (one of: csv, json)")
+	fs.StringVar(&out, "out", "", "File to write the export to (default: stdout)")
+	fs.StringVar(&since, "since", "", "Only export transactions mined on or after this UTC date, as YYYY-MM-DD")
+	fs.StringVar(&until, "until", "", "Only export transactions mined before this UTC date, as YYYY-MM-DD")
+	_ = fs.Parse(args)
+
+	if format != "csv" && format != "json" {
+		log.Crit("Invalid --format, expected csv or json", "value", format)
+	}
+	from, to := parseDate("--since", since), parseDate("--until", until)
+
+	n := f.resolveNetwork()
+	st := sf.open()
+	defer st.Close()
+	ctx := interruptContext()
+
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	feed := f.historicalPriceFeed(ctx, l1)
+
+	withdrawals, err := st.List()
+	if err != nil {
+		log.Crit("Error listing withdrawals", "error", err)
+	}
+	txs := []accountingTx{}
+	for _, w := range withdrawals {
+		for _, action := range []struct {
+			name string
+			tx   common.Hash
+		}{{"prove", w.ProveTx}, {"finalize", w.FinalizeTx}} {
+			if action.tx == (common.Hash{}) {
+				continue
+			}
+			t, err := newAccountingTx(ctx, l1, feed, w, action.name, action.tx)
+			if err != nil {
+				log.Crit("Error querying transaction", "withdrawal", w.TxHash, "tx", action.tx, "error", err)
+			}
+			if (!from.IsZero() && t.Time.Before(from)) || (!to.IsZero() && !t.Time.Before(to)) {
+				continue
+			}
+			t.TxURL = n.explorers().L1TxURL(t.Tx)
+			txs = append(txs, t)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Crit("Error creating export file", "error", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := writeAccounting(w, format, txs); err != nil {
+		log.Crit("Error writing export", "error", err)
+	}
+}
+
+// newAccountingTx queries the receipt, sender and block time of the action tx of w, and the ETH price
+// at its block if feed is set.
+func newAccountingTx(ctx context.Context, l1 *ethclient.Client, feed price.HistoricalFeed, w *store.Withdrawal, action string, hash common.Hash) (accountingTx, error) {
+	receipt, err := l1.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return accountingTx{}, fmt.Errorf("error querying receipt: %w", err)
+	}
+	tx, _, err := l1.TransactionByHash(ctx, hash)
+	if err != nil {
+		return accountingTx{}, fmt.Errorf("error querying transaction: %w", err)
+	}
+	sender, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return accountingTx{}, fmt.Errorf("error recovering sender: %w", err)
+	}
+	header, err := l1.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return accountingTx{}, fmt.Errorf("error querying block: %w", err)
+	}
+
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice)
+	feeETH, _ := new(big.Float).Quo(new(big.Float).SetInt(fee), big.NewFloat(params.Ether)).Float64()
+	t := accountingTx{
+		Withdrawal:     w.TxHash,
+		WithdrawalHash: w.WithdrawalHash,
+		Action:         action,
+		Tx:             hash,
+		Block:          receipt.BlockNumber.Uint64(),
+		Time:           time.Unix(int64(header.Time), 0).UTC(),
+		From:           sender,
+		GasUsed:        receipt.GasUsed,
+		GasPrice:       gasPrice.String(),
+		Fee:            fee.String(),
+		FeeETH:         new(big.Rat).SetFrac(fee, big.NewInt(params.Ether)).FloatString(18),
+	}
+	if feed != nil {
+		ethUSD, err := feed.ETHUSDAt(ctx, receipt.BlockNumber, t.Time)
+		if err != nil {
+			return accountingTx{}, fmt.Errorf("error querying ETH price: %w", err)
+		}
+		feeUSD := feeETH * ethUSD
+		t.ETHUSD, t.FeeUSD = &ethUSD, &feeUSD
+	}
+	return t, nil
+}
+
+func writeAccounting(w io.Writer, format string, txs []accountingTx) error {
+	if format == "json" {
+		data, err := json.Marshal(accountingOutput{SchemaVersion: schemaVersion, Transactions: txs})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	c := csv.NewWriter(w)
+	if err := c.Write(accountingHeader); err != nil {
+		return err
+	}
+	for _, t := range txs {
+		if err := c.Write(t.csvRow()); err != nil {
+			return err
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// historicalPriceFeed returns the feed of past ETH prices selected with --usd-price, or nil if none is.
+func (f *flags) historicalPriceFeed(ctx context.Context, l1 *ethclient.Client) price.HistoricalFeed {
+	switch f.usdPrice {
+	case "":
+		return nil
+	case "coingecko":
+		return price.NewCoinGeckoHistory(price.CoinGeckoHistoryURL)
+	case "chainlink":
+		feed, err := f.newPriceFeed()(ctx, l1)
+		if err != nil {
+			log.Crit("Error creating price feed", "error", err)
+		}
+		return feed.(price.HistoricalFeed)
+	default:
+		log.Crit("Past ETH prices are only available with --usd-price chainlink or coingecko", "value", f.usdPrice)
+		return nil
+	}
+}
+
+// parseDate parses a YYYY-MM-DD date flag as midnight UTC, or returns the zero time if it is empty.
+func parseDate(flag, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		log.Crit("Invalid date, expected YYYY-MM-DD", "flag", flag, "value", value)
+	}
+	return t
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// activityEvent is a prove or finalize event of the portal, as exported by the activity subcommand.
+type activityEvent struct {
+	Action         string         `json:"action"`
+	Block          uint64         `json:"block"`
+	Time           time.Time      `json:"time"`
+	Tx             common.Hash    `json:"tx"`
+	LogIndex       uint           `json:"logIndex"`
+	WithdrawalHash common.Hash    `json:"withdrawalHash"`
+	Submitter      common.Address `json:"submitter"`
+	Success        *bool          `json:"success,omitempty"`
+	// the withdrawal fields are only set if the transaction called the portal directly, with values in wei
+	// as decimal strings
+	Sender *common.Address `json:"sender,omitempty"`
+	Target *common.Address `json:"target,omitempty"`
+	Value  string          `json:"value,omitempty"`
+	// from and to are the L2 and L1 accounts the withdrawal is attributed to, decoding the messenger and
+	// standard bridge calls of withdrawals routed through them, e.g. by aggregators
+	From *common.Address `json:"from,omitempty"`
+	To   *common.Address `json:"to,omitempty"`
+	// the bridge fields are only set for transfers of the standard bridge, with a zero token for ETH
+	BridgeToken  *common.Address `json:"bridgeToken,omitempty"`
+	BridgeFrom   *common.Address `json:"bridgeFrom,omitempty"`
+	BridgeTo     *common.Address `json:"bridgeTo,omitempty"`
+	BridgeAmount string          `json:"bridgeAmount,omitempty"`
+}
+
+// activityOutput is the JSON printed by the activity subcommand with --format json.
+type activityOutput struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Events        []activityEvent `json:"events"`
+}
+
+// activityHeader is the header row of the CSV export.
+var activityHeader = []string{"action", "block", "time", "l1_tx", "log_index", "withdrawal_hash", "submitter", "success", "sender", "target", "value_wei", "from", "to", "bridge_token", "bridge_from", "bridge_to", "bridge_amount"}
+
+func newActivityEvent(e withdraw.PortalEvent) activityEvent {
+	a := activityEvent{
+		Action:         e.Action,
+		Block:          e.BlockNumber,
+		Time:           e.Time,
+		Tx:             e.TxHash,
+		LogIndex:       e.LogIndex,
+		WithdrawalHash: e.WithdrawalHash,
+		Submitter:      e.Submitter,
+	}
+	if e.Action == "finalize" {
+		a.Success = &e.Success
+	}
+	if w := e.Withdrawal; w != nil {
+		a.Sender, a.Target, a.Value = &w.Sender, &w.Target, w.Value.String()
+	}
+	if r := e.Route; r != nil {
+		from, to := r.From(), r.To()
+		a.From, a.To = &from, &to
+		if b := r.Bridged; b != nil {
+			a.BridgeToken, a.BridgeFrom, a.BridgeTo, a.BridgeAmount = &b.Token, &b.From, &b.To, b.Amount.String()
+		}
+	}
+	return a
+}
+
+func (a activityEvent) csvRow() []string {
+	address := func(addr *common.Address) string {
+		if addr == nil {
+			return ""
+		}
+		return addr.Hex()
+	}
+	success := ""
+	if a.Success != nil {
+		success = fmt.Sprint(*a.Success)
+	}
+	return []string{a.Action, fmt.Sprint(a.Block), a.Time.Format(time.RFC3339), a.Tx.Hex(), fmt.Sprint(a.LogIndex), a.WithdrawalHash.Hex(),
+		a.Submitter.Hex(), success, address(a.Sender), address(a.Target), a.Value, address(a.From), address(a.To), address(a.BridgeToken), address(a.BridgeFrom), address(a.BridgeTo), a.BridgeAmount}
+}
+
+// runActivity implements the activity subcommand, which exports every prove and finalize event of the
+// portal over a range of L1 blocks, with the amounts withdrawn, for analytics and dashboards.
+func runActivity(args []string) {
+	fs := flag.NewFlagSet("activity", flag.ExitOnError)
+	f := registerFlags(fs)
+	var fromBlock, toBlock, blockRange uint64
+	var format, out string
+	fs.Uint64Var(&fromBlock, "from-block", 0, "First L1 block to export the events of (default: a day before --to-block)")
+	fs.Uint64Var(&toBlock, "to-block", 0, "Last L1 block to export the events of (default: the latest block)")
+	fs.Uint64Var(&blockRange, "block-range", 10000, "Maximum number of L1 blocks to query per eth_getLogs request")
+	fs.StringVar(&format, "format", "csv", "Export format (one of: csv, json, ndjson)")
+	fs.StringVar(&out, "out", "", "File to write the export to (default: stdout)")
+	_ = fs.Parse(args)
+
+	if format != "csv" && format != "json" && format != "ndjson" {
+		log.Crit("Invalid --format, expected csv, json or ndjson", "value", format)
+	}
+	if blockRange == 0 {
+		log.Crit("--block-range must be at least 1")
+	}
+	n := f.resolveNetwork()
+	ctx := interruptContext()
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	if toBlock == 0 {
+		if toBlock, err = l1.BlockNumber(ctx); err != nil {
+			log.Crit("Error querying L1 head", "error", err)
+		}
+	}
+	if fromBlock == 0 && toBlock > 7200 {
+		// 7200 blocks of 12 seconds
+		fromBlock = toBlock - 7200
+	}
+	if fromBlock > toBlock {
+		log.Crit("--from-block must not be after --to-block", "from", fromBlock, "to", toBlock)
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Crit("Error creating export file", "error", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	// csv and ndjson are written as the range is scanned, json once it is done
+	c := csv.NewWriter(w)
+	if format == "csv" {
+		if err := c.Write(activityHeader); err != nil {
+			log.Crit("Error writing export", "error", err)
+		}
+	}
+	events := []activityEvent{}
+	err = withdraw.PortalActivity(ctx, l1, n.PortalAddress, fromBlock, toBlock, blockRange, func(e withdraw.PortalEvent) error {
+		a := newActivityEvent(e)
+		switch format {
+		case "csv":
+			if err := c.Write(a.csvRow()); err != nil {
+				return err
+			}
+			c.Flush()
+			return c.Error()
+		case "ndjson":
+			data, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(append(data, '\n'))
+			return err
+		}
+		events = append(events, a)
+		return nil
+	})
+	if err != nil {
+		log.Crit("Error exporting portal activity", "error", err)
+	}
+	if format == "json" {
+		data, err := json.Marshal(activityOutput{SchemaVersion: schemaVersion, Events: events})
+		if err != nil {
+			log.Crit("Error encoding export", "error", err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			log.Crit("Error writing export", "error", err)
+		}
+	}
+	c.Flush()
+	if out != "" {
+		fmt.Fprintf(os.Stderr, "Exported portal activity of L1 blocks %d-%d to %s\n", fromBlock, toBlock, out)
+	}
+}
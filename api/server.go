@@ -0,0 +1,123 @@
+// Package api implements the gRPC API of the withdrawer daemon, defined in proto/withdrawer/v1.
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/base-org/withdrawer/api/withdrawerv1"
+	"github.com/base-org/withdrawer/daemon"
+	"github.com/base-org/withdrawer/store"
+)
+
+// Server implements withdrawerv1.WithdrawerServiceServer on top of a daemon and its store.
+type Server struct {
+	withdrawerv1.UnimplementedWithdrawerServiceServer
+	store  store.Store
+	daemon *daemon.Daemon
+}
+
+// NewServer creates a Server that tracks submitted withdrawals with d and reads their state from s.
+func NewServer(s store.Store, d *daemon.Daemon) *Server {
+	return &Server{store: s, daemon: d}
+}
+
+func (s *Server) SubmitWithdrawal(ctx context.Context, req *withdrawerv1.SubmitWithdrawalRequest) (*withdrawerv1.SubmitWithdrawalResponse, error) {
+	txHash, err := parseTxHash(req.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.daemon.Track(txHash); err != nil {
+		return nil, status.Errorf(codes.Internal, "tracking withdrawal: %v", err)
+	}
+	w, err := s.store.Get(txHash)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading withdrawal: %v", err)
+	}
+	return &withdrawerv1.SubmitWithdrawalResponse{Withdrawal: toProto(w)}, nil
+}
+
+func (s *Server) GetWithdrawal(ctx context.Context, req *withdrawerv1.GetWithdrawalRequest) (*withdrawerv1.GetWithdrawalResponse, error) {
+	txHash, err := parseTxHash(req.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	w, err := s.store.Get(txHash)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "withdrawal %s is not tracked", txHash)
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading withdrawal: %v", err)
+	}
+	return &withdrawerv1.GetWithdrawalResponse{Withdrawal: toProto(w)}, nil
+}
+
+func (s *Server) ListWithdrawals(ctx context.Context, req *withdrawerv1.ListWithdrawalsRequest) (*withdrawerv1.ListWithdrawalsResponse, error) {
+	withdrawals, err := s.store.List()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "listing withdrawals: %v", err)
+	}
+	resp := &withdrawerv1.ListWithdrawalsResponse{}
+	for _, w := range withdrawals {
+		pw := toProto(w)
+		if req.Status != withdrawerv1.WithdrawalStatus_WITHDRAWAL_STATUS_UNSPECIFIED && pw.Status != req.Status {
+			continue
+		}
+		resp.Withdrawals = append(resp.Withdrawals, pw)
+	}
+	return resp, nil
+}
+
+func parseTxHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, status.Errorf(codes.InvalidArgument, "invalid tx hash %q", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+var statuses = map[store.Status]withdrawerv1.WithdrawalStatus{
+	store.StatusPending:    withdrawerv1.WithdrawalStatus_WITHDRAWAL_STATUS_PENDING,
+	store.StatusProven:     withdrawerv1.WithdrawalStatus_WITHDRAWAL_STATUS_PROVEN,
+	store.StatusFinalized:  withdrawerv1.WithdrawalStatus_WITHDRAWAL_STATUS_FINALIZED,
+	store.StatusDeadLetter: withdrawerv1.WithdrawalStatus_WITHDRAWAL_STATUS_DEAD_LETTER,
+}
+
+func toProto(w *store.Withdrawal) *withdrawerv1.Withdrawal {
+	return &withdrawerv1.Withdrawal{
+		TxHash:         w.TxHash.Hex(),
+		WithdrawalHash: hashString(w.WithdrawalHash),
+		Status:         statuses[w.Status],
+		ProveTx:        hashString(w.ProveTx),
+		FinalizeTx:     hashString(w.FinalizeTx),
+		CreatedAt:      timestamp(w.CreatedAt),
+		UpdatedAt:      timestamp(w.UpdatedAt),
+		ProvenAt:       timestamp(w.ProvenAt),
+		FinalizedAt:    timestamp(w.FinalizedAt),
+		LastError:      w.LastError,
+		Attempts:       int32(w.Attempts),
+		NextAttemptAt:  timestamp(w.NextAttemptAt),
+	}
+}
+
+// hashString returns h as hex, or an empty string for the zero hash.
+func hashString(h common.Hash) string {
+	if h == (common.Hash{}) {
+		return ""
+	}
+	return h.Hex()
+}
+
+// timestamp returns t as a protobuf timestamp, or nil for the zero time.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
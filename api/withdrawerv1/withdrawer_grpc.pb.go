@@ -0,0 +1,198 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: withdrawer/v1/withdrawer.proto
+
+package withdrawerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	WithdrawerService_SubmitWithdrawal_FullMethodName = "/withdrawer.v1.WithdrawerService/SubmitWithdrawal"
+	WithdrawerService_GetWithdrawal_FullMethodName    = "/withdrawer.v1.WithdrawerService/GetWithdrawal"
+	WithdrawerService_ListWithdrawals_FullMethodName  = "/withdrawer.v1.WithdrawerService/ListWithdrawals"
+)
+
+// WithdrawerServiceClient is the client API for WithdrawerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WithdrawerService exposes the withdrawals tracked by a withdrawer daemon.
+type WithdrawerServiceClient interface {
+	// SubmitWithdrawal starts tracking a withdrawal, which the daemon then proves and finalizes.
+	// Submitting a dead-lettered withdrawal again requeues it.
+	SubmitWithdrawal(ctx context.Context, in *SubmitWithdrawalRequest, opts ...grpc.CallOption) (*SubmitWithdrawalResponse, error)
+	// GetWithdrawal returns the state of a tracked withdrawal.
+	GetWithdrawal(ctx context.Context, in *GetWithdrawalRequest, opts ...grpc.CallOption) (*GetWithdrawalResponse, error)
+	// ListWithdrawals returns all tracked withdrawals, optionally filtered by status.
+	ListWithdrawals(ctx context.Context, in *ListWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error)
+}
+
+type withdrawerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWithdrawerServiceClient(cc grpc.ClientConnInterface) WithdrawerServiceClient {
+	return &withdrawerServiceClient{cc}
+}
+
+func (c *withdrawerServiceClient) SubmitWithdrawal(ctx context.Context, in *SubmitWithdrawalRequest, opts ...grpc.CallOption) (*SubmitWithdrawalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitWithdrawalResponse)
+	err := c.cc.Invoke(ctx, WithdrawerService_SubmitWithdrawal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *withdrawerServiceClient) GetWithdrawal(ctx context.Context, in *GetWithdrawalRequest, opts ...grpc.CallOption) (*GetWithdrawalResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWithdrawalResponse)
+	err := c.cc.Invoke(ctx, WithdrawerService_GetWithdrawal_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *withdrawerServiceClient) ListWithdrawals(ctx context.Context, in *ListWithdrawalsRequest, opts ...grpc.CallOption) (*ListWithdrawalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWithdrawalsResponse)
+	err := c.cc.Invoke(ctx, WithdrawerService_ListWithdrawals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WithdrawerServiceServer is the server API for WithdrawerService service.
+// All implementations must embed UnimplementedWithdrawerServiceServer
+// for forward compatibility
+//
+// WithdrawerService exposes the withdrawals tracked by a withdrawer daemon.
+type WithdrawerServiceServer interface {
+	// SubmitWithdrawal starts tracking a withdrawal, which the daemon then proves and finalizes.
+	// Submitting a dead-lettered withdrawal again requeues it.
+	SubmitWithdrawal(context.Context, *SubmitWithdrawalRequest) (*SubmitWithdrawalResponse, error)
+	// GetWithdrawal returns the state of a tracked withdrawal.
+	GetWithdrawal(context.Context, *GetWithdrawalRequest) (*GetWithdrawalResponse, error)
+	// ListWithdrawals returns all tracked withdrawals, optionally filtered by status.
+	ListWithdrawals(context.Context, *ListWithdrawalsRequest) (*ListWithdrawalsResponse, error)
+	mustEmbedUnimplementedWithdrawerServiceServer()
+}
+
+// UnimplementedWithdrawerServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedWithdrawerServiceServer struct {
+}
+
+func (UnimplementedWithdrawerServiceServer) SubmitWithdrawal(context.Context, *SubmitWithdrawalRequest) (*SubmitWithdrawalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitWithdrawal not implemented")
+}
+func (UnimplementedWithdrawerServiceServer) GetWithdrawal(context.Context, *GetWithdrawalRequest) (*GetWithdrawalResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWithdrawal not implemented")
+}
+func (UnimplementedWithdrawerServiceServer) ListWithdrawals(context.Context, *ListWithdrawalsRequest) (*ListWithdrawalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWithdrawals not implemented")
+}
+func (UnimplementedWithdrawerServiceServer) mustEmbedUnimplementedWithdrawerServiceServer() {}
+
+// UnsafeWithdrawerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WithdrawerServiceServer will
+// result in compilation errors.
+type UnsafeWithdrawerServiceServer interface {
+	mustEmbedUnimplementedWithdrawerServiceServer()
+}
+
+func RegisterWithdrawerServiceServer(s grpc.ServiceRegistrar, srv WithdrawerServiceServer) {
+	s.RegisterService(&WithdrawerService_ServiceDesc, srv)
+}
+
+func _WithdrawerService_SubmitWithdrawal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitWithdrawalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WithdrawerServiceServer).SubmitWithdrawal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WithdrawerService_SubmitWithdrawal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WithdrawerServiceServer).SubmitWithdrawal(ctx, req.(*SubmitWithdrawalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WithdrawerService_GetWithdrawal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWithdrawalRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WithdrawerServiceServer).GetWithdrawal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WithdrawerService_GetWithdrawal_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WithdrawerServiceServer).GetWithdrawal(ctx, req.(*GetWithdrawalRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WithdrawerService_ListWithdrawals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWithdrawalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WithdrawerServiceServer).ListWithdrawals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WithdrawerService_ListWithdrawals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WithdrawerServiceServer).ListWithdrawals(ctx, req.(*ListWithdrawalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WithdrawerService_ServiceDesc is the grpc.ServiceDesc for WithdrawerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WithdrawerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "withdrawer.v1.WithdrawerService",
+	HandlerType: (*WithdrawerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitWithdrawal",
+			Handler:    _WithdrawerService_SubmitWithdrawal_Handler,
+		},
+		{
+			MethodName: "GetWithdrawal",
+			Handler:    _WithdrawerService_GetWithdrawal_Handler,
+		},
+		{
+			MethodName: "ListWithdrawals",
+			Handler:    _WithdrawerService_ListWithdrawals_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "withdrawer/v1/withdrawer.proto",
+}
@@ -0,0 +1,487 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/price"
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// batchResult is the outcome of a step of one withdrawal in a batch run.
+type batchResult struct {
+	stepResult
+	// GasUsed and Fee are the gas used by the sent transaction and the fee paid for it, in wei, once
+	// confirmed.
+	GasUsed uint64   `json:"gasUsed,omitempty"`
+	Fee     *big.Int `json:"fee,omitempty"`
+	// Error is why the step failed, or why no action was taken.
+	Error string `json:"error,omitempty"`
+	// failed is whether the step failed, and code the exit code of its error.
+	failed bool
+	code   int
+}
+
+// batchHeader is the header row of the CSV file written with --out.
+var batchHeader = []string{"withdrawal", "action", "l1_tx", "gas_used", "status", "error"}
+
+func (r batchResult) csvRow() []string {
+	tx, gasUsed := "", ""
+	if r.Tx != nil {
+		tx = r.Tx.Hex()
+	}
+	if r.GasUsed > 0 {
+		gasUsed = fmt.Sprint(r.GasUsed)
+	}
+	return []string{r.Withdrawal.Hex(), r.Action, tx, gasUsed, r.Status, r.Error}
+}
+
+// runBatch implements the batch subcommand, which performs at most one step of each of a list of
+// withdrawals, printing the result of each as JSON and optionally writing them to a CSV file.
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	f := registerFlags(fs)
+	var withdrawalsFlag, withdrawalsFile, out, reportPath, checkpointPath, failuresPath string
+	var resume bool
+	var workers int
+	fs.StringVar(&withdrawalsFlag, "withdrawals", "", "Comma-separated TX hashes of L2 withdrawal transactions to process (in addition to --withdrawal)")
+	fs.StringVar(&withdrawalsFile, "withdrawals-file", "", "File listing TX hashes of L2 withdrawal transactions to process, one per line")
+	fs.StringVar(&out, "out", "", "CSV file to write one row per withdrawal to (withdrawal, action, l1_tx, gas_used, status, error)")
+	fs.StringVar(&reportPath, "report", "", "Markdown file to write a report of the run to, with tables of proven, finalized, skipped and failed withdrawals")
+	fs.StringVar(&checkpointPath, "checkpoint", defaultCheckpointPath(), "File recording the withdrawals handled by the run so far, so that an interrupted run can be resumed with --resume")
+	fs.StringVar(&failuresPath, "failures", "", "JSON file to write the list of the withdrawals whose step failed to, with their error and exit code")
+	fs.BoolVar(&resume, "resume", false, "Resume the interrupted run recorded in --checkpoint, skipping the withdrawals it already handled")
+	fs.IntVar(&workers, "workers", 1, "Number of withdrawals to process concurrently, sharing the signer's nonces")
+	_ = fs.Parse(args)
+
+	if workers < 1 {
+		log.Crit("--workers must be at least 1")
+	}
+	if workers > 1 {
+		if f.nonce != "" {
+			log.Crit("--nonce can't be used with more than one --workers, as each withdrawal sends its own transaction")
+		}
+		if f.txMgr {
+			log.Crit("--txmgr can't be used with more than one --workers, as each tx manager tracks the nonce itself")
+		}
+		if f.pendingTxs == pendingReplace {
+			log.Crit("--pending-txs replace can't be used with more than one --workers")
+		}
+	}
+
+	withdrawals, err := batchWithdrawals(f.withdrawal, withdrawalsFlag, withdrawalsFile)
+	if err != nil {
+		log.Crit("Error reading withdrawals", "error", err)
+	}
+	if len(withdrawals) == 0 {
+		log.Crit("No withdrawals to process, pass --withdrawal, --withdrawals or --withdrawals-file")
+	}
+
+	n := f.resolveNetwork()
+	s := f.createSigner()
+
+	journal := f.openJournal()
+	defer journal.Store.Close()
+
+	checkpoint := &batchCheckpoint{path: checkpointPath, Network: f.network}
+	if resume {
+		c, err := loadCheckpoint(checkpointPath, withdrawals)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			log.Warn("No checkpoint to resume from, starting a new run", "checkpoint", checkpointPath)
+		case err != nil:
+			log.Crit("Error reading checkpoint", "checkpoint", checkpointPath, "error", err)
+		case c.Network != f.network:
+			log.Crit("Checkpoint is of a run on another network", "checkpoint", checkpointPath, "network", c.Network)
+		default:
+			checkpoint = c
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(checkpointPath), 0o700); err != nil {
+		log.Crit("Error creating checkpoint directory", "error", err)
+	}
+	// a new run starts from an empty checkpoint, so a stale one can't be resumed by mistake
+	if err := checkpoint.save(); err != nil {
+		log.Crit("Error writing checkpoint", "error", err)
+	}
+
+	var csvOut *csv.Writer
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Crit("Error creating results file", "error", err)
+		}
+		defer file.Close()
+		csvOut = csv.NewWriter(file)
+		writeCSVRow(csvOut, batchHeader)
+	}
+	// the results of the withdrawals handled before the interruption are carried over, so the outputs
+	// cover the whole run
+	handled := make(map[common.Hash]bool)
+	for _, r := range checkpoint.Results {
+		handled[r.Withdrawal] = true
+		if csvOut != nil {
+			writeCSVRow(csvOut, r.csvRow())
+		}
+	}
+	if len(handled) > 0 {
+		log.Info("Resuming batch, skipping the withdrawals already handled", "handled", len(handled), "remaining", len(withdrawals)-len(handled))
+	}
+
+	ctx := interruptContext()
+	// the results are printed as JSON, so stdout is kept free of progress messages
+	hc := f.helperConfig(journal)
+	hc.logger = withdraw.NewWriterLogger(os.Stderr)
+	// the helpers of all withdrawals share the clients
+	clients, err := dialHelperClients(ctx, f.rpc, n, s, hc)
+	if err != nil {
+		log.Crit("Error dialing clients", "error", err)
+	}
+	defer clients.Close()
+	if workers > 1 {
+		// the pending transactions of the signer are checked once, after which the nonce manager assigns
+		// the nonces of the transactions of all withdrawals
+		if _, _, err := checkPendingTxs(ctx, clients.l1, s.Address(), hc.pendingTxs, hc.pollInterval); err != nil {
+			log.Crit("Error checking pending transactions", "error", err)
+		}
+		hc.nonces = withdraw.NewNonceManager(clients.l1, s.Address())
+	}
+	var feed price.Feed
+	if hc.newPriceFeed != nil {
+		if feed, err = hc.newPriceFeed(ctx, clients.l1); err != nil {
+			log.Crit("Error creating price feed", "error", err)
+		}
+	}
+
+	report := batchReport{
+		network:   f.network,
+		explorers: n.explorers(),
+		started:   time.Now(),
+		results:   append([]batchResult(nil), checkpoint.Results...),
+	}
+	var pending []common.Hash
+	for _, withdrawal := range withdrawals {
+		if !handled[withdrawal] {
+			pending = append(pending, withdrawal)
+		}
+	}
+	// withdrawals are processed concurrently by the workers, their results recorded one at a time
+	jobs := make(chan common.Hash)
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < min(workers, len(pending)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for withdrawal := range jobs {
+				results <- batchStep(ctx, clients, withdrawal, n, s, hc, f.noWait, report.explorers)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, withdrawal := range pending {
+			// the remaining withdrawals would fail the same way once the run is interrupted
+			if ctx.Err() != nil {
+				return
+			}
+			jobs <- withdrawal
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	// a failing withdrawal doesn't stop the run, its error is recorded and the next one processed
+	for r := range results {
+		if csvOut != nil {
+			writeCSVRow(csvOut, r.csvRow())
+		}
+		report.results = append(report.results, r)
+		if r.failed {
+			continue
+		}
+		if err := checkpoint.add(r); err != nil {
+			log.Crit("Error writing checkpoint", "error", err)
+		}
+		printJSON(r.stepResult)
+	}
+
+	printGasSummary(ctx, feed, report.results)
+	if reportPath != "" {
+		report.finished = time.Now()
+		file, err := os.Create(reportPath)
+		if err != nil {
+			log.Crit("Error creating report file", "error", err)
+		}
+		err = report.write(file)
+		file.Close()
+		if err != nil {
+			log.Crit("Error writing report file", "error", err)
+		}
+	}
+	failures := batchFailures{SchemaVersion: schemaVersion, Network: f.network, Total: len(withdrawals), Failures: []batchFailure{}}
+	for _, r := range report.results {
+		if r.failed {
+			failures.Failures = append(failures.Failures, batchFailure{Withdrawal: r.Withdrawal, Error: r.Error, Code: r.code})
+		}
+	}
+	if failuresPath != "" {
+		data, err := json.MarshalIndent(failures, "", "  ")
+		if err != nil {
+			log.Crit("Error encoding failures", "error", err)
+		}
+		if err := os.WriteFile(failuresPath, append(data, '\n'), 0o644); err != nil {
+			log.Crit("Error writing failures file", "error", err)
+		}
+	}
+	if len(failures.Failures) > 0 {
+		fmt.Fprintf(os.Stderr, "\n%d of %d withdrawals failed:\n", len(failures.Failures), failures.Total)
+		for _, fl := range failures.Failures {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", fl.Withdrawal, fl.Error)
+		}
+		os.Exit(1)
+	}
+}
+
+// batchStep performs at most one step of a withdrawal of a batch run, with a helper using clients.
+func batchStep(ctx context.Context, clients *helperClients, withdrawal common.Hash, n network, s signer.Signer, hc helperConfig, noWait bool, explorers notify.Explorers) batchResult {
+	var res stepResult
+	helper, err := newWithdrawHelper(ctx, clients, withdrawal, n, s, hc)
+	if err != nil {
+		res = stepResult{SchemaVersion: schemaVersion, Withdrawal: withdrawal, Status: "pending"}
+		err = &stepError{"Error creating withdrawer", err}
+	} else {
+		res, err = step(ctx, helper, withdrawal, noWait, explorers)
+	}
+	r := batchResult{stepResult: res, Error: res.Reason}
+	if err != nil {
+		r.Error = err.Error()
+		r.failed = true
+		r.code = exitCode(err)
+		log.Error("Error processing withdrawal", "withdrawal", withdrawal, "error", err)
+	}
+	if r.Tx != nil && !noWait {
+		if receipt, err := clients.l1.TransactionReceipt(ctx, *r.Tx); err == nil {
+			r.GasUsed = receipt.GasUsed
+			r.Fee = new(big.Int)
+			if receipt.EffectiveGasPrice != nil {
+				r.Fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+			}
+		} else {
+			log.Warn("Error querying receipt", "tx", r.Tx, "error", err)
+		}
+	}
+	return r
+}
+
+// gasSpend is the gas used and fees paid by the confirmed transactions of a batch run for an action,
+// or for all of them.
+type gasSpend struct {
+	// action is empty for the total.
+	action  string
+	label   string
+	txs     int
+	gasUsed uint64
+	fees    *big.Int
+}
+
+// average returns the average fee paid per transaction.
+func (s gasSpend) average() *big.Int {
+	if s.txs == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(s.fees, big.NewInt(int64(s.txs)))
+}
+
+// gasSpends sums the gas used and fees paid by the transactions of results, for prove and finalize
+// transactions and in total.
+func gasSpends(results []batchResult) []gasSpend {
+	spends := []gasSpend{{action: "prove", label: "Prove"}, {action: "finalize", label: "Finalize"}, {label: "Total"}}
+	for i := range spends {
+		spends[i].fees = new(big.Int)
+	}
+	for _, r := range results {
+		if r.Fee == nil {
+			continue
+		}
+		for i := range spends {
+			if spends[i].action == r.Action || spends[i].action == "" {
+				spends[i].txs++
+				spends[i].gasUsed += r.GasUsed
+				spends[i].fees.Add(spends[i].fees, r.Fee)
+			}
+		}
+	}
+	return spends
+}
+
+// printGasSummary prints the gas used and fees paid by the confirmed transactions of a batch run to
+// stderr, for operators to track the cost of withdrawing. Fees are also reported in USD with a feed.
+func printGasSummary(ctx context.Context, feed price.Feed, results []batchResult) {
+	fmt.Fprintln(os.Stderr)
+	for _, s := range gasSpends(results) {
+		if s.action == "" {
+			fmt.Fprintf(os.Stderr, "Total:     %d gas, %s in fees for %d txs\n", s.gasUsed, price.FormatETH(ctx, feed, s.fees), s.txs)
+		} else if s.txs > 0 {
+			fmt.Fprintf(os.Stderr, "%-10s %d gas, %s in fees for %d txs, %s on average\n", s.label+":",
+				s.gasUsed, price.FormatETH(ctx, feed, s.fees), s.txs, price.FormatETH(ctx, feed, s.average()))
+		}
+	}
+}
+
+// writeCSVRow writes and flushes a row, so that the rows written so far survive an interrupted run.
+func writeCSVRow(w *csv.Writer, row []string) {
+	if err := w.Write(row); err != nil {
+		log.Crit("Error writing results file", "error", err)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Crit("Error writing results file", "error", err)
+	}
+}
+
+// batchCheckpoint is the progress of a batch run: the results of the withdrawals it handled, saved to
+// --checkpoint after each of them. Failed steps aren't recorded, so that resuming retries them.
+type batchCheckpoint struct {
+	Network string        `json:"network"`
+	Results []batchResult `json:"results"`
+
+	path string
+}
+
+// defaultCheckpointPath returns the checkpoint location in the user's cache directory, or in the working
+// directory if there is none.
+func defaultCheckpointPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "withdrawer-batch-checkpoint.json"
+	}
+	return filepath.Join(dir, "withdrawer", "batch-checkpoint.json")
+}
+
+// loadCheckpoint reads the checkpoint at path, keeping the results of the given withdrawals only.
+func loadCheckpoint(path string, withdrawals []common.Hash) (*batchCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c batchCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error decoding checkpoint: %w", err)
+	}
+	listed := make(map[common.Hash]bool, len(withdrawals))
+	for _, w := range withdrawals {
+		listed[w] = true
+	}
+	results := c.Results[:0]
+	for _, r := range c.Results {
+		if listed[r.Withdrawal] {
+			results = append(results, r)
+		}
+	}
+	c.Results, c.path = results, path
+	return &c, nil
+}
+
+// add records the result of a handled withdrawal.
+func (c *batchCheckpoint) add(r batchResult) error {
+	c.Results = append(c.Results, r)
+	return c.save()
+}
+
+// save writes the checkpoint to a temporary file and renames it over the checkpoint file, so an
+// interruption mid-write never leaves a corrupt checkpoint behind.
+func (c *batchCheckpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// batchFailures is the machine-readable list of the withdrawals whose step failed in a batch run, written
+// with --failures.
+type batchFailures struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Network       string `json:"network"`
+	// Total is the number of withdrawals of the run, including those handled before a resume.
+	Total    int            `json:"total"`
+	Failures []batchFailure `json:"failures"`
+}
+
+// batchFailure is a withdrawal whose step failed.
+type batchFailure struct {
+	Withdrawal common.Hash `json:"withdrawal"`
+	Error      string      `json:"error"`
+	// Code is the exit code the step subcommand exits with for the error.
+	Code int `json:"code"`
+}
+
+// batchWithdrawals returns the withdrawals passed with --withdrawal, --withdrawals and --withdrawals-file,
+// in order and without duplicates.
+func batchWithdrawals(withdrawal, withdrawals, path string) ([]common.Hash, error) {
+	hashes := []string{withdrawal}
+	hashes = append(hashes, strings.Split(withdrawals, ",")...)
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			hashes = append(hashes, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	var result []common.Hash
+	seen := make(map[common.Hash]bool)
+	for _, h := range hashes {
+		if h = strings.TrimSpace(h); h == "" || strings.HasPrefix(h, "#") {
+			continue
+		}
+		b, err := hexutil.Decode(h)
+		if err != nil || len(b) != common.HashLength {
+			return nil, fmt.Errorf("invalid withdrawal tx hash %q", h)
+		}
+		hash := common.BytesToHash(b)
+		if !seen[hash] {
+			seen[hash] = true
+			result = append(result, hash)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBatchCheckpoint(t *testing.T) {
+	a, b, c := common.HexToHash("0xa"), common.HexToHash("0xb"), common.HexToHash("0xc")
+	tx := common.HexToHash("0x1")
+	proved := batchResult{
+		stepResult: stepResult{SchemaVersion: schemaVersion, Withdrawal: a, Status: "proven", Action: "prove", Tx: &tx},
+		GasUsed:    100_000,
+		Fee:        big.NewInt(1e15),
+	}
+	waiting := batchResult{
+		stepResult: stepResult{SchemaVersion: schemaVersion, Withdrawal: b, Status: "proven", Reason: "challenge period is active"},
+		Error:      "challenge period is active",
+	}
+	tests := []struct {
+		name string
+		// saved are the results of the interrupted run, and withdrawals those of the resumed one.
+		saved       []batchResult
+		withdrawals []common.Hash
+		want        []common.Hash
+	}{
+		{
+			name:        "resume",
+			saved:       []batchResult{proved, waiting},
+			withdrawals: []common.Hash{a, b, c},
+			want:        []common.Hash{a, b},
+		},
+		{
+			name:        "withdrawals no longer listed",
+			saved:       []batchResult{proved, waiting},
+			withdrawals: []common.Hash{b, c},
+			want:        []common.Hash{b},
+		},
+		{
+			name:        "nothing handled",
+			withdrawals: []common.Hash{a, c},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+			checkpoint := &batchCheckpoint{Network: "base-mainnet", path: path}
+			if err := checkpoint.save(); err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tt.saved {
+				if err := checkpoint.add(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			loaded, err := loadCheckpoint(path, tt.withdrawals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Network != "base-mainnet" {
+				t.Fatalf("network = %q, want base-mainnet", loaded.Network)
+			}
+			if len(loaded.Results) != len(tt.want) {
+				t.Fatalf("loaded %d results, want %d", len(loaded.Results), len(tt.want))
+			}
+			for i, r := range loaded.Results {
+				if r.Withdrawal != tt.want[i] {
+					t.Fatalf("result %d is of %s, want %s", i, r.Withdrawal, tt.want[i])
+				}
+				if r.Withdrawal == a && (r.Tx == nil || *r.Tx != tx || r.GasUsed != proved.GasUsed || r.Fee.Cmp(proved.Fee) != 0) {
+					t.Fatalf("result of %s = %+v, want %+v", a, r, proved)
+				}
+			}
+
+			// the resumed run keeps adding to the loaded checkpoint
+			next := batchResult{stepResult: stepResult{SchemaVersion: schemaVersion, Withdrawal: c, Status: "pending"}}
+			if err := loaded.add(next); err != nil {
+				t.Fatal(err)
+			}
+			reloaded, err := loadCheckpoint(path, tt.withdrawals)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := len(reloaded.Results); n != len(tt.want)+1 || reloaded.Results[n-1].Withdrawal != c {
+				t.Fatalf("reloaded %+v, want %d results ending with %s", reloaded.Results, len(tt.want)+1, c)
+			}
+			// no temporary files are left behind
+			entries, err := os.ReadDir(filepath.Dir(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("checkpoint directory has %d entries, want 1", len(entries))
+			}
+		})
+	}
+}
+
+func TestLoadCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		path     string
+		notExist bool
+	}{
+		{name: "missing", path: filepath.Join(dir, "missing.json"), notExist: true},
+		{name: "corrupt", path: corrupt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadCheckpoint(tt.path, nil)
+			if err == nil {
+				t.Fatal("loadCheckpoint() error = nil, want an error")
+			}
+			if errors.Is(err, os.ErrNotExist) != tt.notExist {
+				t.Fatalf("loadCheckpoint() error = %v, want not exist %v", err, tt.notExist)
+			}
+		})
+	}
+}
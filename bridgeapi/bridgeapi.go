@@ -0,0 +1,105 @@
+// Package bridgeapi queries the withdrawals of an account from the bridge backend API of an OP Stack
+// chain, as served by the OP indexer behind the bridge UIs.
+package bridgeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maxPages bounds the number of pages of an account's withdrawals searched by FindWithdrawal.
+const maxPages = 50
+
+// ErrNotFound is returned if the API doesn't know of a withdrawal.
+var ErrNotFound = errors.New("withdrawal not found by the bridge API")
+
+// Withdrawal is a withdrawal as reported by the bridge API. The L1 transaction hashes are empty until the
+// withdrawal is proven or finalized.
+type Withdrawal struct {
+	TransactionHash   common.Hash `json:"transactionHash"`
+	From              string      `json:"from"`
+	To                string      `json:"to"`
+	Amount            string      `json:"amount"`
+	L1ProvenTxHash    string      `json:"l1ProvenTxHash"`
+	L1FinalizedTxHash string      `json:"l1FinalizedTxHash"`
+}
+
+// Status returns the status of the withdrawal according to the API: initiated, proven or finalized.
+func (w *Withdrawal) Status() string {
+	switch {
+	case isSet(w.L1FinalizedTxHash):
+		return "finalized"
+	case isSet(w.L1ProvenTxHash):
+		return "proven"
+	default:
+		return "initiated"
+	}
+}
+
+func isSet(hash string) bool {
+	return hash != "" && common.HexToHash(hash) != (common.Hash{})
+}
+
+// Client is a client of the bridge API at a base URL, e.g. https://indexer.example.com.
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+func NewClient(url string) *Client {
+	return &Client{url: strings.TrimSuffix(url, "/"), client: http.DefaultClient}
+}
+
+// FindWithdrawal searches the withdrawals initiated by from for the one initiated by the L2 transaction
+// txHash.
+func (c *Client) FindWithdrawal(ctx context.Context, from common.Address, txHash common.Hash) (*Withdrawal, error) {
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		var resp struct {
+			Cursor      string       `json:"cursor"`
+			HasNextPage bool         `json:"hasNextPage"`
+			Items       []Withdrawal `json:"items"`
+		}
+		u := fmt.Sprintf("%s/api/v0/withdrawals/%s?limit=100", c.url, from.Hex())
+		if cursor != "" {
+			u += "&cursor=" + url.QueryEscape(cursor)
+		}
+		if err := c.get(ctx, u, &resp); err != nil {
+			return nil, fmt.Errorf("error querying bridge API: %w", err)
+		}
+		for i := range resp.Items {
+			if resp.Items[i].TransactionHash == txHash {
+				return &resp.Items[i], nil
+			}
+		}
+		if !resp.HasNextPage || resp.Cursor == "" {
+			break
+		}
+		cursor = resp.Cursor
+	}
+	return nil, ErrNotFound
+}
+
+func (c *Client) get(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
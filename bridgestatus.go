@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/bridgeapi"
+	"github.com/base-org/withdrawer/signer"
+)
+
+// runBridgeStatus implements the bridge-status subcommand, which shows the status of a withdrawal
+// according to the bridge backend API next to its status on chain, and exits with an error if they
+// differ, e.g. to settle reports of the bridge UI and the withdrawer disagreeing.
+func runBridgeStatus(args []string) {
+	fs := flag.NewFlagSet("bridge-status", flag.ExitOnError)
+	f := registerFlags(fs)
+	var bridgeAPI string
+	fs.StringVar(&bridgeAPI, "bridge-api", "", "Base URL of the bridge backend API (OP indexer) of the network to compare with")
+	_ = fs.Parse(args)
+
+	if bridgeAPI == "" {
+		log.Crit("Missing --bridge-api flag")
+	}
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	// the signer is optional, and only used as one more account whose fault proof is looked for
+	var s signer.Signer
+	if f.privateKey != "" || f.ledger || f.mnemonic != "" {
+		s = f.createSigner()
+	}
+	ctx := interruptContext()
+
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	l2RPC, err := n.dialL2(ctx)
+	if err != nil {
+		log.Crit("Error dialing L2 client", "error", err)
+	}
+	from, err := txSender(ctx, ethclient.NewClient(l2RPC), withdrawal)
+	if err != nil {
+		log.Crit("Error querying withdrawal tx", "error", err)
+	}
+
+	api, err := bridgeapi.NewClient(bridgeAPI).FindWithdrawal(ctx, from, withdrawal)
+	if err != nil && !errors.Is(err, bridgeapi.ErrNotFound) {
+		log.Crit("Error querying bridge API", "error", err)
+	}
+
+	// fault proofs are recorded per submitter, so they are looked for from the accounts that could have
+	// proven the withdrawal: the one the API reports, and the signer
+	var provers []common.Address
+	if !n.FaultProofs {
+		provers = append(provers, common.Address{})
+	}
+	if api != nil && api.L1ProvenTxHash != "" {
+		prover, err := txSender(ctx, l1, common.HexToHash(api.L1ProvenTxHash))
+		if err != nil {
+			log.Warn("Error querying prove tx reported by the bridge API", "tx", api.L1ProvenTxHash, "error", err)
+		} else {
+			provers = append(provers, prover)
+		}
+	}
+	if s != nil {
+		provers = append(provers, s.Address())
+	}
+	onChain, detail := onChainStatus(ctx, f, n, withdrawal, provers)
+
+	fmt.Printf("Withdrawal %s\n", withdrawal)
+	fmt.Printf("  On chain:   %s%s\n", onChain, detail)
+	apiStatus := "unknown"
+	if api != nil {
+		apiStatus = api.Status()
+		fmt.Printf("  Bridge API: %s", apiStatus)
+		if api.L1ProvenTxHash != "" {
+			fmt.Printf(", prove tx %s", api.L1ProvenTxHash)
+		}
+		if api.L1FinalizedTxHash != "" {
+			fmt.Printf(", finalize tx %s", api.L1FinalizedTxHash)
+		}
+		fmt.Println()
+	} else {
+		fmt.Printf("  Bridge API: unknown, no withdrawal of %s has this tx hash\n", from)
+	}
+
+	if apiStatus == onChain {
+		fmt.Println("The bridge API matches the on-chain status")
+		return
+	}
+	fmt.Printf("Mismatch: the bridge API reports the withdrawal as %s, but it is %s on chain", apiStatus, onChain)
+	if statusRank[apiStatus] < statusRank[onChain] {
+		fmt.Println(", the API may be lagging behind the chain")
+	} else {
+		fmt.Println(", the API may report a reorged transaction, or a fault proof by an account the withdrawer didn't check (pass it as the signer)")
+	}
+	os.Exit(1)
+}
+
+// statusRank orders the statuses of a withdrawal by progress.
+var statusRank = map[string]int{"unknown": 0, "initiated": 1, "proven": 2, "finalized": 3}
+
+// onChainStatus returns the status of the withdrawal on chain, initiated, proven or finalized, with
+// details such as who proved it or whether it can be proven.
+func onChainStatus(ctx context.Context, f *flags, n network, withdrawal common.Hash, provers []common.Address) (string, string) {
+	hc := f.helperConfig(nil)
+	if len(provers) > 0 {
+		hc.from = provers[0]
+	}
+	// the helpers of all provers share the clients
+	clients, err := dialHelperClients(ctx, f.rpc, n, nil, hc)
+	if err != nil {
+		log.Crit("Error dialing clients", "error", err)
+	}
+	defer clients.Close()
+	helper, err := newWithdrawHelper(ctx, clients, withdrawal, n, nil, hc)
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+	finalized, err := helper.IsProofFinalized(ctx)
+	if err != nil {
+		exitWithError("Error querying withdrawal finalization status", err)
+	}
+	if finalized {
+		return "finalized", ""
+	}
+
+	for _, prover := range provers {
+		hc.from = prover
+		helper, err := newWithdrawHelper(ctx, clients, withdrawal, n, nil, hc)
+		if err != nil {
+			log.Crit("Error creating withdrawer", "error", err)
+		}
+		proofTime, err := helper.GetProvenWithdrawalTime(ctx)
+		if err != nil {
+			exitWithError("Error querying withdrawal proof", err)
+		}
+		if proofTime == 0 {
+			continue
+		}
+		if n.FaultProofs {
+			return "proven", fmt.Sprintf(" by %s", prover)
+		}
+		return "proven", ""
+	}
+
+	if err := helper.CheckIfProvable(ctx); err != nil {
+		return "initiated", fmt.Sprintf(", not provable yet (%v)", err)
+	}
+	if n.FaultProofs {
+		return "initiated", ", provable (not proven by the accounts checked)"
+	}
+	return "initiated", ", provable"
+}
+
+// txSender returns the sender of the transaction with the given hash.
+func txSender(ctx context.Context, client *ethclient.Client, hash common.Hash) (common.Address, error) {
+	tx, _, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// printCastCommands returns the buildOnly function of --print-cast, which prints the Foundry cast
+// commands decoding the calldata of the built transaction, simulating it from from and sending it, so
+// the action can be verified or performed with other tooling. cast reads the RPC url from ETH_RPC_URL,
+// which keeps the user's url, which may hold an API key, out of the output.
+func printCastCommands(from common.Address) func(l2TxHash common.Hash, action string, tx *types.Transaction) {
+	return func(l2TxHash common.Hash, action string, tx *types.Transaction) {
+		data := hexutil.Encode(tx.Data())
+		args := tx.To().Hex() + " " + data
+		if tx.Value().Sign() > 0 {
+			args = "--value " + tx.Value().String() + " " + args
+		}
+
+		fmt.Printf("# %s withdrawal %s (set ETH_RPC_URL to your L1 RPC url)\n", action, l2TxHash.String())
+		if sig := withdraw.MethodSignature(tx.Data()); sig != "" {
+			fmt.Printf("cast calldata-decode '%s' %s\n", sig, data)
+		}
+		fmt.Printf("cast call --from %s %s\n", from.Hex(), args)
+		fmt.Println("# add --private-key, --ledger or --account to sign")
+		fmt.Printf("cast send %s\n", args)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/token"
+)
+
+// runCheckToken implements the check-token subcommand, which checks that an L2 token is mapped to an L1
+// token through the standard bridge before withdrawing it, as withdrawals of tokens with a non-standard
+// mapping may burn them on L2 without being redeemable on L1.
+func runCheckToken(args []string) {
+	fs := flag.NewFlagSet("check-token", flag.ExitOnError)
+	f := registerFlags(fs)
+	var l2Token, l1Token, listURL string
+	fs.StringVar(&l2Token, "token", "", "Address of the L2 token to withdraw")
+	fs.StringVar(&l1Token, "l1-token", "", "Address of the L1 token the withdrawal is meant to be redeemed in (optional, required for tokens native to L2)")
+	fs.StringVar(&listURL, "token-list", token.DefaultTokenListURL, "URL of the token list to compare the mapping with (empty to skip)")
+	_ = fs.Parse(args)
+
+	if !common.IsHexAddress(l2Token) {
+		log.Crit("Invalid --token", "value", l2Token)
+	}
+	var l1Address common.Address
+	if l1Token != "" {
+		if !common.IsHexAddress(l1Token) {
+			log.Crit("Invalid --l1-token", "value", l1Token)
+		}
+		l1Address = common.HexToAddress(l1Token)
+	}
+	n := f.resolveNetwork()
+	ctx := interruptContext()
+
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	l2RPC, err := n.dialL2(ctx)
+	if err != nil {
+		log.Crit("Error dialing L2 client", "error", err)
+	}
+	l2 := ethclient.NewClient(l2RPC)
+
+	report, err := token.Check(ctx, l1, l2, common.HexToAddress(l2Token), l1Address)
+	if err != nil {
+		log.Crit("Error checking token", "error", err)
+	}
+	if listURL != "" && report.L1Token != (common.Address{}) {
+		list, err := token.FetchList(ctx, http.DefaultClient, listURL)
+		if err != nil {
+			log.Crit("Error loading token list", "error", err)
+		}
+		l1ChainID, err := l1.ChainID(ctx)
+		if err != nil {
+			log.Crit("Error querying L1 chain ID", "error", err)
+		}
+		l2ChainID, err := l2.ChainID(ctx)
+		if err != nil {
+			log.Crit("Error querying L2 chain ID", "error", err)
+		}
+		report.CheckList(list, l1ChainID.Uint64(), l2ChainID.Uint64())
+	}
+
+	kind := "L2-native token"
+	switch {
+	case report.Mintable:
+		kind = "OptimismMintableERC20"
+	case report.Legacy:
+		kind = "legacy OptimismMintableERC20"
+	}
+	fmt.Printf("Token:     %s %s (%s)\n", report.Token, report.Symbol, kind)
+	if report.Bridge != (common.Address{}) {
+		fmt.Printf("Bridge:    %s\n", report.Bridge)
+	}
+	if report.L1Token != (common.Address{}) {
+		fmt.Printf("L1 token:  %s\n", report.L1Token)
+	}
+	for _, w := range report.Warnings {
+		fmt.Printf("Warning:   %s\n", w)
+	}
+	for _, p := range report.Problems {
+		fmt.Printf("Problem:   %s\n", p)
+	}
+	if !report.OK() {
+		fmt.Println("Do not withdraw this token through the standard bridge, it may not be redeemable on L1.")
+		os.Exit(1)
+	}
+	fmt.Println("The token mapping looks standard.")
+}
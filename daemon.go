@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+
+	"github.com/base-org/withdrawer/api"
+	"github.com/base-org/withdrawer/api/withdrawerv1"
+	"github.com/base-org/withdrawer/daemon"
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/store"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// discoveryFlags are the flags selecting how the new withdrawals of --senders are discovered, which are
+// set per network.
+type discoveryFlags struct {
+	senders                  string
+	from, blockRange         uint64
+	indirect                 bool
+	explorerAPI, explorerKey string
+	explorerRate             float64
+	graphqlURL, graphqlQuery string
+}
+
+func registerDiscoveryFlags(fs *flag.FlagSet) *discoveryFlags {
+	df := &discoveryFlags{}
+	fs.StringVar(&df.senders, "senders", "", "Comma-separated L2 addresses whose new withdrawals are discovered and tracked automatically")
+	fs.Uint64Var(&df.from, "discover-from-block", 0, "L2 block to start discovering withdrawals of --senders from (default: the latest block)")
+	fs.Uint64Var(&df.blockRange, "discover-block-range", 5000, "Maximum number of L2 blocks to query per eth_getLogs request when discovering withdrawals")
+	fs.BoolVar(&df.indirect, "discover-indirect", false, "Also discover withdrawals of --senders routed through other contracts, such as aggregators or custom bridges, by decoding every withdrawal of the chain and checking its bridge transfer, messenger message and L2 transaction sender")
+	fs.StringVar(&df.explorerAPI, "explorer-api-url", "", "Etherscan-compatible API url of an L2 block explorer (e.g. https://api.basescan.org/api or https://base.blockscout.com/api) to discover withdrawals of --senders through, instead of eth_getLogs on the L2 RPC")
+	fs.StringVar(&df.explorerKey, "explorer-api-key", "", "API key of the --explorer-api-url")
+	fs.Float64Var(&df.explorerRate, "explorer-api-rate-limit", 4, "Maximum number of requests per second to send to the --explorer-api-url")
+	fs.StringVar(&df.graphqlURL, "graphql-url", "", "GraphQL endpoint, such as a bridge subgraph or indexer, to discover withdrawals of --senders through instead of scanning logs")
+	fs.StringVar(&df.graphqlQuery, "graphql-query", "", "File holding the GraphQL query sent to --graphql-url (default: a query for subgraphs of the L2StandardBridge WithdrawalInitiated events)")
+	return df
+}
+
+// daemonNetwork is a network tracked by the daemon, with its own RPCs, signer and discovery. The network
+// of the top-level flags has an empty name, and the ones of --networks the name they are given there.
+type daemonNetwork struct {
+	name string
+	f    *flags
+	df   *discoveryFlags
+	n    network
+	s    signer.Signer
+	d    *daemon.Daemon
+	// clients are shared by the helpers of all withdrawals of the network.
+	clients *helperClients
+}
+
+// loadDaemonNetworks reads the --networks file, a JSON object mapping the name of each additional network
+// to the flags configuring it, as they would be passed on the command line.
+func loadDaemonNetworks(path string) []*daemonNetwork {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Crit("Error reading --networks", "error", err)
+	}
+	var args map[string][]string
+	if err := json.Unmarshal(data, &args); err != nil {
+		log.Crit("Error decoding --networks", "error", err)
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		if name == "" {
+			log.Crit("Invalid --networks, network names can't be empty")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	networks := make([]*daemonNetwork, len(names))
+	for i, name := range names {
+		fs := flag.NewFlagSet("daemon --networks "+name, flag.ExitOnError)
+		dn := &daemonNetwork{name: name, f: registerFlags(fs), df: registerDiscoveryFlags(fs)}
+		_ = fs.Parse(args[name])
+		networks[i] = dn
+	}
+	return networks
+}
+
+// runDaemon implements the daemon subcommand, which tracks a set of withdrawals and automatically
+// proves and finalizes them, persisting progress across restarts. With --networks, a single process
+// tracks withdrawals on several networks, sharing the store, metrics and notifications.
+func runDaemon(args []string) {
+	fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+	f := registerFlags(fs)
+	df := registerDiscoveryFlags(fs)
+	sf := registerStoreFlags(fs)
+	var cfg daemon.Config
+	var withdrawalsFlag string
+	var metricsAddr string
+	var grpcAddr string
+	var healthAddr string
+	var watchEvents bool
+	var networksFile string
+	var eventPollInterval time.Duration
+	var slackWebhook string
+	var discordWebhook string
+	var telegramToken, telegramChat string
+	var eventLog string
+	var pagerDutyKey, opsgenieKey string
+	var gasWindow daemon.GasWindow
+	var finalizeHours string
+	fs.DurationVar(&cfg.Interval, "interval", time.Minute, "Interval between scans of the tracked withdrawals")
+	fs.IntVar(&cfg.MaxAttempts, "max-attempts", 5, "Consecutive failed prove or finalize attempts after which a withdrawal is moved to the dead-letter state")
+	fs.DurationVar(&cfg.RetryBackoff, "retry-backoff", time.Minute, "Delay before retrying a failed attempt, doubling with every consecutive failure")
+	fs.DurationVar(&cfg.MaxRetryBackoff, "max-retry-backoff", time.Hour, "Maximum delay between retries of a failed attempt")
+	fs.StringVar(&withdrawalsFlag, "withdrawals", "", "Comma-separated TX hashes of L2 withdrawal transactions to track (in addition to --withdrawal); passing a dead-lettered withdrawal requeues it")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on (e.g. :7300), disabled if empty")
+	fs.IntVar(&cfg.NotifyAfterAttempts, "notify-after-attempts", 2, "Consecutive failed prove or finalize attempts after which every further failure is notified (0 to only notify status changes)")
+	fs.StringVar(&slackWebhook, "slack-webhook", "", "Slack incoming webhook URL to notify of status changes and repeated failures")
+	fs.StringVar(&discordWebhook, "discord-webhook", "", "Discord webhook URL to notify of status changes and repeated failures")
+	fs.StringVar(&telegramToken, "telegram-bot-token", "", "Telegram bot token to notify of status changes and repeated failures (requires --telegram-chat-id)")
+	fs.StringVar(&telegramChat, "telegram-chat-id", "", "Telegram chat ID the bot posts notifications to")
+	fs.StringVar(&eventLog, "event-log", "", "Directory to append the status changes and repeated failures of each withdrawal to, as JSON lines in <tx hash>.jsonl, for data pipelines")
+	fs.StringVar(&pagerDutyKey, "pagerduty-routing-key", "", "PagerDuty Events API v2 routing key to page for stuck withdrawals")
+	fs.StringVar(&opsgenieKey, "opsgenie-api-key", "", "Opsgenie API integration key to page for stuck withdrawals")
+	fs.DurationVar(&cfg.StuckAfter, "stuck-after", 6*time.Hour, "Page when a withdrawal has been ready to prove or finalize for longer than this (0 to disable)")
+	fs.IntVar(&cfg.PageAfterFailures, "page-after-failures", 3, "Page when finalizing a withdrawal failed this many times in a row (0 to disable)")
+	fs.StringVar(&healthAddr, "health-addr", "", "Address to serve the /healthz and /readyz probes on (e.g. :8080, may equal --metrics-addr), disabled if empty")
+	fs.BoolVar(&watchEvents, "watch-events", true, "Scan immediately when new dispute games are created (or outputs proposed, without fault proofs), instead of only every --interval")
+	fs.DurationVar(&eventPollInterval, "event-poll-interval", 12*time.Second, "Interval to poll for new events at if the L1 RPC doesn't support subscriptions")
+	fs.Float64Var(&gasWindow.Percentile, "gas-percentile", 0, "Only finalize while the L1 base fee is at most this percentile (0-100) of the base fees of the last --gas-window-blocks blocks (0 to disable)")
+	fs.Uint64Var(&gasWindow.Blocks, "gas-window-blocks", 7200, "Number of recent L1 blocks --gas-percentile is computed over")
+	fs.StringVar(&finalizeHours, "finalize-hours", "", "UTC hours to finalize within, as start-end (e.g. 22-6), any time if empty")
+	fs.DurationVar(&gasWindow.Deadline, "gas-window-deadline", 24*time.Hour, "Finalize regardless of --gas-percentile and --finalize-hours once a withdrawal has waited this long (0 to wait indefinitely)")
+	fs.IntVar(&cfg.Workers, "workers", 1, "Number of withdrawals to process concurrently, sharing the signer's nonces")
+	fs.StringVar(&grpcAddr, "grpc-addr", "", "Address to serve the gRPC API on (e.g. :9090), disabled if empty")
+	fs.StringVar(&networksFile, "networks", "", "JSON file mapping the names of additional networks to track to the flags configuring them (e.g. {\"op-mainnet\": [\"--network\", \"op-mainnet\", \"--rpc\", \"...\"]}), each with its own RPCs, signer and --senders")
+	_ = fs.Parse(args)
+
+	if cfg.MaxAttempts < 1 {
+		log.Crit("--max-attempts must be at least 1")
+	}
+	if cfg.Interval <= 0 {
+		log.Crit("--interval must be positive")
+	}
+	if watchEvents && eventPollInterval <= 0 {
+		log.Crit("--event-poll-interval must be positive")
+	}
+	networks := []*daemonNetwork{{f: f, df: df}}
+	if networksFile != "" {
+		networks = append(networks, loadDaemonNetworks(networksFile)...)
+	}
+	for _, dn := range networks {
+		if dn.f.noWait {
+			log.Crit("--no-wait can't be used with the daemon command, which tracks withdrawals until they are finalized")
+		}
+		if dn.f.nonce != "" {
+			log.Crit("--nonce can't be used with the daemon command, as it sends many transactions")
+		}
+		if cfg.Workers > 1 && dn.f.txMgr {
+			log.Crit("--txmgr can't be used with more than one --workers, as each tx manager tracks the nonce itself")
+		}
+		if dn.f.pendingTxs == pendingReplace {
+			log.Crit("--pending-txs replace can't be used with the daemon command, as it sends many transactions")
+		}
+		if dn.df.blockRange == 0 {
+			log.Crit("--discover-block-range must be at least 1")
+		}
+		dn.n = dn.f.resolveNetwork()
+		dn.s = dn.f.createSigner()
+	}
+	ctx := interruptContext()
+
+	st := sf.open()
+	defer st.Close()
+
+	metrics := daemon.NewMetrics()
+
+	if finalizeHours != "" {
+		if _, err := fmt.Sscanf(finalizeHours, "%d-%d", &gasWindow.StartHour, &gasWindow.EndHour); err != nil ||
+			gasWindow.StartHour < 0 || gasWindow.StartHour > 23 || gasWindow.EndHour < 0 || gasWindow.EndHour > 24 {
+			log.Crit("Invalid --finalize-hours", "value", finalizeHours)
+		}
+	}
+	if gasWindow.Percentile < 0 || gasWindow.Percentile > 100 {
+		log.Crit("--gas-percentile must be between 0 and 100")
+	}
+	var eventLogger *notify.EventLog
+	if eventLog != "" {
+		l, err := notify.NewEventLog(eventLog)
+		if err != nil {
+			log.Crit("Error creating event log directory", "error", err)
+		}
+		eventLogger = l
+	}
+	if (telegramToken != "" || telegramChat != "") && (telegramToken == "" || telegramChat == "") {
+		log.Crit("--telegram-bot-token and --telegram-chat-id must be set together")
+	}
+
+	var pagers notify.MultiPager
+	if pagerDutyKey != "" {
+		pagers = append(pagers, notify.NewPagerDuty(pagerDutyKey))
+	}
+	if opsgenieKey != "" {
+		pagers = append(pagers, notify.NewOpsgenie(opsgenieKey))
+	}
+	var pager notify.Pager
+	if len(pagers) > 0 {
+		pager = pagers
+	}
+
+	for _, dn := range networks {
+		dn := dn
+		ncfg := cfg
+		ncfg.Network = dn.name
+		// the store doubles as the journal, as it records the prove and finalize txs of each withdrawal
+		hc := dn.f.helperConfig(store.Journal{Store: st})
+		clients, err := dialHelperClients(ctx, dn.f.rpc, dn.n, dn.s, hc)
+		if err != nil {
+			log.Crit("Error dialing clients", "network", dn.name, "error", err)
+		}
+		defer clients.Close()
+		dn.clients = clients
+		if gasWindow.Percentile > 0 || gasWindow.StartHour != gasWindow.EndHour {
+			window := gasWindow
+			window.Client = clients.l1
+			ncfg.GasWindow = &window
+		}
+
+		// notifications link to the block explorers of the network of each withdrawal
+		explorers := dn.n.explorers()
+		var notifiers notify.Multi
+		if slackWebhook != "" {
+			notifiers = append(notifiers, notify.NewSlack(slackWebhook, explorers))
+		}
+		if discordWebhook != "" {
+			notifiers = append(notifiers, notify.NewDiscord(discordWebhook, explorers))
+		}
+		if telegramToken != "" {
+			notifiers = append(notifiers, notify.NewTelegram(telegramToken, telegramChat, explorers))
+		}
+		if eventLogger != nil {
+			notifiers = append(notifiers, eventLogger)
+		}
+
+		// the pending transactions of the signer are checked once, after which the nonce manager assigns
+		// the nonces of the transactions of all withdrawals (the tx manager assigns them itself)
+		var nonces *withdraw.NonceManager
+		if !hc.txMgr {
+			if _, _, err := checkPendingTxs(ctx, clients.l1, dn.s.Address(), hc.pendingTxs, hc.pollInterval); err != nil {
+				log.Crit("Error checking pending transactions", "network", dn.name, "error", err)
+			}
+			nonces = withdraw.NewNonceManager(clients.l1, dn.s.Address())
+		}
+
+		logger := log.Root()
+		if dn.name != "" {
+			logger = logger.With("network", dn.name)
+		}
+		hc.nonces = nonces
+		hc.logger = withdraw.NewLogLogger(logger)
+		dn.d = daemon.New(st, func(l2TxHash common.Hash) (withdraw.WithdrawHelper, error) {
+			return newWithdrawHelper(ctx, clients, l2TxHash, dn.n, dn.s, hc)
+		}, ncfg, metrics, notifiers, pager)
+	}
+	d := networks[0].d
+
+	// the metrics and health endpoints may share a single HTTP server
+	muxes := make(map[string]*http.ServeMux)
+	muxFor := func(addr string) *http.ServeMux {
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+	if metricsAddr != "" {
+		muxFor(metricsAddr).Handle("/metrics", metrics.Handler())
+	}
+	if healthAddr != "" {
+		checks := make(map[string]daemon.Check)
+		daemons := make([]*daemon.Daemon, len(networks))
+		for i, dn := range networks {
+			daemons[i] = dn.d
+			prefix := ""
+			if dn.name != "" {
+				prefix = dn.name + "/"
+			}
+			checks[prefix+"l1"] = rpcCheck(dn.clients.l1)
+			checks[prefix+"l2"] = rpcCheck(ethclient.NewClient(dn.clients.l2))
+		}
+		mux := muxFor(healthAddr)
+		mux.Handle("/healthz", daemon.HealthzHandler(daemons...))
+		mux.Handle("/readyz", d.ReadyzHandler(checks))
+	}
+	for addr, mux := range muxes {
+		addr, mux := addr, mux
+		go func() {
+			log.Info("Serving HTTP endpoints", "addr", addr)
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				log.Crit("HTTP server stopped", "error", err)
+			}
+		}()
+	}
+
+	for _, dn := range networks {
+		var hashes []string
+		if dn.f.withdrawal != "" {
+			hashes = append(hashes, dn.f.withdrawal)
+		}
+		if dn.name == "" && withdrawalsFlag != "" {
+			hashes = append(hashes, strings.Split(withdrawalsFlag, ",")...)
+		}
+		for _, h := range hashes {
+			if err := dn.d.Track(common.HexToHash(strings.TrimSpace(h))); err != nil {
+				log.Crit("Error tracking withdrawal", "network", dn.name, "tx", h, "error", err)
+			}
+		}
+	}
+
+	// the gRPC API tracks submitted withdrawals on the network of the top-level flags
+	if grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Crit("Error listening for gRPC", "addr", grpcAddr, "error", err)
+		}
+		server := grpc.NewServer()
+		withdrawerv1.RegisterWithdrawerServiceServer(server, api.NewServer(st, d))
+		go func() {
+			log.Info("Serving gRPC API", "addr", grpcAddr)
+			if err := server.Serve(lis); err != nil {
+				log.Crit("gRPC server stopped", "error", err)
+			}
+		}()
+	}
+
+	for _, dn := range networks {
+		if dn.df.senders != "" {
+			dn.discover(ctx, cfg.Interval)
+		}
+		if watchEvents {
+			dn.watchEvents(ctx, eventPollInterval)
+		}
+	}
+
+	log.Info("Starting daemon", "store", sf.kind, "state", sf.path, "interval", cfg.Interval, "networks", len(networks))
+	g, gctx := errgroup.WithContext(ctx)
+	for _, dn := range networks {
+		dn := dn
+		g.Go(func() error {
+			if err := dn.d.Run(gctx); err != nil && !errors.Is(err, context.Canceled) {
+				return fmt.Errorf("network %q: %w", dn.name, err)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		log.Crit("Daemon stopped", "error", err)
+	}
+	log.Info("Daemon stopped")
+}
+
+// discover discovers and tracks the new withdrawals of the --senders of the network in the background.
+func (dn *daemonNetwork) discover(ctx context.Context, interval time.Duration) {
+	df := dn.df
+	l2 := ethclient.NewClient(dn.clients.l2)
+	var err error
+	from := df.from
+	if from == 0 {
+		if from, err = l2.BlockNumber(ctx); err != nil {
+			log.Crit("Error querying L2 head", "network", dn.name, "error", err)
+		}
+	}
+	var x interface {
+		Run(ctx context.Context, interval time.Duration) error
+	}
+	switch {
+	case df.graphqlURL != "" && df.explorerAPI != "":
+		log.Crit("--graphql-url and --explorer-api-url can't be combined")
+	case df.graphqlURL != "" && df.indirect:
+		log.Crit("--discover-indirect isn't supported with --graphql-url")
+	case df.graphqlURL != "":
+		query := daemon.DefaultGraphQLQuery
+		if df.graphqlQuery != "" {
+			data, err := os.ReadFile(df.graphqlQuery)
+			if err != nil {
+				log.Crit("Error reading GraphQL query", "error", err)
+			}
+			query = string(data)
+		}
+		x = daemon.NewGraphQLDiscoverer(dn.d, df.graphqlURL, query, parseAddresses(df.senders), from)
+	default:
+		var logs daemon.LogFilterer
+		if df.explorerAPI != "" {
+			logs = daemon.NewExplorerLogs(df.explorerAPI, df.explorerKey, df.explorerRate)
+		}
+		if x, err = daemon.NewDiscoverer(dn.d, l2, logs, parseAddresses(df.senders), from, df.blockRange, df.indirect); err != nil {
+			log.Crit("Error creating discoverer", "network", dn.name, "error", err)
+		}
+	}
+	go func() {
+		if err := x.Run(ctx, interval); err != nil {
+			log.Error("Stopped discovering withdrawals", "network", dn.name, "error", err)
+		}
+	}()
+}
+
+// watchEvents scans the withdrawals of the network as soon as new dispute games are created, or outputs
+// proposed, in the background.
+func (dn *daemonNetwork) watchEvents(ctx context.Context, pollInterval time.Duration) {
+	l1 := dn.clients.l1
+	go func() {
+		var err error
+		if dn.n.FaultProofs {
+			err = daemon.WatchDisputeGames(ctx, dn.d, l1, dn.n.DisputeGameFactory, pollInterval)
+		} else {
+			err = daemon.WatchOutputProposals(ctx, dn.d, l1, dn.n.L2OutputOracle, pollInterval)
+		}
+		if err != nil {
+			log.Error("Stopped watching events", "network", dn.name, "error", err)
+		}
+	}()
+}
+
+// rpcCheck reports an RPC endpoint as healthy if it returns the latest block number.
+func rpcCheck(client *ethclient.Client) daemon.Check {
+	return func(ctx context.Context) error {
+		_, err := client.BlockNumber(ctx)
+		return err
+	}
+}
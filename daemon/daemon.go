@@ -0,0 +1,559 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/store"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// notifyTimeout bounds the time spent delivering a single notification.
+const notifyTimeout = 10 * time.Second
+
+// defaultMaxAttempts is the number of consecutive failed attempts after which a withdrawal is
+// dead-lettered if Config.MaxAttempts is unset.
+const defaultMaxAttempts = 5
+
+// HelperFactory creates a WithdrawHelper for the withdrawal initiated in the given L2 transaction.
+type HelperFactory func(l2TxHash common.Hash) (withdraw.WithdrawHelper, error)
+
+// Config configures the scan and retry behavior of the daemon.
+type Config struct {
+	// Interval is the time between scans of the tracked withdrawals.
+	Interval time.Duration
+	// MaxAttempts is the number of consecutive failed prove or finalize attempts after which a
+	// withdrawal is moved to the dead-letter state, 5 if unset.
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failed attempt, doubling with every further failure.
+	RetryBackoff time.Duration
+	// MaxRetryBackoff caps the delay between attempts.
+	MaxRetryBackoff time.Duration
+	// NotifyAfterAttempts is the number of consecutive failed attempts after which every further
+	// failure is notified.
+	NotifyAfterAttempts int
+	// StuckAfter is how long a withdrawal may remain eligible for its next action before paging.
+	StuckAfter time.Duration
+	// PageAfterFailures is the number of consecutive failed finalize attempts after which to page.
+	PageAfterFailures int
+	// GasWindow, if set, holds off finalizations until L1 gas is cheap.
+	GasWindow *GasWindow
+	// Workers is the number of withdrawals processed concurrently, 1 if unset. The helpers must then
+	// coordinate their nonces, see withdraw.NonceManager.
+	Workers int
+	// Network is the name of the network whose withdrawals are processed, when daemons of several
+	// networks share the store, metrics and notifiers. It is empty for the default network.
+	Network string
+}
+
+// Daemon drives tracked withdrawals to completion, proving them once provable and finalizing
+// them once mature. All progress is persisted to the store, so the daemon can be restarted at any time.
+type Daemon struct {
+	store     store.Store
+	newHelper HelperFactory
+	cfg       Config
+	metrics   *Metrics
+	notifier  notify.Notifier
+	pager     notify.Pager
+	log       log.Logger
+	isLeader  bool
+	// lastLoop is the unix time of the last iteration of the scan loop, or of the last withdrawal
+	// processed by a scan, read by the liveness probe.
+	lastLoop atomic.Int64
+	// wake triggers a scan ahead of the next interval.
+	wake chan struct{}
+
+	// eligible records since when withdrawals have been eligible for their next action, and paged
+	// the keys of open alerts. Both are kept in memory only, so they start over after a restart, and
+	// guarded by mu, as workers update them concurrently.
+	mu       sync.Mutex
+	eligible map[common.Hash]eligibility
+	paged    map[string]bool
+	// gasClosed is why the gas window was closed at the start of the current scan, if it was.
+	gasClosed string
+}
+
+// eligibility is the action a withdrawal has been ready for since a given time.
+type eligibility struct {
+	action string
+	since  time.Time
+}
+
+// New creates a Daemon that processes the withdrawals tracked in s. The notifier and pager may be nil.
+func New(s store.Store, newHelper HelperFactory, cfg Config, metrics *Metrics, notifier notify.Notifier, pager notify.Pager) *Daemon {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	d := &Daemon{
+		store:     s,
+		newHelper: newHelper,
+		cfg:       cfg,
+		metrics:   metrics,
+		notifier:  notifier,
+		pager:     pager,
+		log:       log.Root(),
+		eligible:  make(map[common.Hash]eligibility),
+		paged:     make(map[string]bool),
+		wake:      make(chan struct{}, 1),
+	}
+	if cfg.Network != "" {
+		d.log = log.Root().With("network", cfg.Network)
+	}
+	d.lastLoop.Store(time.Now().Unix())
+	return d
+}
+
+// actionError is returned by process when submitting a prove or finalize transaction failed, as
+// opposed to the withdrawal not being ready for the next action yet. Only action errors count
+// towards the retry limit.
+type actionError struct {
+	action string
+	err    error
+}
+
+func (e *actionError) Error() string {
+	return e.action + " failed: " + e.err.Error()
+}
+
+func (e *actionError) Unwrap() error {
+	return e.err
+}
+
+// Track adds a withdrawal to the store, if it isn't tracked already. Tracking a dead-lettered
+// withdrawal again requeues it.
+func (d *Daemon) Track(txHash common.Hash) error {
+	w, err := d.store.Get(txHash)
+	if err == nil {
+		if w.Network != d.cfg.Network {
+			return fmt.Errorf("withdrawal %s is tracked on network %q", txHash, w.Network)
+		}
+		if w.Status != store.StatusDeadLetter {
+			return nil
+		}
+		d.log.Info("Requeuing dead-lettered withdrawal", "tx", txHash)
+		w.Status = store.StatusPending
+		w.Attempts = 0
+		w.NextAttemptAt = time.Time{}
+		w.UpdatedAt = time.Now()
+		return d.store.Put(w)
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	d.log.Info("Tracking withdrawal", "tx", txHash)
+	return d.store.Put(&store.Withdrawal{
+		TxHash:    txHash,
+		Network:   d.cfg.Network,
+		Status:    store.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+// Run scans the tracked withdrawals every interval, or when woken, until ctx is canceled. If the
+// store supports leader election, only the replica holding leadership scans, the others stand by.
+func (d *Daemon) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		d.lastLoop.Store(time.Now().Unix())
+		if d.acquireLeadership(ctx) {
+			if err := d.Scan(ctx); err != nil {
+				d.log.Error("Error scanning withdrawals", "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// acquireLeadership returns whether this replica is (still) the leader.
+func (d *Daemon) acquireLeadership(ctx context.Context) bool {
+	leader, ok := d.store.(store.Leader)
+	if !ok {
+		return true
+	}
+
+	isLeader, err := leader.TryAcquireLeadership(ctx)
+	if err != nil {
+		d.log.Error("Error acquiring leadership", "error", err)
+		isLeader = false
+	}
+	if isLeader != d.isLeader {
+		if isLeader {
+			d.log.Info("Acquired leadership, processing withdrawals")
+		} else {
+			d.log.Info("Another replica is the leader, standing by")
+		}
+		d.isLeader = isLeader
+	}
+	d.metrics.leader.Set(boolToFloat(isLeader))
+	return isLeader
+}
+
+// Scan advances every unfinalized withdrawal of the daemon's network by at most one step.
+func (d *Daemon) Scan(ctx context.Context) error {
+	all, err := d.store.List()
+	if err != nil {
+		return err
+	}
+	var withdrawals []*store.Withdrawal
+	for _, w := range all {
+		if w.Network == d.cfg.Network {
+			withdrawals = append(withdrawals, w)
+		}
+	}
+	d.gasClosed = ""
+	if d.cfg.GasWindow != nil {
+		// finalizations are only held off while the window is known to be closed
+		if d.gasClosed, err = d.cfg.GasWindow.closedReason(time.Now()); err != nil {
+			d.log.Warn("Error checking gas window", "error", err)
+		}
+	}
+	var due []*store.Withdrawal
+	for _, w := range withdrawals {
+		if w.Status == store.StatusFinalized || w.Status == store.StatusDeadLetter || time.Now().Before(w.NextAttemptAt) {
+			continue
+		}
+		due = append(due, w)
+	}
+
+	// withdrawals are processed concurrently by the workers, their results recorded one at a time
+	type result struct {
+		w              *store.Withdrawal
+		previousStatus store.Status
+		err            error
+	}
+	jobs := make(chan *store.Withdrawal)
+	results := make(chan result)
+	for i := 0; i < min(max(d.cfg.Workers, 1), len(due)); i++ {
+		go func() {
+			for w := range jobs {
+				previousStatus := w.Status
+				results <- result{w: w, previousStatus: previousStatus, err: d.process(ctx, w)}
+			}
+		}()
+	}
+	go func() {
+		for _, w := range due {
+			jobs <- w
+		}
+		close(jobs)
+	}()
+	var storeErr error
+	for range due {
+		r := <-results
+		// keep draining the results, so that no worker is left blocked
+		if err := d.record(r.w, r.previousStatus, r.err); err != nil && storeErr == nil {
+			storeErr = err
+		}
+		// a scan waiting for the confirmations of many transactions is slow, but not stalled
+		d.lastLoop.Store(time.Now().Unix())
+	}
+	if storeErr != nil {
+		return storeErr
+	}
+
+	d.metrics.recordWithdrawals(d.cfg.Network, withdrawals)
+	return nil
+}
+
+// record updates the state of w after processing it failed with err, or succeeded if err is nil, and
+// persists it.
+func (d *Daemon) record(w *store.Withdrawal, previousStatus store.Status, err error) error {
+	var actionErr *actionError
+	switch {
+	case err == nil:
+		w.LastError = ""
+		w.Attempts = 0
+		w.NextAttemptAt = time.Time{}
+	case errors.As(err, &actionErr):
+		w.LastError = err.Error()
+		w.Attempts++
+		d.metrics.actionFailures.WithLabelValues(d.cfg.Network, actionErr.action).Inc()
+		if w.Attempts >= d.cfg.MaxAttempts {
+			d.log.Error("Withdrawal moved to dead-letter state", "tx", w.TxHash, "attempts", w.Attempts, "error", err)
+			w.Status = store.StatusDeadLetter
+			d.metrics.deadLettered.WithLabelValues(d.cfg.Network).Inc()
+		} else {
+			w.NextAttemptAt = time.Now().Add(d.backoff(w.Attempts))
+			d.log.Warn("Withdrawal action failed, retrying later", "tx", w.TxHash, "attempts", w.Attempts, "next", w.NextAttemptAt, "error", err)
+		}
+		if d.cfg.NotifyAfterAttempts > 0 && w.Attempts >= d.cfg.NotifyAfterAttempts {
+			d.notify(notify.Event{Kind: notify.ActionFailed, Withdrawal: *w, Action: actionErr.action, Err: err.Error()})
+		}
+		if actionErr.action == "finalize" && d.cfg.PageAfterFailures > 0 && w.Attempts >= d.cfg.PageAfterFailures {
+			d.page(notify.Alert{
+				Key:     "finalize-failing/" + w.TxHash.Hex(),
+				Summary: fmt.Sprintf("Finalizing withdrawal %s failed %d times in a row", w.TxHash.Hex(), w.Attempts),
+				Details: alertDetails(w),
+			})
+		}
+	default:
+		// the withdrawal isn't ready for its next action yet, or a read failed; just try again next scan
+		d.log.Info("Withdrawal not advanced", "tx", w.TxHash, "status", w.Status, "reason", err)
+		w.LastError = err.Error()
+	}
+	w.UpdatedAt = time.Now()
+	if err := d.store.Put(w); err != nil {
+		return err
+	}
+	if w.Status != previousStatus {
+		d.notify(notify.Event{Kind: notify.StatusChanged, Withdrawal: *w, PreviousStatus: previousStatus, Err: w.LastError})
+	}
+	d.checkStuck(w)
+	return nil
+}
+
+// notify delivers e to the notifier, if any. Delivery failures are logged but don't affect processing.
+func (d *Daemon) notify(e notify.Event) {
+	if d.notifier == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := d.notifier.Notify(ctx, e); err != nil {
+		d.log.Warn("Error sending notification", "tx", e.Withdrawal.TxHash, "event", e.Kind, "error", err)
+	}
+}
+
+// page triggers an alert, unless it is open already.
+func (d *Daemon) page(a notify.Alert) {
+	d.mu.Lock()
+	open := d.paged[a.Key]
+	d.mu.Unlock()
+	if d.pager == nil || open {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := d.pager.Trigger(ctx, a); err != nil {
+		d.log.Warn("Error triggering alert", "key", a.Key, "error", err)
+		return
+	}
+	d.log.Warn("Triggered alert", "key", a.Key, "summary", a.Summary)
+	d.mu.Lock()
+	d.paged[a.Key] = true
+	d.mu.Unlock()
+}
+
+// resolve resolves an open alert.
+func (d *Daemon) resolve(key string) {
+	d.mu.Lock()
+	open := d.paged[key]
+	d.mu.Unlock()
+	if d.pager == nil || !open {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := d.pager.Resolve(ctx, key); err != nil {
+		d.log.Warn("Error resolving alert", "key", key, "error", err)
+		return
+	}
+	d.log.Info("Resolved alert", "key", key)
+	d.mu.Lock()
+	delete(d.paged, key)
+	d.mu.Unlock()
+}
+
+// setEligible records whether w is ready for action. The time it became ready is kept for as long as
+// it stays ready for the same action.
+func (d *Daemon) setEligible(w *store.Withdrawal, action string, eligible bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !eligible {
+		delete(d.eligible, w.TxHash)
+		return
+	}
+	if e, ok := d.eligible[w.TxHash]; !ok || e.action != action {
+		d.eligible[w.TxHash] = eligibility{action: action, since: time.Now()}
+	}
+}
+
+// checkStuck pages when w has been ready for its next action for longer than the configured threshold,
+// and resolves the alerts of withdrawals that have moved on.
+func (d *Daemon) checkStuck(w *store.Withdrawal) {
+	key := "stuck/" + w.TxHash.Hex()
+	d.mu.Lock()
+	e, ok := d.eligible[w.TxHash]
+	if !ok || w.Status == store.StatusFinalized {
+		delete(d.eligible, w.TxHash)
+	}
+	d.mu.Unlock()
+	if !ok || w.Status == store.StatusFinalized {
+		d.resolve(key)
+	} else if d.cfg.StuckAfter > 0 && time.Since(e.since) > d.cfg.StuckAfter {
+		d.page(notify.Alert{
+			Key:     key,
+			Summary: fmt.Sprintf("Withdrawal %s has been ready to %s since %s", w.TxHash.Hex(), e.action, e.since.Format(time.RFC3339)),
+			Details: alertDetails(w),
+		})
+	}
+	if w.Status == store.StatusFinalized {
+		d.resolve("finalize-failing/" + w.TxHash.Hex())
+	}
+}
+
+// checkProofGame pages if the dispute game w was proven against has been challenged, since the
+// withdrawal must be proven again if the challenger wins.
+func (d *Daemon) checkProofGame(ctx context.Context, w *store.Withdrawal, helper withdraw.WithdrawHelper) {
+	inspector, ok := helper.(withdraw.ProofGameInspector)
+	if !ok || d.pager == nil {
+		return
+	}
+	game, err := inspector.ProofGame(ctx)
+	if err != nil {
+		d.log.Warn("Error inspecting proof dispute game", "tx", w.TxHash, "error", err)
+		return
+	}
+	if game == nil || (!game.Challenged && game.Status != withdraw.GameChallengerWins) {
+		return
+	}
+	details := alertDetails(w)
+	details["game"] = game.Address.Hex()
+	details["game_status"] = game.Status.String()
+	d.page(notify.Alert{
+		Key:     "game-challenged/" + w.TxHash.Hex(),
+		Summary: fmt.Sprintf("Dispute game %s proving withdrawal %s was challenged (%s)", game.Address.Hex(), w.TxHash.Hex(), game.Status),
+		Details: details,
+	})
+}
+
+func alertDetails(w *store.Withdrawal) map[string]string {
+	details := map[string]string{
+		"tx":       w.TxHash.Hex(),
+		"status":   string(w.Status),
+		"attempts": fmt.Sprint(w.Attempts),
+	}
+	if w.ProveTx != (common.Hash{}) {
+		details["prove_tx"] = w.ProveTx.Hex()
+	}
+	if w.LastError != "" {
+		details["last_error"] = w.LastError
+	}
+	if w.Network != "" {
+		details["network"] = w.Network
+	}
+	return details
+}
+
+// backoff returns the delay before the next attempt after the given number of consecutive failures.
+func (d *Daemon) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxRetryBackoff {
+		delay = d.cfg.MaxRetryBackoff
+	}
+	return delay
+}
+
+// process performs the next action for w, updating its state in place.
+func (d *Daemon) process(ctx context.Context, w *store.Withdrawal) error {
+	helper, err := d.newHelper(w.TxHash)
+	if err != nil {
+		return err
+	}
+
+	if w.WithdrawalHash == (common.Hash{}) {
+		w.WithdrawalHash, err = helper.WithdrawalHash(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	isFinalized, err := helper.IsProofFinalized(ctx)
+	if err != nil {
+		return err
+	}
+	if isFinalized {
+		d.log.Info("Withdrawal finalized", "tx", w.TxHash)
+		w.Status = store.StatusFinalized
+		if w.FinalizedAt.IsZero() {
+			w.FinalizedAt = time.Now()
+		}
+		return nil
+	}
+
+	proofTime, err := helper.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if proofTime == 0 {
+		err := helper.CheckIfProvable(ctx)
+		d.setEligible(w, "prove", err == nil)
+		if err != nil {
+			return err
+		}
+		tx, err := helper.ProveWithdrawal(ctx)
+		if tx != (common.Hash{}) {
+			w.ProveTx = tx
+		}
+		if err != nil {
+			// interrupted while waiting for confirmation, the tx is resumed on restart, txs deferred
+			// because of a base fee spike are sent once it subsides, txs the signer can't pay for yet once
+			// it is funded, and none are sent once the spending cap is reached
+			if errors.Is(err, context.Canceled) || errors.Is(err, withdraw.ErrBaseFeeTooHigh) || errors.Is(err, withdraw.ErrSpendCapReached) ||
+				errors.Is(err, withdraw.ErrInsufficientFunds) {
+				return err
+			}
+			return &actionError{action: "prove", err: err}
+		}
+		d.log.Info("Withdrawal proven", "tx", w.TxHash, "proveTx", tx)
+		d.setEligible(w, "prove", false)
+		w.Status = store.StatusProven
+		w.ProvenAt = time.Now()
+		return nil
+	}
+
+	w.Status = store.StatusProven
+	if w.ProvenAt.IsZero() {
+		w.ProvenAt = time.Unix(int64(proofTime), 0)
+	}
+	d.checkProofGame(ctx, w, helper)
+	err = helper.CheckIfFinalizable(ctx)
+	d.setEligible(w, "finalize", err == nil)
+	if err != nil {
+		return err
+	}
+	if d.gasClosed != "" {
+		d.mu.Lock()
+		since := time.Since(d.eligible[w.TxHash].since)
+		d.mu.Unlock()
+		if d.cfg.GasWindow.Deadline == 0 || since < d.cfg.GasWindow.Deadline {
+			return errors.New("waiting for gas window: " + d.gasClosed)
+		}
+		d.log.Info("Gas window deadline passed, finalizing regardless", "tx", w.TxHash, "reason", d.gasClosed)
+	}
+	tx, err := helper.FinalizeWithdrawal(ctx)
+	if tx != (common.Hash{}) {
+		w.FinalizeTx = tx
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, withdraw.ErrBaseFeeTooHigh) || errors.Is(err, withdraw.ErrSpendCapReached) ||
+			errors.Is(err, withdraw.ErrInsufficientFunds) {
+			return err
+		}
+		return &actionError{action: "finalize", err: err}
+	}
+	d.log.Info("Withdrawal finalized", "tx", w.TxHash, "finalizeTx", tx)
+	w.Status = store.StatusFinalized
+	w.FinalizedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,193 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/base-org/withdrawer/store"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// fakeHelper is a withdrawal that is provable, or not with notProvable, and whose prove transactions
+// fail with proveErrs in turn, then succeed.
+type fakeHelper struct {
+	notProvable error
+	proveErrs   []error
+
+	proves int
+	proven bool
+}
+
+func (h *fakeHelper) WithdrawalHash(context.Context) (common.Hash, error) {
+	return common.HexToHash("0xabcd"), nil
+}
+
+func (h *fakeHelper) CheckIfProvable(context.Context) error {
+	return h.notProvable
+}
+
+func (h *fakeHelper) GetProvenWithdrawalTime(context.Context) (uint64, error) {
+	if h.proven {
+		return 1_700_000_000, nil
+	}
+	return 0, nil
+}
+
+func (h *fakeHelper) ProveWithdrawal(context.Context) (common.Hash, error) {
+	h.proves++
+	if len(h.proveErrs) > 0 {
+		err := h.proveErrs[0]
+		h.proveErrs = h.proveErrs[1:]
+		return common.Hash{}, err
+	}
+	h.proven = true
+	return common.HexToHash("0x1234"), nil
+}
+
+func (h *fakeHelper) IsProofFinalized(context.Context) (bool, error) {
+	return false, nil
+}
+
+func (h *fakeHelper) CheckIfFinalizable(context.Context) error {
+	return withdraw.ErrChallengePeriodActive
+}
+
+func (h *fakeHelper) FinalizeWithdrawal(context.Context) (common.Hash, error) {
+	return common.Hash{}, errors.New("not finalizable")
+}
+
+func TestDaemonRetries(t *testing.T) {
+	errReverted := errors.New("execution reverted")
+	tests := []struct {
+		name   string
+		helper *fakeHelper
+		// backoff is the delay before retrying a failed attempt.
+		backoff time.Duration
+		scans   int
+
+		wantStatus   store.Status
+		wantAttempts int
+		wantProves   int
+		// wantRetryAt is whether the next attempt is delayed.
+		wantRetryAt bool
+	}{
+		{
+			name:       "proven",
+			helper:     &fakeHelper{},
+			scans:      1,
+			wantStatus: store.StatusProven,
+			wantProves: 1,
+		},
+		{
+			name:       "not provable yet",
+			helper:     &fakeHelper{notProvable: withdraw.ErrNotProvableYet},
+			scans:      3,
+			wantStatus: store.StatusPending,
+		},
+		{
+			name:         "failed attempt is retried after the backoff",
+			helper:       &fakeHelper{proveErrs: []error{errReverted}},
+			backoff:      time.Hour,
+			scans:        3,
+			wantStatus:   store.StatusPending,
+			wantAttempts: 1,
+			wantProves:   1,
+			wantRetryAt:  true,
+		},
+		{
+			name:       "success resets the attempts",
+			helper:     &fakeHelper{proveErrs: []error{errReverted, errReverted}},
+			scans:      3,
+			wantStatus: store.StatusProven,
+			wantProves: 3,
+		},
+		{
+			name:         "dead-lettered after the maximum attempts",
+			helper:       &fakeHelper{proveErrs: []error{errReverted, errReverted, errReverted, errReverted}},
+			scans:        5,
+			wantStatus:   store.StatusDeadLetter,
+			wantAttempts: 3,
+			wantProves:   3,
+		},
+		{
+			name:       "deferred sends don't count as attempts",
+			helper:     &fakeHelper{proveErrs: []error{withdraw.ErrBaseFeeTooHigh, withdraw.ErrSpendCapReached, withdraw.ErrInsufficientFunds}},
+			scans:      3,
+			wantStatus: store.StatusPending,
+			wantProves: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := store.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer st.Close()
+			newHelper := func(common.Hash) (withdraw.WithdrawHelper, error) { return tt.helper, nil }
+			d := New(st, newHelper, Config{MaxAttempts: 3, RetryBackoff: tt.backoff, MaxRetryBackoff: time.Hour}, NewMetrics(), nil, nil)
+			txHash := common.HexToHash("0x01")
+			if err := d.Track(txHash); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.scans; i++ {
+				if err := d.Scan(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w, err := st.Get(txHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Status != tt.wantStatus || w.Attempts != tt.wantAttempts || tt.helper.proves != tt.wantProves {
+				t.Fatalf("status %s after %d attempts and %d proves, want %s after %d attempts and %d proves",
+					w.Status, w.Attempts, tt.helper.proves, tt.wantStatus, tt.wantAttempts, tt.wantProves)
+			}
+			if retryAt := w.NextAttemptAt.After(time.Now()); retryAt != tt.wantRetryAt {
+				t.Fatalf("next attempt at %s, want delayed %v", w.NextAttemptAt, tt.wantRetryAt)
+			}
+		})
+	}
+}
+
+func TestDaemonRequeuesDeadLetter(t *testing.T) {
+	st, err := store.NewFileStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	helper := &fakeHelper{proveErrs: []error{errors.New("execution reverted")}}
+	newHelper := func(common.Hash) (withdraw.WithdrawHelper, error) { return helper, nil }
+	d := New(st, newHelper, Config{MaxAttempts: 1}, NewMetrics(), nil, nil)
+	txHash := common.HexToHash("0x01")
+	if err := d.Track(txHash); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := st.Get(txHash); w.Status != store.StatusDeadLetter {
+		t.Fatalf("status %s, want %s", w.Status, store.StatusDeadLetter)
+	}
+
+	// tracking the withdrawal again requeues it
+	if err := d.Track(txHash); err != nil {
+		t.Fatal(err)
+	}
+	w, _ := st.Get(txHash)
+	if w.Status != store.StatusPending || w.Attempts != 0 {
+		t.Fatalf("requeued with status %s and %d attempts, want %s and 0", w.Status, w.Attempts, store.StatusPending)
+	}
+	if err := d.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := st.Get(txHash); w.Status != store.StatusProven {
+		t.Fatalf("status %s, want %s", w.Status, store.StatusProven)
+	}
+}
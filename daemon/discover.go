@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// Discoverer finds new withdrawals initiated by a set of L2 accounts and tracks them with a Daemon.
+type Discoverer struct {
+	d  *Daemon
+	l2 *ethclient.Client
+	// logs is where the logs of the withdrawals are queried from, the L2 RPC by default.
+	logs      LogFilterer
+	senders   []common.Address
+	chunkSize uint64
+	// indirect also looks for withdrawals routed through other contracts, such as aggregators or custom
+	// bridges, which have these contracts as their sender.
+	indirect bool
+	next     uint64
+}
+
+// NewDiscoverer creates a Discoverer tracking the withdrawals senders initiate from L2 block fromBlock on.
+// Their logs are queried from logs if it is set, and from l2 otherwise. With indirect, withdrawals are also
+// attributed to senders that routed them through other contracts, at the cost of decoding every withdrawal
+// of the chain. chunkSize, the maximum number of blocks queried at once, must be at least 1.
+func NewDiscoverer(d *Daemon, l2 *ethclient.Client, logs LogFilterer, senders []common.Address, fromBlock, chunkSize uint64, indirect bool) (*Discoverer, error) {
+	if chunkSize == 0 {
+		return nil, errors.New("chunk size must be at least 1")
+	}
+	if logs == nil {
+		logs = l2
+	}
+	return &Discoverer{d: d, l2: l2, logs: logs, senders: senders, chunkSize: chunkSize, indirect: indirect, next: fromBlock}, nil
+}
+
+// Run polls for new withdrawals every interval until ctx is canceled.
+func (x *Discoverer) Run(ctx context.Context, interval time.Duration) error {
+	return pollEvery(ctx, interval, x.Poll)
+}
+
+// pollEvery calls poll every interval until ctx is canceled, logging its errors.
+func pollEvery(ctx context.Context, interval time.Duration, poll func(context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := poll(ctx); err != nil {
+			log.Error("Error discovering withdrawals", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll tracks the withdrawals initiated by the senders in L2 blocks since the last poll, either
+// through the L2StandardBridge or directly on the L2ToL1MessagePasser.
+func (x *Discoverer) Poll(ctx context.Context) error {
+	head, err := x.l2.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying L2 head: %w", err)
+	}
+	passer, err := bindings.L2ToL1MessagePasserMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+
+	senderTopics := make([]common.Hash, len(x.senders))
+	for i, s := range x.senders {
+		senderTopics[i] = common.BytesToHash(s.Bytes())
+	}
+	queries := []ethereum.FilterQuery{
+		{
+			Addresses: []common.Address{predeploys.L2StandardBridgeAddr},
+			Topics:    [][]common.Hash{{withdraw.WithdrawalInitiatedTopic}, nil, nil, senderTopics},
+		},
+		{
+			Addresses: []common.Address{predeploys.L2ToL1MessagePasserAddr},
+			Topics:    [][]common.Hash{{passer.Events["MessagePassed"].ID}, nil, senderTopics},
+		},
+	}
+	if x.indirect {
+		// every withdrawal, to be attributed by attribute
+		queries = append(queries, ethereum.FilterQuery{
+			Addresses: []common.Address{predeploys.L2ToL1MessagePasserAddr},
+			Topics:    [][]common.Hash{{passer.Events["MessagePassed"].ID}},
+		})
+	}
+
+	for start := x.next; start <= head; start += x.chunkSize {
+		end := start + x.chunkSize - 1
+		if end > head {
+			end = head
+		}
+		for _, q := range queries {
+			q.FromBlock = new(big.Int).SetUint64(start)
+			q.ToBlock = new(big.Int).SetUint64(end)
+			logs, err := x.logs.FilterLogs(ctx, q)
+			if err != nil {
+				return fmt.Errorf("error filtering withdrawals in L2 blocks %d-%d: %w", start, end, err)
+			}
+			for _, l := range logs {
+				if _, err := x.d.store.Get(l.TxHash); err == nil {
+					continue
+				}
+				// only the query for every withdrawal doesn't filter by sender
+				if len(q.Topics) == 1 {
+					account, err := x.attribute(ctx, passer, l)
+					if err != nil {
+						return err
+					}
+					if account == (common.Address{}) {
+						continue
+					}
+					x.d.log.Info("Discovered withdrawal routed through another contract", "tx", l.TxHash, "l2Block", l.BlockNumber, "account", account)
+				} else {
+					x.d.log.Info("Discovered withdrawal", "tx", l.TxHash, "l2Block", l.BlockNumber)
+				}
+				if err := x.d.Track(l.TxHash); err != nil {
+					return err
+				}
+				x.d.Wake()
+			}
+		}
+		x.next = end + 1
+	}
+	return nil
+}
+
+// attribute returns the sender the withdrawal logged by l is attributed to, or the zero address if none:
+// the sender or recipient of the standard bridge transfer or messenger message it relays, or the account
+// that sent its L2 transaction, for aggregators and custom bridges whose messages can't be decoded.
+func (x *Discoverer) attribute(ctx context.Context, passer *abi.ABI, l types.Log) (common.Address, error) {
+	if len(l.Topics) < 4 {
+		return common.Address{}, nil
+	}
+	var ev bindings.L2ToL1MessagePasserMessagePassed
+	if err := passer.UnpackIntoInterface(&ev, "MessagePassed", l.Data); err != nil {
+		return common.Address{}, fmt.Errorf("error decoding withdrawal in tx %s: %w", l.TxHash, err)
+	}
+	route := withdraw.DecodeRoute(common.BytesToAddress(l.Topics[2].Bytes()), common.BytesToAddress(l.Topics[3].Bytes()), ev.Data)
+	for _, account := range []common.Address{route.From(), route.To()} {
+		if slices.Contains(x.senders, account) {
+			return account, nil
+		}
+	}
+	tx, _, err := x.l2.TransactionByHash(ctx, l.TxHash)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error querying withdrawal tx %s: %w", l.TxHash, err)
+	}
+	from, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil || !slices.Contains(x.senders, from) {
+		return common.Address{}, nil
+	}
+	return from, nil
+}
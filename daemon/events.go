@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Wake triggers a scan without waiting for the next interval.
+func (d *Daemon) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+		// a scan is pending already
+	}
+}
+
+// WatchDisputeGames wakes d whenever factory creates a dispute game, so tracked withdrawals are
+// proven as soon as a game covering their block appears. It returns when ctx is canceled.
+func WatchDisputeGames(ctx context.Context, d *Daemon, l1 *ethclient.Client, factory common.Address, pollInterval time.Duration) error {
+	dgf, err := bindings.NewDisputeGameFactoryFilterer(factory, l1)
+	if err != nil {
+		return err
+	}
+	parsed, err := bindings.DisputeGameFactoryMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{factory},
+		Topics:    [][]common.Hash{{parsed.Events["DisputeGameCreated"].ID}},
+	}
+	return watchLogs(ctx, l1, query, pollInterval, func(l types.Log) {
+		ev, err := dgf.ParseDisputeGameCreated(l)
+		if err != nil {
+			d.log.Warn("Error parsing DisputeGameCreated event", "tx", l.TxHash, "error", err)
+			return
+		}
+		d.log.Info("Dispute game created, scanning withdrawals", "game", ev.DisputeProxy, "rootClaim", common.Hash(ev.RootClaim), "l1Block", l.BlockNumber)
+		d.Wake()
+	})
+}
+
+// WatchOutputProposals wakes d whenever a new output is proposed to the L2OutputOracle at oracle, so
+// tracked withdrawals on networks without fault proofs are proven as soon as a qualifying output lands.
+// It returns when ctx is canceled.
+func WatchOutputProposals(ctx context.Context, d *Daemon, l1 *ethclient.Client, oracle common.Address, pollInterval time.Duration) error {
+	l2oo, err := bindings.NewL2OutputOracleFilterer(oracle, l1)
+	if err != nil {
+		return err
+	}
+	parsed, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{oracle},
+		Topics:    [][]common.Hash{{parsed.Events["OutputProposed"].ID}},
+	}
+	return watchLogs(ctx, l1, query, pollInterval, func(l types.Log) {
+		ev, err := l2oo.ParseOutputProposed(l)
+		if err != nil {
+			d.log.Warn("Error parsing OutputProposed event", "tx", l.TxHash, "error", err)
+			return
+		}
+		d.log.Info("Output proposed, scanning withdrawals", "l2Block", ev.L2BlockNumber, "outputIndex", ev.L2OutputIndex, "l1Block", l.BlockNumber)
+		d.Wake()
+	})
+}
+
+// watchLogs calls onLog for every new log matching query. It subscribes to logs if the client supports
+// subscriptions (i.e. is connected over WebSocket or IPC), and polls for them every pollInterval
+// otherwise or whenever the subscription fails.
+func watchLogs(ctx context.Context, client *ethclient.Client, query ethereum.FilterQuery, pollInterval time.Duration, onLog func(types.Log)) error {
+	logs := make(chan types.Log)
+	sub, err := client.SubscribeFilterLogs(ctx, query, logs)
+	if err == nil {
+		defer sub.Unsubscribe()
+		// from is the block to resume polling at if the subscription fails, so no logs are missed.
+		// Logs of that block may be passed to onLog twice.
+		var from uint64
+		if head, err := client.BlockNumber(ctx); err == nil {
+			from = head + 1
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-sub.Err():
+				log.Warn("Log subscription failed, polling instead", "error", err, "from", from)
+				return pollLogs(ctx, client, query, from, pollInterval, onLog)
+			case l := <-logs:
+				if !l.Removed {
+					onLog(l)
+					from = max(from, l.BlockNumber)
+				}
+			}
+		}
+	}
+	log.Debug("Log subscriptions not supported, polling instead", "error", err)
+	return pollLogs(ctx, client, query, 0, pollInterval, onLog)
+}
+
+// pollLogs calls onLog for every log matching query in blocks from block from onwards, or mined after it
+// was started if from is 0.
+func pollLogs(ctx context.Context, client *ethclient.Client, query ethereum.FilterQuery, from uint64, pollInterval time.Duration, onLog func(types.Log)) error {
+	next := from
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Warn("Error querying L1 head", "error", err)
+		} else if next == 0 {
+			next = head + 1
+		} else if head >= next {
+			q := query
+			q.FromBlock = new(big.Int).SetUint64(next)
+			q.ToBlock = new(big.Int).SetUint64(head)
+			found, err := client.FilterLogs(ctx, q)
+			if err != nil {
+				log.Warn("Error polling logs", "from", next, "to", head, "error", err)
+			} else {
+				for _, l := range found {
+					onLog(l)
+				}
+				next = head + 1
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
+)
+
+// LogFilterer is the source of the logs withdrawals are discovered from: the L2 RPC, or an ExplorerLogs.
+type LogFilterer interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// explorerPageSize is the maximum number of logs returned per request by the Etherscan logs API.
+const explorerPageSize = 1000
+
+// ExplorerLogs queries logs through the Etherscan-compatible logs API of a block explorer, which
+// Etherscan and Blockscout instances provide, for L2 RPCs that don't serve eth_getLogs over large
+// ranges, such as free public endpoints.
+type ExplorerLogs struct {
+	// url is the API endpoint, e.g. https://api.basescan.org/api or https://base.blockscout.com/api,
+	// possibly with query parameters such as the chainid of the Etherscan V2 API.
+	url     string
+	apiKey  string
+	client  *http.Client
+	limiter *rate.Limiter
+}
+
+// NewExplorerLogs creates an ExplorerLogs for the API at url, sending at most requestsPerSecond requests
+// per second to stay within the limits of free API keys.
+func NewExplorerLogs(url, apiKey string, requestsPerSecond float64) *ExplorerLogs {
+	return &ExplorerLogs{url: url, apiKey: apiKey, client: http.DefaultClient, limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1)}
+}
+
+// FilterLogs implements LogFilterer. The query must have a block range and a single address; as the API
+// only takes one value per topic, a request is made for every combination of the values of q.Topics.
+func (e *ExplorerLogs) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if len(q.Addresses) != 1 || q.FromBlock == nil || q.ToBlock == nil {
+		return nil, fmt.Errorf("explorer log queries need a block range and a single address")
+	}
+	var logs []types.Log
+	for _, topics := range topicCombinations(q.Topics) {
+		for page := 1; ; page++ {
+			params := url.Values{
+				"module":    {"logs"},
+				"action":    {"getLogs"},
+				"address":   {q.Addresses[0].Hex()},
+				"fromBlock": {q.FromBlock.String()},
+				"toBlock":   {q.ToBlock.String()},
+				"page":      {strconv.Itoa(page)},
+				"offset":    {strconv.Itoa(explorerPageSize)},
+			}
+			for i, topic := range topics {
+				if topic == nil {
+					continue
+				}
+				params.Set(fmt.Sprintf("topic%d", i), topic.Hex())
+				// topics are or'ed unless told otherwise
+				if i > 0 {
+					params.Set(fmt.Sprintf("topic0_%d_opr", i), "and")
+				}
+			}
+			result, err := e.get(ctx, params)
+			if err != nil {
+				return nil, err
+			}
+			logs = append(logs, result...)
+			if len(result) < explorerPageSize {
+				break
+			}
+		}
+	}
+	return logs, nil
+}
+
+// explorerLog is a log as returned by the Etherscan logs API, with quantities as hex or decimal strings.
+type explorerLog struct {
+	Address         common.Address `json:"address"`
+	Topics          []common.Hash  `json:"topics"`
+	Data            hexutil.Bytes  `json:"data"`
+	BlockNumber     string         `json:"blockNumber"`
+	TransactionHash common.Hash    `json:"transactionHash"`
+	LogIndex        string         `json:"logIndex"`
+}
+
+// get requests the logs matching params.
+func (e *ExplorerLogs) get(ctx context.Context, params url.Values) ([]types.Log, error) {
+	if err := e.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(e.url)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	for k, v := range params {
+		query[k] = v
+	}
+	if e.apiKey != "" {
+		query.Set("apikey", e.apiKey)
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error querying explorer API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("explorer API responded with status %s", resp.Status)
+	}
+	var body struct {
+		Status  string          `json:"status"`
+		Message string          `json:"message"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("error decoding explorer API response: %w", err)
+	}
+	var result []explorerLog
+	if err := json.Unmarshal(body.Result, &result); err != nil {
+		// errors, such as rate limits, are reported as a string result
+		var msg string
+		_ = json.Unmarshal(body.Result, &msg)
+		return nil, fmt.Errorf("explorer API error: %s %s", body.Message, msg)
+	}
+
+	logs := make([]types.Log, len(result))
+	for i, l := range result {
+		block, err := parseQuantity(l.BlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block number %q in explorer API response", l.BlockNumber)
+		}
+		index, _ := parseQuantity(l.LogIndex)
+		logs[i] = types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data, BlockNumber: block, TxHash: l.TransactionHash, Index: uint(index)}
+	}
+	return logs, nil
+}
+
+// topicCombinations returns every list of topics with at most one value per position matching topics,
+// with nil for positions matching any value.
+func topicCombinations(topics [][]common.Hash) [][]*common.Hash {
+	combinations := [][]*common.Hash{{}}
+	for _, values := range topics {
+		var next [][]*common.Hash
+		if len(values) == 0 {
+			for _, c := range combinations {
+				next = append(next, append(c[:len(c):len(c)], nil))
+			}
+		}
+		for _, v := range values {
+			v := v
+			for _, c := range combinations {
+				next = append(next, append(c[:len(c):len(c)], &v))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// parseQuantity parses a hex (0x-prefixed) or decimal quantity, as explorers return either. Etherscan
+// returns zero as a bare 0x.
+func parseQuantity(s string) (uint64, error) {
+	if hex, ok := strings.CutPrefix(s, "0x"); ok {
+		if hex == "" {
+			return 0, nil
+		}
+		return strconv.ParseUint(hex, 16, 64)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
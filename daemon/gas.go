@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// maxFeeHistoryBlocks is the maximum number of blocks nodes return per eth_feeHistory request.
+const maxFeeHistoryBlocks = 1024
+
+// gasWindowTimeout bounds the queries checking the gas window.
+const gasWindowTimeout = 30 * time.Second
+
+// GasWindow holds off finalizations, which are not urgent once a withdrawal is mature, until L1 gas
+// is cheap: the base fee is at most a percentile of the recent base fees, and/or the time of day is
+// within a window.
+type GasWindow struct {
+	Client *ethclient.Client
+	// Percentile (0-100) of the base fees of the last Blocks L1 blocks that the current base fee must
+	// not exceed, 0 to disable.
+	Percentile float64
+	Blocks     uint64
+	// StartHour and EndHour restrict finalizations to UTC hours in [StartHour, EndHour), which may wrap
+	// around midnight. Equal hours disable the restriction.
+	StartHour, EndHour int
+	// Deadline is how long a withdrawal may wait for the window once it can be finalized, 0 for no limit.
+	Deadline time.Duration
+}
+
+// closedReason returns why the window is closed at now, or an empty string if it is open.
+func (g *GasWindow) closedReason(now time.Time) (string, error) {
+	if g.StartHour != g.EndHour {
+		h := now.UTC().Hour()
+		in := h >= g.StartHour && h < g.EndHour
+		if g.StartHour > g.EndHour {
+			in = h >= g.StartHour || h < g.EndHour
+		}
+		if !in {
+			return fmt.Sprintf("outside of finalization hours %02d:00-%02d:00 UTC", g.StartHour, g.EndHour), nil
+		}
+	}
+	if g.Percentile <= 0 {
+		return "", nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), gasWindowTimeout)
+	defer cancel()
+	head, err := g.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error querying L1 head: %w", err)
+	}
+	if head.BaseFee == nil {
+		// the window is considered open, as with any other error
+		return "", fmt.Errorf("L1 head %s has no base fee", head.Number)
+	}
+	target, err := g.targetBaseFee(ctx, head.Number)
+	if err != nil {
+		return "", err
+	}
+	if head.BaseFee.Cmp(target) > 0 {
+		return fmt.Sprintf("L1 base fee %s wei above target %s wei (p%g of the last %d blocks)", head.BaseFee, target, g.Percentile, g.Blocks), nil
+	}
+	return "", nil
+}
+
+// targetBaseFee returns the configured percentile of the base fees of the Blocks blocks up to head.
+func (g *GasWindow) targetBaseFee(ctx context.Context, head *big.Int) (*big.Int, error) {
+	var fees []*big.Int
+	last := new(big.Int).Set(head)
+	for remaining := g.Blocks; remaining > 0 && last.Sign() >= 0; {
+		count := min(remaining, maxFeeHistoryBlocks)
+		history, err := g.Client.FeeHistory(ctx, count, last, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error querying fee history: %w", err)
+		}
+		// the last entry is the base fee of the block after last
+		n := len(history.BaseFee) - 1
+		if n <= 0 {
+			break
+		}
+		fees = append(fees, history.BaseFee[:n]...)
+		remaining -= uint64(n)
+		last.Sub(last, big.NewInt(int64(n)))
+	}
+	if len(fees) == 0 {
+		return nil, fmt.Errorf("no fee history available")
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i].Cmp(fees[j]) < 0 })
+	i := int(float64(len(fees)-1) * g.Percentile / 100)
+	return fees[i], nil
+}
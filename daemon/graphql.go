@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultGraphQLQuery is the query a GraphQLDiscoverer sends by default, which matches subgraphs indexing
+// the WithdrawalInitiated events of the L2StandardBridge with the schema generated by graph init.
+const DefaultGraphQLQuery = `query($senders: [Bytes!], $fromBlock: BigInt!) {
+  withdrawalInitiateds(first: 1000, orderBy: blockNumber, orderDirection: asc, where: {from_in: $senders, blockNumber_gte: $fromBlock}) {
+    transactionHash
+    blockNumber
+  }
+}`
+
+// GraphQLDiscoverer finds new withdrawals initiated by a set of L2 accounts by querying a GraphQL
+// endpoint, such as a bridge subgraph or an indexer, instead of scanning logs, and tracks them with a
+// Daemon.
+//
+// The query is passed the senders as lowercase hex strings in $senders, and the L2 block to start from as
+// a decimal string in $fromBlock. Every object in its result with a transactionHash field is a withdrawal,
+// and the highest blockNumber field is where the next poll starts from.
+type GraphQLDiscoverer struct {
+	d       *Daemon
+	url     string
+	query   string
+	senders []string
+	client  *http.Client
+	next    uint64
+}
+
+// NewGraphQLDiscoverer creates a GraphQLDiscoverer tracking the withdrawals senders initiate from L2 block
+// fromBlock on, found with query at url.
+func NewGraphQLDiscoverer(d *Daemon, url, query string, senders []common.Address, fromBlock uint64) *GraphQLDiscoverer {
+	hexSenders := make([]string, len(senders))
+	for i, s := range senders {
+		hexSenders[i] = strings.ToLower(s.Hex())
+	}
+	return &GraphQLDiscoverer{d: d, url: url, query: query, senders: hexSenders, client: http.DefaultClient, next: fromBlock}
+}
+
+// Run polls for new withdrawals every interval until ctx is canceled.
+func (x *GraphQLDiscoverer) Run(ctx context.Context, interval time.Duration) error {
+	return pollEvery(ctx, interval, x.Poll)
+}
+
+// Poll tracks the withdrawals returned by the query since the last poll.
+func (x *GraphQLDiscoverer) Poll(ctx context.Context) error {
+	body, err := json.Marshal(map[string]any{
+		"query":     x.query,
+		"variables": map[string]any{"senders": x.senders, "fromBlock": strconv.FormatUint(x.next, 10)},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, x.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error querying GraphQL endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("GraphQL endpoint responded with status %s", resp.Status)
+	}
+	var result struct {
+		Data   any `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("error decoding GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("GraphQL query failed: %s", result.Errors[0].Message)
+	}
+
+	next := x.next
+	var withdrawals []graphQLWithdrawal
+	collectWithdrawals(result.Data, &withdrawals)
+	for _, w := range withdrawals {
+		if w.block > next {
+			next = w.block
+		}
+		if _, err := x.d.store.Get(w.tx); err == nil {
+			continue
+		}
+		x.d.log.Info("Discovered withdrawal", "tx", w.tx, "l2Block", w.block)
+		if err := x.d.Track(w.tx); err != nil {
+			return err
+		}
+		x.d.Wake()
+	}
+	// the last block is queried again, in case it has more withdrawals than a page holds
+	x.next = next
+	return nil
+}
+
+// graphQLWithdrawal is a withdrawal found in a GraphQL response.
+type graphQLWithdrawal struct {
+	tx    common.Hash
+	block uint64
+}
+
+// collectWithdrawals appends the objects with a transactionHash field found in v to withdrawals.
+func collectWithdrawals(v any, withdrawals *[]graphQLWithdrawal) {
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			collectWithdrawals(e, withdrawals)
+		}
+	case map[string]any:
+		if hash, ok := v["transactionHash"].(string); ok {
+			w := graphQLWithdrawal{tx: common.HexToHash(hash)}
+			switch block := v["blockNumber"].(type) {
+			case string:
+				w.block, _ = strconv.ParseUint(block, 0, 64)
+			case float64:
+				w.block = uint64(block)
+			}
+			*withdrawals = append(*withdrawals, w)
+			return
+		}
+		for _, e := range v {
+			collectWithdrawals(e, withdrawals)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Check reports whether a dependency of the daemon, such as an RPC endpoint, is healthy.
+type Check func(ctx context.Context) error
+
+// healthCheckTimeout bounds the time spent on all checks of a single health request.
+const healthCheckTimeout = 5 * time.Second
+
+// HealthzHandler serves the liveness probe, which fails if the scan loop has stalled or the store is
+// unreachable, in which case restarting the daemon may help.
+func (d *Daemon) HealthzHandler() http.Handler {
+	return HealthzHandler(d)
+}
+
+// HealthzHandler serves the liveness probe of the daemons of several networks sharing a store, which
+// fails if the scan loop of any of them has stalled or the store is unreachable. The loop of each is
+// reported as loop/<network>, or loop for the default network.
+func HealthzHandler(daemons ...*Daemon) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		results := map[string]string{"store": "ok"}
+		healthy := true
+
+		for _, d := range daemons {
+			key := "loop"
+			if d.cfg.Network != "" {
+				key += "/" + d.cfg.Network
+			}
+			results[key] = "ok"
+			// allow for a slow withdrawal before considering the loop stalled
+			last := time.Unix(d.lastLoop.Load(), 0)
+			if stalled := time.Since(last); stalled > 3*d.cfg.Interval+time.Minute {
+				results[key] = fmt.Sprintf("no scan for %s", stalled.Round(time.Second))
+				healthy = false
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+		if err := daemons[0].store.Ping(ctx); err != nil {
+			results["store"] = err.Error()
+			healthy = false
+		}
+		writeHealth(w, healthy, results)
+	})
+}
+
+// ReadyzHandler serves the readiness probe, which fails if the store or any of the given checks,
+// e.g. of the L1 and L2 RPC endpoints, fail.
+func (d *Daemon) ReadyzHandler(checks map[string]Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		results := make(map[string]string)
+		healthy := true
+		all := map[string]Check{"store": d.store.Ping}
+		for name, check := range checks {
+			all[name] = check
+		}
+		for name, check := range all {
+			if err := check(ctx); err != nil {
+				results[name] = err.Error()
+				healthy = false
+			} else {
+				results[name] = "ok"
+			}
+		}
+		writeHealth(w, healthy, results)
+	})
+}
+
+func writeHealth(w http.ResponseWriter, healthy bool, results map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(results)
+}
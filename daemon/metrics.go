@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/base-org/withdrawer/store"
+)
+
+// Metrics are the Prometheus metrics exported by the daemon.
+type Metrics struct {
+	registry       *prometheus.Registry
+	withdrawals    *prometheus.GaugeVec
+	actionFailures *prometheus.CounterVec
+	deadLettered   *prometheus.CounterVec
+	leader         prometheus.Gauge
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		withdrawals: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "withdrawer",
+			Name:      "withdrawals",
+			Help:      "Number of tracked withdrawals by status",
+		}, []string{"network", "status"}),
+		actionFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "withdrawer",
+			Name:      "action_failures_total",
+			Help:      "Number of failed prove or finalize attempts",
+		}, []string{"network", "action"}),
+		deadLettered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "withdrawer",
+			Name:      "dead_lettered_total",
+			Help:      "Number of withdrawals moved to the dead-letter state",
+		}, []string{"network"}),
+		leader: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "withdrawer",
+			Name:      "leader",
+			Help:      "Whether this replica is the leader submitting transactions (1) or standing by (0)",
+		}),
+	}
+	m.registry.MustRegister(m.withdrawals, m.actionFailures, m.deadLettered, m.leader)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// recordWithdrawals records the number of withdrawals of network by status. The network label is empty
+// for the default network, which Prometheus treats like an unset label.
+func (m *Metrics) recordWithdrawals(network string, withdrawals []*store.Withdrawal) {
+	counts := make(map[store.Status]int)
+	for _, w := range withdrawals {
+		counts[w.Status]++
+	}
+	for _, status := range store.Statuses {
+		m.withdrawals.WithLabelValues(network, string(status)).Set(float64(counts[status]))
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
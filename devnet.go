@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// devnetKey is the private key of the first prefunded account of op-e2e, Kurtosis and Anvil devnets,
+// which is public.
+const devnetKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// runDevnet implements the devnet subcommand, which runs a withdrawal end to end on a local devnet:
+// it initiates a withdrawal on L2, waits for an output or dispute game covering it, proves it,
+// fast-forwards L1 past the challenge window (or waits for it, if the L1 node can't), and finalizes
+// it. It exits with an error if any step fails, so that it can be used as an integration test.
+func runDevnet(args []string) {
+	fs := flag.NewFlagSet("devnet", flag.ExitOnError)
+	f := registerFlags(fs)
+	var amount, recipient string
+	var timeout time.Duration
+	fs.StringVar(&amount, "amount", "0.001ether", "Amount of ETH to withdraw (e.g. 0.001ether or 1000gwei)")
+	fs.StringVar(&recipient, "recipient", "", "L1 address to withdraw to (default: the signer's address)")
+	fs.DurationVar(&timeout, "timeout", 30*time.Minute, "Maximum duration of the whole run")
+	_ = fs.Parse(args)
+
+	if f.portalAddress == "" {
+		log.Crit("The devnet subcommand requires the contracts of the devnet, pass --l2-rpc, --portal-address and --l2oo-address or --fault-proofs --dfg-address")
+	}
+	if f.privateKey == "" && !f.ledger && f.mnemonic == "" {
+		f.privateKey, f.acceptKeyRisk = devnetKey, true
+	}
+	value := parseAmount("--amount", amount)
+	n := f.resolveNetwork()
+	s := f.createSigner()
+	to := s.Address()
+	if recipient != "" {
+		if !common.IsHexAddress(recipient) {
+			log.Crit("Invalid --recipient address", "address", recipient)
+		}
+		to = common.HexToAddress(recipient)
+	}
+
+	ctx, cancel := context.WithTimeout(interruptContext(), timeout)
+	defer cancel()
+	started := time.Now()
+
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	withdrawal, err := initiateWithdrawal(ctx, n, s, to, value)
+	if err != nil {
+		exitWithError("Error initiating withdrawal", err)
+	}
+
+	helper, err := CreateWithdrawHelper(ctx, f.rpc, withdrawal, n, s, f.helperConfig(nil))
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+	fmt.Println("Waiting for an output or dispute game covering the withdrawal to be proposed...")
+	for {
+		err := helper.CheckIfProvable(ctx)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, withdraw.ErrNotProvableYet) {
+			exitWithError("Withdrawal is not provable", err)
+		}
+		if err := sleep(ctx, f.pollInterval); err != nil {
+			exitWithError("Timed out waiting for a proposal", err)
+		}
+	}
+	proveTx, err := helper.ProveWithdrawal(ctx)
+	if err != nil {
+		exitWithError("Error proving withdrawal", err)
+	}
+
+	if err := awaitMaturity(ctx, l1, helper, n, s, f.pollInterval); err != nil {
+		exitWithError("Error waiting for the challenge window", err)
+	}
+	// the helper was created with the nonce the prove tx used, so a new one is needed to finalize
+	helper, err = CreateWithdrawHelper(ctx, f.rpc, withdrawal, n, s, f.helperConfig(nil))
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+	finalizeTx, err := helper.FinalizeWithdrawal(ctx)
+	if err != nil {
+		exitWithError("Error completing withdrawal", err)
+	}
+
+	fmt.Printf("\nDevnet withdrawal completed in %s\n", time.Since(started).Round(time.Second))
+	fmt.Printf("  Initiated: %s\n", withdrawal)
+	fmt.Printf("  Proven:    %s\n", proveTx)
+	fmt.Printf("  Finalized: %s\n", finalizeTx)
+}
+
+// initiateWithdrawal withdraws value to to through the L2ToL1MessagePasser, returning the hash of the
+// L2 transaction once it is mined.
+func initiateWithdrawal(ctx context.Context, n network, s signer.Signer, to common.Address, value *big.Int) (common.Hash, error) {
+	l2c, err := n.dialL2(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error dialing L2 client: %w", err)
+	}
+	l2 := ethclient.NewClient(l2c)
+	chainID, err := l2.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error querying L2 chain ID: %w", err)
+	}
+	passer, err := bindings.NewL2ToL1MessagePasserTransactor(predeploys.L2ToL1MessagePasserAddr, l2)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts := &bind.TransactOpts{From: s.Address(), Signer: s.SignerFn(chainID), Context: ctx, Value: value}
+	tx, err := passer.InitiateWithdrawal(opts, to, big.NewInt(100_000), nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	receipt, err := bind.WaitMined(ctx, l2, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return common.Hash{}, fmt.Errorf("withdrawal tx %s reverted", tx.Hash())
+	}
+	fmt.Printf("Initiated withdrawal %s in L2 block %d\n", tx.Hash(), receipt.BlockNumber)
+	return tx.Hash(), nil
+}
+
+// awaitMaturity fast-forwards L1 until the proven withdrawal has matured, or waits for it if the L1
+// node doesn't support it, resolving the unchallenged dispute game it was proven against on the way.
+func awaitMaturity(ctx context.Context, l1 *ethclient.Client, helper withdraw.WithdrawHelper, n network, s signer.Signer, poll time.Duration) error {
+	scheduler, ok := helper.(withdraw.Scheduler)
+	if !ok {
+		return errors.New("the finalization time of the withdrawal can't be estimated")
+	}
+	chainID, err := l1.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	send := func(ctx context.Context, to common.Address, data []byte) error {
+		opts := &bind.TransactOpts{From: s.Address(), Signer: s.SignerFn(chainID), Context: ctx}
+		tx, err := bind.NewBoundContract(to, abi.ABI{}, l1, l1, l1).RawTransact(opts, data)
+		if err != nil {
+			return err
+		}
+		receipt, err := bind.WaitMined(ctx, l1, tx)
+		if err == nil && receipt.Status != types.ReceiptStatusSuccessful {
+			err = fmt.Errorf("tx %s reverted", tx.Hash())
+		}
+		return err
+	}
+
+	warp := true
+	// the finality delay of a dispute game only starts once it is resolved, which takes a second wait
+	for i := 0; i < 2; i++ {
+		at, err := scheduler.EarliestFinalizeTime(ctx)
+		if err != nil {
+			return err
+		}
+		if warp {
+			if err := fastForward(ctx, l1.Client(), at); err != nil {
+				fmt.Printf("L1 can't be fast-forwarded (%v), waiting until %s instead\n", err, at.UTC().Format(time.RFC3339))
+				warp = false
+			}
+		}
+		for !warp {
+			head, err := l1.HeaderByNumber(ctx, nil)
+			if err != nil {
+				return err
+			}
+			if int64(head.Time) > at.Unix() {
+				break
+			}
+			if err := sleep(ctx, poll); err != nil {
+				return err
+			}
+		}
+		if !n.FaultProofs {
+			return nil
+		}
+		if err := resolveGame(ctx, l1, helper, n.PortalAddress, s.Address(), send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,257 @@
+// Package failover spreads JSON-RPC requests over several HTTP endpoints, failing over to the next one
+// when an endpoint is down or rate limited, and retries requests that failed with transient errors.
+package failover
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gorilla/websocket"
+)
+
+// Transport is an http.RoundTripper sending each JSON-RPC request to one of several endpoints, trying the
+// next one on connection errors, 429s and 5xx responses. Endpoints are tried in order of priority, or
+// starting with the next one for every request with round robin. eth_getProof requests go to the proof
+// endpoints, skipping those that responded to it with "method not found".
+type Transport struct {
+	endpoints      []*url.URL
+	proofEndpoints []*url.URL
+	roundRobin     bool
+	base           http.RoundTripper
+
+	mu      sync.Mutex
+	next    int
+	noProof map[int]bool
+}
+
+// NewTransport returns a transport for the endpoints, sending eth_getProof requests to proofURLs, or to
+// the endpoints if there are none.
+func NewTransport(urls, proofURLs []string, roundRobin bool) (*Transport, error) {
+	endpoints, err := parseURLs(urls)
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints")
+	}
+	proofEndpoints := endpoints
+	if len(proofURLs) > 0 {
+		if proofEndpoints, err = parseURLs(proofURLs); err != nil {
+			return nil, err
+		}
+	}
+	return &Transport{
+		endpoints:      endpoints,
+		proofEndpoints: proofEndpoints,
+		roundRobin:     roundRobin,
+		base:           http.DefaultTransport,
+		noProof:        make(map[int]bool),
+	}, nil
+}
+
+func parseURLs(urls []string) ([]*url.URL, error) {
+	var endpoints []*url.URL
+	for _, s := range urls {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RPC url %q: %w", s, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("RPC url %q: failover is only supported over HTTP", s)
+		}
+		endpoints = append(endpoints, u)
+	}
+	return endpoints, nil
+}
+
+// Options configures the clients returned by Dial.
+type Options struct {
+	// ProofURLs are the comma-separated urls eth_getProof requests are sent to, if set.
+	ProofURLs string
+	// RoundRobin spreads requests over the urls, instead of failing over in order.
+	RoundRobin bool
+	// Retries is the maximum number of retries of requests that failed with a transient error, and
+	// RetryBackoff the delay before the first retry, which doubles with every further one.
+	Retries      int
+	RetryBackoff time.Duration
+	// Headers are added to every request to the urls, e.g. to authenticate with the endpoints. They aren't
+	// sent to the VerifiedURL.
+	Headers http.Header
+	// RateLimit, if set, is the maximum number of requests per second sent to each endpoint, across
+	// all clients.
+	RateLimit float64
+	// DialTimeout, if set, limits connecting to an endpoint, including the TLS or WebSocket handshake, and
+	// ResponseTimeout waiting for the response to a request, so that dead endpoints fail fast instead of
+	// hanging.
+	DialTimeout     time.Duration
+	ResponseTimeout time.Duration
+	// IdleConnTimeout, if set, is how long idle connections are kept open for reuse, and MaxIdleConns how
+	// many of them are kept per endpoint. DisableKeepAlives opens a new connection for every request.
+	IdleConnTimeout   time.Duration
+	MaxIdleConns      int
+	DisableKeepAlives bool
+	// VerifiedURL, if set, is the HTTP url of a light client, such as Helios, that state reads are sent to
+	// instead of the urls, so that their responses are verified rather than trusted.
+	VerifiedURL string
+	// Trace logs every request sent over HTTP, with the endpoint, method, params, duration and error.
+	Trace bool
+}
+
+// Dial returns a client for the comma-separated RPC urls, which fails over between them if there are
+// several. Only HTTP clients fail over and retry requests; a single WebSocket or IPC url is dialed as is.
+// Connections go through the proxy of http.DefaultTransport, which defaults to the one configured in the
+// standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func Dial(ctx context.Context, urls string, opts Options) (*rpc.Client, error) {
+	list, proofList := splitURLs(urls), splitURLs(opts.ProofURLs)
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no RPC endpoints")
+	}
+	base := httpTransport(opts)
+	if opts.Trace {
+		base = &traceTransport{base: base}
+	}
+	if opts.RateLimit > 0 {
+		base = &rateLimitTransport{base: base, requestsPerSecond: opts.RateLimit}
+	}
+	transport := base
+	if len(list) > 1 || len(proofList) > 0 {
+		t, err := NewTransport(list, proofList, opts.RoundRobin)
+		if err != nil {
+			return nil, err
+		}
+		t.base = transport
+		transport = t
+	} else if u, err := url.Parse(list[0]); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		if opts.VerifiedURL != "" {
+			return nil, fmt.Errorf("verified reads require HTTP RPC urls, not %s", list[0])
+		}
+		if opts.Trace {
+			log.Warn("RPC tracing is only supported over HTTP", "url", list[0])
+		}
+		dialer := websocket.Dialer{Proxy: http.DefaultTransport.(*http.Transport).Proxy, HandshakeTimeout: opts.DialTimeout}
+		if opts.DialTimeout > 0 {
+			dialer.NetDialContext = (&net.Dialer{Timeout: opts.DialTimeout}).DialContext
+		}
+		return rpc.DialOptions(ctx, list[0], rpc.WithHeaders(opts.Headers), rpc.WithWebsocketDialer(dialer))
+	}
+	// the headers authenticate with the RPC provider, so they are only sent to its endpoints, and not to
+	// the light client
+	if len(opts.Headers) > 0 {
+		transport = &headerTransport{base: transport, headers: opts.Headers}
+	}
+	if opts.VerifiedURL != "" {
+		u, err := url.Parse(opts.VerifiedURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("invalid light client url %s, expected an HTTP url", opts.VerifiedURL)
+		}
+		transport = &verifiedTransport{endpoint: u, verified: base, next: transport}
+	}
+	if opts.Retries > 0 {
+		transport = &retryTransport{base: transport, retries: opts.Retries, backoff: opts.RetryBackoff}
+	}
+	return rpc.DialOptions(ctx, list[0], rpc.WithHTTPClient(&http.Client{Transport: transport}))
+}
+
+func splitURLs(s string) []string {
+	var urls []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	proof := bytes.Contains(body, []byte(`"eth_getProof"`))
+	endpoints := t.endpoints
+	if proof {
+		endpoints = t.proofEndpoints
+	}
+
+	var lastErr error
+	for _, i := range t.order(len(endpoints), proof) {
+		u := *endpoints[i]
+		r := req.Clone(req.Context())
+		r.URL, r.Host = &u, u.Host
+		r.Body, r.ContentLength = io.NopCloser(bytes.NewReader(body)), int64(len(body))
+		resp, err := t.base.RoundTrip(r)
+		if err != nil {
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+			log.Warn("RPC endpoint failed, trying the next one", "host", u.Host, "error", err)
+			continue
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("%s: %s", u.Host, resp.Status)
+			log.Warn("RPC endpoint failed, trying the next one", "host", u.Host, "status", resp.Status)
+			continue
+		}
+		if proof {
+			data, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if bytes.Contains(data, []byte(`"code":-32601`)) {
+				log.Warn("RPC endpoint doesn't support eth_getProof, no longer using it for proofs", "host", u.Host)
+				t.mu.Lock()
+				t.noProof[i] = true
+				t.mu.Unlock()
+				lastErr = fmt.Errorf("%s: eth_getProof not supported", u.Host)
+				continue
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(data))
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// order returns the order to try n endpoints in. For proofs, endpoints known not to support them are
+// left out, unless that leaves none.
+func (t *Transport) order(n int, proof bool) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	start := 0
+	if t.roundRobin {
+		start = t.next % n
+		t.next++
+	}
+	var order []int
+	for j := 0; j < n; j++ {
+		i := (start + j) % n
+		if proof && t.noProof[i] {
+			continue
+		}
+		order = append(order, i)
+	}
+	if len(order) == 0 {
+		for j := 0; j < n; j++ {
+			order = append(order, (start+j)%n)
+		}
+	}
+	return order
+}
@@ -0,0 +1,72 @@
+package failover
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// transportConfig holds the connection settings of Options.
+type transportConfig struct {
+	dialTimeout       time.Duration
+	responseTimeout   time.Duration
+	idleConnTimeout   time.Duration
+	maxIdleConns      int
+	disableKeepAlives bool
+}
+
+var (
+	transportsMu sync.Mutex
+	// transports are shared by all clients with the same settings, so that connections are reused across them.
+	transports = make(map[transportConfig]*http.Transport)
+)
+
+// httpTransport returns the transport with the connection settings of opts, which is http.DefaultTransport
+// if there are none. Other transports are based on http.DefaultTransport, including its proxy.
+func httpTransport(opts Options) http.RoundTripper {
+	cfg := transportConfig{
+		dialTimeout:       opts.DialTimeout,
+		responseTimeout:   opts.ResponseTimeout,
+		idleConnTimeout:   opts.IdleConnTimeout,
+		maxIdleConns:      opts.MaxIdleConns,
+		disableKeepAlives: opts.DisableKeepAlives,
+	}
+	if cfg == (transportConfig{}) {
+		return http.DefaultTransport
+	}
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if t, ok := transports[cfg]; ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.dialTimeout > 0 {
+		t.DialContext = (&net.Dialer{Timeout: cfg.dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+		t.TLSHandshakeTimeout = cfg.dialTimeout
+	}
+	t.ResponseHeaderTimeout = cfg.responseTimeout
+	if cfg.idleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.idleConnTimeout
+	}
+	if cfg.maxIdleConns > 0 {
+		t.MaxIdleConnsPerHost = cfg.maxIdleConns
+	}
+	t.DisableKeepAlives = cfg.disableKeepAlives
+	transports[cfg] = t
+	return t
+}
+
+// headerTransport adds headers to every request.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	for k, v := range t.headers {
+		r.Header[k] = v
+	}
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,43 @@
+package failover
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	limitersMu sync.Mutex
+	// limiters are shared by all clients of an endpoint, such as the helpers the daemon creates for every
+	// withdrawal, so that the budget holds across them. They are keyed by the endpoint url rather than its
+	// host, as endpoints on the same host may have different paths or API keys, and so budgets.
+	limiters = make(map[string]*rate.Limiter)
+)
+
+// limiter returns the limiter of requests to endpoint.
+func limiter(endpoint *url.URL, requestsPerSecond float64) *rate.Limiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	key := endpoint.String()
+	l, ok := limiters[key]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(requestsPerSecond), max(1, int(requestsPerSecond)))
+		limiters[key] = l
+	}
+	return l
+}
+
+// rateLimitTransport holds requests back to stay within a budget of requests per second per endpoint.
+type rateLimitTransport struct {
+	base              http.RoundTripper
+	requestsPerSecond float64
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := limiter(req.URL, t.requestsPerSecond).Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
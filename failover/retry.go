@@ -0,0 +1,74 @@
+package failover
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultRetryBackoff is the delay before the first retry if none is configured.
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// retryTransport retries requests that failed with a connection error, timeout, 429 or 5xx response,
+// backing off exponentially with jitter. Requests sending transactions are not retried, as the node may
+// have accepted them before failing.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	retries := t.retries
+	if bytes.Contains(body, []byte(`"eth_send`)) {
+		retries = 0
+	}
+	backoff := t.backoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req.Clone(req.Context())
+		r.Body, r.ContentLength = io.NopCloser(bytes.NewReader(body)), int64(len(body))
+		resp, err := t.base.RoundTrip(r)
+		var reason error
+		if err != nil {
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			reason = err
+		} else if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			reason = fmt.Errorf("%s", resp.Status)
+		}
+		if reason == nil || attempt >= retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		// full jitter over the upper half of the exponential delay
+		delay := backoff << attempt
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		log.Debug("Retrying RPC request", "host", req.URL.Host, "attempt", attempt+1, "delay", delay, "reason", reason)
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
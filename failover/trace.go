@@ -0,0 +1,100 @@
+package failover
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxTracedParams is the length the params of traced requests are cut to.
+const maxTracedParams = 512
+
+// traceTransport logs every JSON-RPC request sent to an endpoint, with its method, params, duration and
+// the error it failed with, if any. Only the host of the endpoint is logged, as the path often holds an
+// API key.
+type traceTransport struct {
+	base http.RoundTripper
+}
+
+// jsonrpcMessage is the part of JSON-RPC requests and responses that is traced.
+type jsonrpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseMessages parses a single JSON-RPC message or a batch of them.
+func parseMessages(body []byte) []jsonrpcMessage {
+	var msgs []jsonrpcMessage
+	if len(body) > 0 && body[0] == '[' {
+		_ = json.Unmarshal(body, &msgs)
+		return msgs
+	}
+	var msg jsonrpcMessage
+	if json.Unmarshal(body, &msg) == nil {
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	requests := parseMessages(bytes.TrimSpace(body))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+
+	rpcErrors := make(map[string]string)
+	status := ""
+	if err == nil {
+		status = resp.Status
+		data, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		for _, r := range parseMessages(bytes.TrimSpace(data)) {
+			if r.Error != nil {
+				rpcErrors[string(r.ID)] = r.Error.Message
+			}
+		}
+	}
+	for _, r := range requests {
+		params := string(r.Params)
+		if len(params) > maxTracedParams {
+			params = params[:maxTracedParams] + "..."
+		}
+		ctx := []any{"endpoint", req.URL.Host, "method", r.Method, "params", params, "duration", duration}
+		if len(requests) > 1 {
+			ctx = append(ctx, "batch", len(requests))
+		}
+		switch {
+		case err != nil:
+			ctx = append(ctx, "error", err)
+		case rpcErrors[string(r.ID)] != "":
+			ctx = append(ctx, "status", status, "error", rpcErrors[string(r.ID)])
+		default:
+			ctx = append(ctx, "status", status)
+		}
+		log.Info("RPC call", ctx...)
+	}
+	return resp, err
+}
@@ -0,0 +1,73 @@
+package failover
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// verifiedMethods are the JSON-RPC methods reading chain state, which a light client such as Helios serves
+// after verifying the provider's responses against the consensus layer.
+var verifiedMethods = map[string]bool{
+	"eth_blockNumber":           true,
+	"eth_call":                  true,
+	"eth_getBalance":            true,
+	"eth_getBlockByHash":        true,
+	"eth_getBlockByNumber":      true,
+	"eth_getCode":               true,
+	"eth_getLogs":               true,
+	"eth_getStorageAt":          true,
+	"eth_getTransactionReceipt": true,
+}
+
+// verifiedTransport sends requests made up of state reads only to a light client endpoint, and all others,
+// such as transactions and fee queries, to the next transport.
+type verifiedTransport struct {
+	endpoint *url.URL
+	verified http.RoundTripper
+	next     http.RoundTripper
+}
+
+func (t *verifiedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.next.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	r := req.Clone(req.Context())
+	r.Body, r.ContentLength = io.NopCloser(bytes.NewReader(body)), int64(len(body))
+	if !onlyVerifiedMethods(body) {
+		return t.next.RoundTrip(r)
+	}
+	u := *t.endpoint
+	r.URL, r.Host = &u, u.Host
+	return t.verified.RoundTrip(r)
+}
+
+// onlyVerifiedMethods returns whether the JSON-RPC request or batch body only calls verifiedMethods.
+func onlyVerifiedMethods(body []byte) bool {
+	var calls []struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(body, &calls); err != nil {
+		calls = calls[:0]
+		var call struct {
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(body, &call); err != nil {
+			return false
+		}
+		calls = append(calls, call)
+	}
+	for _, c := range calls {
+		if !verifiedMethods[c.Method] {
+			return false
+		}
+	}
+	return len(calls) > 0
+}
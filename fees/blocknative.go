@@ -0,0 +1,54 @@
+package fees
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+const blocknativeURL = "https://api.blocknative.com/gasprices/blockprices"
+
+// Blocknative fetches fee recommendations for the next block from the Blocknative gas price API.
+type Blocknative struct {
+	apiKey     string
+	chainID    *big.Int
+	confidence int
+	client     *http.Client
+}
+
+// NewBlocknative creates an oracle suggesting the fees at which a transaction on the given chain is
+// included in the next block with the given confidence (one of 70, 80, 90, 95, 99 percent).
+func NewBlocknative(apiKey string, chainID *big.Int, confidence int) *Blocknative {
+	return &Blocknative{apiKey: apiKey, chainID: chainID, confidence: confidence, client: http.DefaultClient}
+}
+
+func (o *Blocknative) SuggestFees(ctx context.Context) (Suggestion, error) {
+	var resp struct {
+		BlockPrices []struct {
+			EstimatedPrices []struct {
+				Confidence           int     `json:"confidence"`
+				MaxPriorityFeePerGas float64 `json:"maxPriorityFeePerGas"`
+				MaxFeePerGas         float64 `json:"maxFeePerGas"`
+			} `json:"estimatedPrices"`
+		} `json:"blockPrices"`
+	}
+	url := fmt.Sprintf("%s?chainid=%s", blocknativeURL, o.chainID)
+	err := getJSON(ctx, o.client, url, &resp, func(req *http.Request) {
+		if o.apiKey != "" {
+			req.Header.Set("Authorization", o.apiKey)
+		}
+	})
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("error querying Blocknative: %w", err)
+	}
+	if len(resp.BlockPrices) == 0 {
+		return Suggestion{}, fmt.Errorf("no block prices returned by Blocknative")
+	}
+	for _, p := range resp.BlockPrices[0].EstimatedPrices {
+		if p.Confidence == o.confidence {
+			return Suggestion{GasTipCap: gweiToWei(p.MaxPriorityFeePerGas), GasFeeCap: gweiToWei(p.MaxFeePerGas)}, nil
+		}
+	}
+	return Suggestion{}, fmt.Errorf("no Blocknative estimate with %d%% confidence", o.confidence)
+}
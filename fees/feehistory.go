@@ -0,0 +1,47 @@
+package fees
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Suggestion is a fee recommendation for EIP-1559 transactions.
+type Suggestion struct {
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// FeeHistory suggests the percentile (0-100) of the priority fees paid in each of the last blocks
+// L1 blocks, taking the median across blocks so a single outlier block doesn't skew it. The fee cap
+// leaves room for the base fee to double.
+func FeeHistory(ctx context.Context, client *ethclient.Client, blocks uint64, percentile float64) (Suggestion, error) {
+	history, err := client.FeeHistory(ctx, blocks, nil, []float64{percentile})
+	if err != nil {
+		return Suggestion{}, fmt.Errorf("error querying fee history: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return Suggestion{}, errors.New("empty fee history")
+	}
+
+	var tips []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 {
+			tips = append(tips, reward[0])
+		}
+	}
+	tip := new(big.Int)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		tip = tips[len(tips)/2]
+	}
+
+	// the last base fee is the one of the next block
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	feeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+	return Suggestion{GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
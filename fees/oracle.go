@@ -0,0 +1,82 @@
+package fees
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Oracle recommends fees for EIP-1559 transactions.
+type Oracle interface {
+	SuggestFees(ctx context.Context) (Suggestion, error)
+}
+
+// HistoryOracle suggests fees from the L1 fee history, see FeeHistory.
+type HistoryOracle struct {
+	Client     *ethclient.Client
+	Blocks     uint64
+	Percentile float64
+}
+
+func (o *HistoryOracle) SuggestFees(ctx context.Context) (Suggestion, error) {
+	return FeeHistory(ctx, o.Client, o.Blocks, o.Percentile)
+}
+
+// HTTPOracle fetches fees from a self-hosted endpoint, which responds to GET requests with a JSON
+// object holding the maxFeePerGas and maxPriorityFeePerGas in gwei, e.g.
+// {"maxFeePerGas": 30.5, "maxPriorityFeePerGas": 1.2}.
+type HTTPOracle struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPOracle(url string) *HTTPOracle {
+	return &HTTPOracle{url: url, client: http.DefaultClient}
+}
+
+func (o *HTTPOracle) SuggestFees(ctx context.Context) (Suggestion, error) {
+	var resp struct {
+		MaxFeePerGas         *float64 `json:"maxFeePerGas"`
+		MaxPriorityFeePerGas *float64 `json:"maxPriorityFeePerGas"`
+	}
+	if err := getJSON(ctx, o.client, o.url, &resp); err != nil {
+		return Suggestion{}, fmt.Errorf("error querying gas oracle: %w", err)
+	}
+	if resp.MaxFeePerGas == nil || resp.MaxPriorityFeePerGas == nil {
+		return Suggestion{}, fmt.Errorf("gas oracle response is missing maxFeePerGas or maxPriorityFeePerGas")
+	}
+	return Suggestion{GasTipCap: gweiToWei(*resp.MaxPriorityFeePerGas), GasFeeCap: gweiToWei(*resp.MaxFeePerGas)}, nil
+}
+
+// getJSON decodes the JSON response to a GET request to url into v, failing on non-2xx responses.
+// The request can be amended, e.g. with authorization headers, by the given functions.
+func getJSON(ctx context.Context, client *http.Client, url string, v any, amend ...func(*http.Request)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, f := range amend {
+		f(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// gweiToWei converts a (fractional) gwei amount, as returned by oracle APIs, to wei.
+func gweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei)).Int(nil)
+	return wei
+}
@@ -0,0 +1,657 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/term"
+
+	"github.com/base-org/withdrawer/fees"
+	"github.com/base-org/withdrawer/pkg/withdrawer"
+	"github.com/base-org/withdrawer/price"
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/store"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// flags holds the flags shared by the default withdraw flow and the subcommands.
+type flags struct {
+	rpc           string
+	network       string
+	l2RPC         string
+	l2ProofRPC    string
+	l2Strategy    string
+	rpcRetries    int
+	retryBackoff  time.Duration
+	rpcHeaders    headerFlag
+	proxy         string
+	rpcRateLimit  float64
+	dialTimeout   time.Duration
+	rpcTimeout    time.Duration
+	idleTimeout   time.Duration
+	maxIdleConns  int
+	l1VerifiedRPC string
+	verifyRPC     string
+	traceRPC      bool
+	faultProofs   bool
+	interop       bool
+	supervisorRPC string
+	rollupRPC     string
+	portalAddress string
+	l2OOAddress   string
+	dgfAddress    string
+	portalAdapter string
+	l1Explorer    string
+	l2Explorer    string
+	withdrawal    string
+	viemJSON      string
+	privateKey    string
+	acceptKeyRisk bool
+	ledger        bool
+	mnemonic      string
+	hdPath        string
+	journal       string
+	pollInterval  time.Duration
+	maxFee        string
+	maxTip        string
+	gasPrice      string
+	legacyTx      bool
+	gasLimit      uint64
+	nonce         string
+	txMgr         bool
+	resubmit      time.Duration
+	maxBaseFee    string
+	feePercentile float64
+	feeBlocks     uint64
+	gasOracle     string
+	oracleKey     string
+	confidence    int
+	gasBuffer     float64
+	usdPrice      string
+	usdPriceFeed  string
+	maxSpend      string
+	privateRPC    string
+	privateBlocks uint64
+	noWait        bool
+	confirmations uint64
+	confirmTag    string
+	l2BlockTag    string
+	reorgCheck    uint64
+	pendingTxs    string
+	// spendCap is shared by all helpers of a run, so it is only created once.
+	spendCap *withdraw.SpendCap
+}
+
+func registerFlags(fs *flag.FlagSet) *flags {
+	var networkKeys []string
+	for n := range withdrawer.Networks {
+		networkKeys = append(networkKeys, n)
+	}
+	sort.Strings(networkKeys)
+
+	f := &flags{}
+	registerLogFlags(fs)
+	fs.StringVar(&f.rpc, "rpc", "", "Ethereum L1 RPC url")
+	fs.StringVar(&f.l1VerifiedRPC, "l1-verified-rpc", "", "HTTP url of a light client, such as Helios, to verify L1 state reads through instead of trusting --rpc, which must be an HTTP url")
+	fs.StringVar(&f.verifyRPC, "verify-rpc", "", "Second L1 RPC url, from an independent provider, to cross-check outputs, dispute games and proven withdrawals against before proving or finalizing")
+	fs.StringVar(&f.network, "network", "base-mainnet", fmt.Sprintf("op-stack network to withdraw.go from (one of: %s)", strings.Join(networkKeys, ", ")))
+	fs.StringVar(&f.l2RPC, "l2-rpc", "", "Custom network L2 RPC url (comma-separated urls to fail over between)")
+	fs.StringVar(&f.l2ProofRPC, "l2-proof-rpc", "", "Comma-separated L2 RPC urls supporting eth_getProof to pin proof generation to (default: the L2 RPC urls that support it)")
+	fs.IntVar(&f.rpcRetries, "rpc-retries", 3, "Number of times to retry RPC requests failing with connection errors, timeouts, 429s or 5xx responses (0 to disable)")
+	fs.DurationVar(&f.retryBackoff, "rpc-retry-backoff", 500*time.Millisecond, "Delay before the first retry of a failed RPC request, doubling with every further retry (with jitter)")
+	fs.Float64Var(&f.rpcRateLimit, "rpc-rate-limit", 0, "Maximum number of requests per second to send to each RPC endpoint, e.g. to stay within the limits of public endpoints (0 for no limit)")
+	fs.DurationVar(&f.dialTimeout, "rpc-dial-timeout", 10*time.Second, "Timeout for connecting to an RPC endpoint, including the TLS or WebSocket handshake")
+	fs.DurationVar(&f.rpcTimeout, "rpc-timeout", time.Minute, "Timeout for the response to an HTTP RPC request, after which it is retried or sent to the next endpoint (0 for none)")
+	fs.DurationVar(&f.idleTimeout, "rpc-idle-timeout", 90*time.Second, "How long idle connections to RPC endpoints are kept open for reuse (0 to open a new connection for every request)")
+	fs.IntVar(&f.maxIdleConns, "rpc-max-idle-conns", 8, "Maximum number of idle connections kept open for reuse per RPC endpoint")
+	fs.Var(&f.rpcHeaders, "rpc-header", "HTTP header to send with every L1 and L2 RPC request, as 'Name: value' (repeatable), e.g. for authenticated RPC gateways")
+	fs.StringVar(&f.proxy, "proxy", "", "HTTP(S) or SOCKS5 proxy to connect through, e.g. socks5://127.0.0.1:1080 (default: the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables)")
+	fs.BoolVar(&f.traceRPC, "trace-rpc", false, "Log every HTTP RPC request with its endpoint, method, params, duration and error, e.g. to diagnose failing proofs")
+	fs.StringVar(&f.l2Strategy, "l2-rpc-strategy", "priority", "How to spread requests over several L2 RPC urls: priority (fail over in order) or round-robin")
+	fs.BoolVar(&f.faultProofs, "fault-proofs", false, "Use fault proofs")
+	fs.BoolVar(&f.interop, "interop", false, "Use interop super root withdrawal flow (implies --fault-proofs)")
+	fs.StringVar(&f.supervisorRPC, "supervisor-rpc", "", "op-supervisor RPC url (required for --interop)")
+	fs.StringVar(&f.rollupRPC, "rollup-rpc", "", "op-node rollup RPC url to check the output root proven against with optimism_outputAtBlock before proving")
+	fs.StringVar(&f.portalAddress, "portal-address", "", "Custom network OptimismPortal address")
+	fs.StringVar(&f.l2OOAddress, "l2oo-address", "", "Custom network L2OutputOracle address")
+	fs.StringVar(&f.dgfAddress, "dfg-address", "", "Custom network DisputeGameFactory address")
+	fs.StringVar(&f.portalAdapter, "portal-adapter", "", fmt.Sprintf("Portal adapter to use for custom networks (one of: %s)", strings.Join(withdraw.PortalAdapters(), ", ")))
+	fs.StringVar(&f.l1Explorer, "l1-explorer", "", "Base URL of the L1 block explorer transactions are linked to, e.g. https://etherscan.io (default: the one of the network)")
+	fs.StringVar(&f.l2Explorer, "l2-explorer", "", "Base URL of the L2 block explorer transactions are linked to, e.g. a Blockscout instance of a custom network (default: the one of the network)")
+	fs.StringVar(&f.withdrawal, "withdrawal", "", "TX hash of the L2 withdrawal transaction")
+	fs.StringVar(&f.viemJSON, "withdrawal-json", "", "File holding the withdrawal as JSON in the shape of viem's getWithdrawals, e.g. handed over by a viem frontend, instead of --withdrawal")
+	fs.StringVar(&f.privateKey, "private-key", "", "Private key to use for signing transactions")
+	fs.BoolVar(&f.acceptKeyRisk, "i-accept-key-risk", false, "Skip the confirmation asked before signing with --private-key on mainnet, e.g. in scripts")
+	fs.BoolVar(&f.ledger, "ledger", false, "Use ledger device for signing transactions")
+	fs.StringVar(&f.mnemonic, "mnemonic", "", "Mnemonic to use for signing transactions")
+	fs.StringVar(&f.hdPath, "hd-path", "m/44'/60'/0'/0/0", "Hierarchical deterministic derivation path for mnemonic or ledger")
+	fs.DurationVar(&f.pollInterval, "poll-interval", 5*time.Second, "Initial interval between checks for transaction confirmation, doubling up to 30s over HTTP")
+	fs.Uint64Var(&f.confirmations, "confirmations", 0, "Number of L1 blocks that must follow a transaction's block before it is considered confirmed, to guard against reorgs")
+	fs.Uint64Var(&f.reorgCheck, "reorg-check-blocks", 0, "Check that a confirmed transaction is still canonical this many L1 blocks later, resubmitting it if it was reorged out (0 to disable)")
+	fs.StringVar(&f.confirmTag, "confirm-tag", "", "Only consider a transaction confirmed once its block is at or below this L1 block tag (one of: safe, finalized)")
+	fs.StringVar(&f.l2BlockTag, "l2-block-tag", "", "Only prove a withdrawal once its L2 block is at or below this L2 block tag, so that it can't be reorged out (one of: safe, finalized)")
+	fs.StringVar(&f.maxFee, "max-fee-per-gas", "", "Maximum fee per gas, in gwei, of sent transactions (default: suggested by the L1 node)")
+	fs.StringVar(&f.maxTip, "max-priority-fee-per-gas", "", "Maximum priority fee per gas, in gwei, of sent transactions (default: suggested by the L1 node)")
+	fs.StringVar(&f.gasPrice, "gas-price", "", "Gas price, in gwei, of sent transactions (implies --legacy-tx)")
+	fs.BoolVar(&f.legacyTx, "legacy-tx", false, "Send legacy (type-0) transactions, for L1s that don't support EIP-1559 (default gas price: suggested by the L1 node)")
+	fs.Float64Var(&f.gasBuffer, "gas-buffer", 1, "Multiplier applied to the estimated gas limit of sent transactions (e.g. 1.2)")
+	fs.Uint64Var(&f.gasLimit, "gas-limit", 0, "Gas limit of sent transactions (default: estimated by the L1 node)")
+	fs.StringVar(&f.pendingTxs, "pending-txs", pendingWarn, "What to do if the signer has pending transactions: warn and send after them, wait for them to be mined, or replace the oldest one (which requires fees at least 10% higher)")
+	fs.StringVar(&f.nonce, "nonce", "", "Nonce of the sent transaction, e.g. to replace a stuck transaction (default: the pending nonce of the signer)")
+	fs.Float64Var(&f.feePercentile, "fee-percentile", 0, "Pay the median of this percentile (0-100) of the priority fees paid in the last --fee-history-blocks L1 blocks, instead of the node's suggestion (0 to disable)")
+	fs.Uint64Var(&f.feeBlocks, "fee-history-blocks", 20, "Number of recent L1 blocks --fee-percentile is computed over")
+	fs.StringVar(&f.gasOracle, "gas-oracle", "", "External oracle to take fees from instead of the node's suggestion: blocknative, or the URL of an endpoint returning {\"maxFeePerGas\": <gwei>, \"maxPriorityFeePerGas\": <gwei>}")
+	fs.StringVar(&f.oracleKey, "gas-oracle-key", "", "API key of the --gas-oracle")
+	fs.IntVar(&f.confidence, "gas-oracle-confidence", 90, "Confidence, in percent, of inclusion in the next block the blocknative --gas-oracle should price for (one of: 70, 80, 90, 95, 99)")
+	fs.StringVar(&f.maxBaseFee, "max-base-fee", "", "L1 base fee, in gwei, above which no transactions are sent (the daemon defers them until it drops)")
+	fs.BoolVar(&f.txMgr, "txmgr", false, "Send transactions with the OP Stack transaction manager, which resubmits them with bumped fees until they are mined")
+	fs.DurationVar(&f.resubmit, "resubmission-timeout", 48*time.Second, "Time to wait for a transaction sent with --txmgr to be mined before resubmitting it with bumped fees")
+	fs.StringVar(&f.privateRPC, "private-rpc", "", "URL of a private relay to send transactions to with eth_sendPrivateTransaction, keeping them out of the public mempool")
+	fs.Uint64Var(&f.privateBlocks, "private-fallback-blocks", 10, "Number of L1 blocks to wait for a transaction sent to --private-rpc to be included before sending it publicly")
+	fs.BoolVar(&f.noWait, "no-wait", false, "Exit right after sending a transaction, printing its hash as JSON, instead of waiting for it to be mined")
+	fs.StringVar(&f.maxSpend, "max-spend", "", "Maximum fees to pay across the run (e.g. 0.05ether or 500000gwei), after which no further transactions are sent (default: unlimited)")
+	fs.StringVar(&f.usdPrice, "usd-price", "", "Source of the ETH/USD price to also report fees in USD with: chainlink, coingecko, or the URL of an endpoint in the CoinGecko simple price format (disabled if empty)")
+	fs.StringVar(&f.usdPriceFeed, "usd-price-feed", "", "Address of the Chainlink ETH/USD feed on L1 (default: the official feed on Ethereum mainnet and Sepolia)")
+	fs.StringVar(&f.journal, "journal", defaultJournalPath(), "File recording sent transactions, so that interrupted runs resume waiting for them instead of sending them again")
+	return f
+}
+
+// defaultJournalPath returns the journal location in the user's cache directory, or in the working
+// directory if there is none.
+func defaultJournalPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "withdrawer-journal.json"
+	}
+	return filepath.Join(dir, "withdrawer", "journal.json")
+}
+
+// openJournal opens the journal of sent transactions.
+func (f *flags) openJournal() store.Journal {
+	if err := os.MkdirAll(filepath.Dir(f.journal), 0o700); err != nil {
+		log.Crit("Error creating journal directory", "error", err)
+	}
+	st, err := store.NewFileStore(f.journal)
+	if err != nil {
+		log.Crit("Error opening journal", "journal", f.journal, "error", err)
+	}
+	return store.Journal{Store: st}
+}
+
+// helperConfig returns the withdraw helper settings selected by the flags, recording sent
+// transactions in journal.
+func (f *flags) helperConfig(journal withdraw.Journal) helperConfig {
+	cfg := helperConfig{
+		journal:             journal,
+		pollInterval:        f.pollInterval,
+		gasFeeCap:           parseGwei("--max-fee-per-gas", f.maxFee),
+		gasTipCap:           parseGwei("--max-priority-fee-per-gas", f.maxTip),
+		gasPrice:            parseGwei("--gas-price", f.gasPrice),
+		legacyTx:            f.legacyTx || f.gasPrice != "",
+		gasLimit:            f.gasLimit,
+		txMgr:               f.txMgr,
+		resubmissionTimeout: f.resubmit,
+		maxBaseFee:          parseGwei("--max-base-fee", f.maxBaseFee),
+		newOracle:           f.newOracle(),
+		gasBuffer:           f.gasBuffer,
+		newPriceFeed:        f.newPriceFeed(),
+		spendCap:            f.sharedSpendCap(),
+		privateRPC:          f.privateRPC,
+		privateBlocks:       f.privateBlocks,
+		noWait:              f.noWait,
+		confirmations:       f.confirmations,
+		confirmTag:          parseBlockTag("--confirm-tag", f.confirmTag),
+		l2BlockTag:          parseBlockTag("--l2-block-tag", f.l2BlockTag),
+		reorgCheckBlocks:    f.reorgCheck,
+		pendingTxs:          f.pendingTxs,
+	}
+	if f.noWait {
+		// the sent transaction is printed as JSON, so stdout is kept free of progress messages
+		cfg.logger = withdraw.NewWriterLogger(os.Stderr)
+	}
+	if cfg.gasFeeCap != nil && cfg.gasTipCap != nil && cfg.gasTipCap.Cmp(cfg.gasFeeCap) > 0 {
+		log.Crit("--max-priority-fee-per-gas must not exceed --max-fee-per-gas")
+	}
+	if f.gasBuffer < 1 {
+		log.Crit("--gas-buffer must be at least 1")
+	}
+	if cfg.txMgr && (cfg.newOracle != nil || cfg.gasFeeCap != nil || cfg.gasTipCap != nil || cfg.legacyTx || f.nonce != "") {
+		log.Crit("--txmgr picks the fees and nonce itself and can't be used with --max-fee-per-gas, --max-priority-fee-per-gas, --fee-percentile, --gas-oracle, --gas-price, --legacy-tx or --nonce")
+	}
+	if cfg.txMgr && f.privateRPC != "" {
+		log.Crit("--txmgr sends transactions publicly and can't be used with --private-rpc")
+	}
+	switch f.pendingTxs {
+	case pendingWarn, pendingWait, pendingReplace:
+	default:
+		log.Crit("Invalid --pending-txs, expected warn, wait or replace", "value", f.pendingTxs)
+	}
+	if cfg.txMgr && f.pendingTxs == pendingReplace {
+		log.Crit("--pending-txs replace can't be used with --txmgr, which picks the nonce itself")
+	}
+	if cfg.txMgr && (cfg.confirmTag != 0 || cfg.reorgCheckBlocks != 0) {
+		log.Crit("--confirm-tag and --reorg-check-blocks aren't supported with --txmgr, which only counts --confirmations")
+	}
+	if f.noWait && (cfg.txMgr || f.privateRPC != "") {
+		log.Crit("--no-wait can't be used with --txmgr or --private-rpc, which need to watch sent transactions until they are mined")
+	}
+	if f.nonce != "" {
+		nonce, err := strconv.ParseUint(f.nonce, 10, 64)
+		if err != nil {
+			log.Crit("Invalid --nonce", "value", f.nonce)
+		}
+		cfg.nonce = &nonce
+	}
+	if cfg.legacyTx && (cfg.gasFeeCap != nil || cfg.gasTipCap != nil) {
+		log.Crit("--max-fee-per-gas and --max-priority-fee-per-gas can't be used with --gas-price or --legacy-tx")
+	}
+	return cfg
+}
+
+// newOracle returns the constructor of the fee oracle selected by the flags, or nil to use the node's
+// suggestions.
+func (f *flags) newOracle() func(context.Context, *ethclient.Client) (fees.Oracle, error) {
+	if f.feePercentile < 0 || f.feePercentile > 100 {
+		log.Crit("--fee-percentile must be between 0 and 100")
+	}
+	switch {
+	case f.feePercentile > 0 && f.gasOracle != "":
+		log.Crit("--fee-percentile and --gas-oracle can't be used together")
+	case f.feePercentile > 0:
+		return func(_ context.Context, client *ethclient.Client) (fees.Oracle, error) {
+			return &fees.HistoryOracle{Client: client, Blocks: f.feeBlocks, Percentile: f.feePercentile}, nil
+		}
+	case f.gasOracle == "blocknative":
+		return func(ctx context.Context, client *ethclient.Client) (fees.Oracle, error) {
+			chainID, err := client.ChainID(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("Error querying chain ID: %w", err)
+			}
+			return fees.NewBlocknative(f.oracleKey, chainID, f.confidence), nil
+		}
+	case strings.HasPrefix(f.gasOracle, "http://") || strings.HasPrefix(f.gasOracle, "https://"):
+		return func(context.Context, *ethclient.Client) (fees.Oracle, error) {
+			return fees.NewHTTPOracle(f.gasOracle), nil
+		}
+	case f.gasOracle != "":
+		log.Crit("Unknown --gas-oracle, expected blocknative or a URL", "value", f.gasOracle)
+	}
+	return nil
+}
+
+// newPriceFeed returns the constructor of the ETH/USD price feed selected by the flags, or nil if
+// fees are only reported in ETH.
+func (f *flags) newPriceFeed() func(context.Context, *ethclient.Client) (price.Feed, error) {
+	switch {
+	case f.usdPrice == "":
+		return nil
+	case f.usdPrice == "chainlink":
+		return func(ctx context.Context, client *ethclient.Client) (price.Feed, error) {
+			address := common.HexToAddress(f.usdPriceFeed)
+			if f.usdPriceFeed == "" {
+				chainID, err := client.ChainID(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("Error querying chain ID: %w", err)
+				}
+				var ok bool
+				if address, ok = price.ChainlinkFeeds[chainID.Uint64()]; !ok {
+					return nil, fmt.Errorf("No Chainlink ETH/USD feed known on chain %s, please provide --usd-price-feed", chainID)
+				}
+			}
+			return price.NewChainlink(address, client)
+		}
+	case f.usdPrice == "coingecko":
+		return func(context.Context, *ethclient.Client) (price.Feed, error) {
+			return price.NewAPI(price.CoinGeckoURL), nil
+		}
+	case strings.HasPrefix(f.usdPrice, "http://") || strings.HasPrefix(f.usdPrice, "https://"):
+		return func(context.Context, *ethclient.Client) (price.Feed, error) {
+			return price.NewAPI(f.usdPrice), nil
+		}
+	default:
+		log.Crit("Unknown --usd-price, expected chainlink, coingecko or a URL", "value", f.usdPrice)
+		return nil
+	}
+}
+
+// sharedSpendCap returns the spending cap of the run, or nil if there is none.
+func (f *flags) sharedSpendCap() *withdraw.SpendCap {
+	if f.maxSpend != "" && f.spendCap == nil {
+		f.spendCap = withdraw.NewSpendCap(parseAmount("--max-spend", f.maxSpend))
+	}
+	return f.spendCap
+}
+
+// parseAmount parses an ETH amount with an optional unit (ether, gwei or wei, ether if omitted) into wei.
+func parseAmount(flag, value string) *big.Int {
+	number, unit := value, int64(params.Ether)
+	for _, u := range []struct {
+		suffix string
+		wei    int64
+	}{{"ether", params.Ether}, {"gwei", params.GWei}, {"wei", params.Wei}} {
+		if strings.HasSuffix(value, u.suffix) {
+			number, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.wei
+			break
+		}
+	}
+	r, ok := new(big.Rat).SetString(number)
+	if !ok || r.Sign() < 0 {
+		log.Crit("Invalid amount", "flag", flag, "value", value)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if !r.IsInt() {
+		log.Crit("Amount is more precise than 1 wei", "flag", flag, "value", value)
+	}
+	return r.Num()
+}
+
+// headerFlag collects the HTTP headers passed with a repeatable flag.
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	var headers []string
+	for name, values := range h {
+		for _, v := range values {
+			headers = append(headers, name+": "+v)
+		}
+	}
+	return strings.Join(headers, ", ")
+}
+
+func (h *headerFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected 'Name: value', got %q", value)
+	}
+	if *h == nil {
+		*h = make(headerFlag)
+	}
+	http.Header(*h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
+
+// parseBlockTag parses a block tag flag, returning 0 if it is unset.
+func parseBlockTag(flag, value string) rpc.BlockNumber {
+	switch value {
+	case "":
+		return 0
+	case "safe":
+		return rpc.SafeBlockNumber
+	case "finalized":
+		return rpc.FinalizedBlockNumber
+	}
+	log.Crit("Invalid block tag, expected safe or finalized", "flag", flag, "value", value)
+	return 0
+}
+
+// parseGwei parses an amount in (possibly fractional) gwei into wei, returning nil for an empty value.
+func parseGwei(flag, value string) *big.Int {
+	if value == "" {
+		return nil
+	}
+	r, ok := new(big.Rat).SetString(value)
+	if !ok || r.Sign() < 0 {
+		log.Crit("Invalid gwei amount", "flag", flag, "value", value)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(params.GWei))
+	if !r.IsInt() {
+		log.Crit("Gwei amount is more precise than 1 wei", "flag", flag, "value", value)
+	}
+	return r.Num()
+}
+
+// resolveNetwork validates the network flags and returns the selected, possibly custom, network.
+func (f *flags) resolveNetwork() network {
+	known, ok := withdrawer.Networks[f.network]
+	if !ok {
+		log.Crit("Unknown network", "network", f.network)
+	}
+	n := network{Network: known}
+
+	if f.interop {
+		f.faultProofs = true
+		if f.supervisorRPC == "" {
+			log.Crit("Missing --supervisor-rpc flag")
+		}
+	}
+
+	// check for non-compatible networks with given flags
+	if f.faultProofs {
+		if n.FaultProofs == false {
+			log.Crit("Fault proofs are not supported on this network")
+		}
+	} else {
+		if n.FaultProofs == true {
+			log.Crit("Fault proofs are required on this network, please provide the --fault-proofs flag")
+		}
+	}
+
+	// check for non-empty flags for non-fault proof networks
+	if !f.faultProofs && (f.l2RPC != "" || f.portalAddress != "" || f.l2OOAddress != "") {
+		if f.l2RPC == "" {
+			log.Crit("Missing --l2-rpc flag")
+		}
+		if f.portalAddress == "" {
+			log.Crit("Missing --portal-address flag")
+		}
+		if f.l2OOAddress == "" {
+			log.Crit("Missing --l2oo-address flag")
+		}
+		n.Network = withdrawer.Network{
+			L2RPC:          f.l2RPC,
+			PortalAddress:  common.HexToAddress(f.portalAddress),
+			L2OutputOracle: common.HexToAddress(f.l2OOAddress),
+			FaultProofs:    f.faultProofs,
+		}
+		n.custom = true
+	}
+
+	// check for non-empty flags for fault proof networks
+	if f.faultProofs && (f.l2RPC != "" || f.dgfAddress != "" || f.portalAddress != "") {
+		if f.l2RPC == "" {
+			log.Crit("Missing --l2-rpc flag")
+		}
+		if f.dgfAddress == "" {
+			log.Crit("Missing --dfg-address flag")
+		}
+		if f.portalAddress == "" {
+			log.Crit("Missing --portal-address flag")
+		}
+		n.Network = withdrawer.Network{
+			L2RPC:              f.l2RPC,
+			PortalAddress:      common.HexToAddress(f.portalAddress),
+			DisputeGameFactory: common.HexToAddress(f.dgfAddress),
+			FaultProofs:        f.faultProofs,
+		}
+		n.custom = true
+	}
+
+	switch f.l2Strategy {
+	case "priority":
+	case "round-robin":
+		n.l2RoundRobin = true
+	default:
+		log.Crit("Invalid --l2-rpc-strategy, expected priority or round-robin", "value", f.l2Strategy)
+	}
+	n.l2ProofRPC = f.l2ProofRPC
+	n.rpcRetries, n.rpcRetryBackoff = f.rpcRetries, f.retryBackoff
+	n.rpcHeaders = http.Header(f.rpcHeaders)
+	n.rpcRateLimit = f.rpcRateLimit
+	n.rpcDialTimeout, n.rpcTimeout = f.dialTimeout, f.rpcTimeout
+	n.rpcIdleTimeout, n.rpcMaxIdleConns = f.idleTimeout, f.maxIdleConns
+	n.l1VerifiedRPC, n.verifyRPC = f.l1VerifiedRPC, f.verifyRPC
+	n.traceRPC = f.traceRPC
+	if f.proxy != "" {
+		u, err := url.Parse(f.proxy)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			log.Crit("Invalid --proxy, expected an http, https or socks5 url", "value", f.proxy)
+		}
+		// all HTTP clients, including the RPC clients, use the default transport
+		http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(u)
+	}
+
+	if f.interop {
+		n.Interop = true
+		n.SupervisorRPC = f.supervisorRPC
+	}
+	if f.rollupRPC != "" {
+		n.RollupRPC = f.rollupRPC
+	}
+	if f.portalAdapter != "" {
+		n.PortalAdapter = f.portalAdapter
+	}
+	if f.l1Explorer != "" {
+		n.L1Explorer = f.l1Explorer
+	}
+	if f.l2Explorer != "" {
+		n.L2Explorer = f.l2Explorer
+	}
+
+	if f.rpc == "" {
+		log.Crit("Missing --rpc flag")
+	}
+	return n
+}
+
+// withdrawalHash validates and returns the L2 withdrawal tx hash.
+func (f *flags) withdrawalHash() common.Hash {
+	if f.viemJSON != "" {
+		if f.withdrawal != "" {
+			log.Crit("Only one of --withdrawal and --withdrawal-json can be set")
+		}
+		return f.importWithdrawal()
+	}
+	if f.withdrawal == "" {
+		log.Crit("Missing --withdrawal flag")
+	}
+	return common.HexToHash(f.withdrawal)
+}
+
+// importWithdrawal returns the L2 transaction initiating the withdrawal in the --withdrawal-json file.
+func (f *flags) importWithdrawal() common.Hash {
+	data, err := os.ReadFile(f.viemJSON)
+	if err != nil {
+		log.Crit("Error reading --withdrawal-json", "error", err)
+	}
+	imported, err := withdraw.ParseViemWithdrawals(data)
+	if err != nil {
+		log.Crit("Invalid --withdrawal-json", "file", f.viemJSON, "error", err)
+	}
+	if len(imported) != 1 {
+		log.Crit("--withdrawal-json must hold a single withdrawal", "withdrawals", len(imported))
+	}
+
+	ctx := context.Background()
+	l2RPC, err := f.resolveNetwork().dialL2(ctx)
+	if err != nil {
+		log.Crit("Error dialing L2 client", "error", err)
+	}
+	defer l2RPC.Close()
+	l2TxHash, err := imported[0].FindL2Tx(ctx, ethclient.NewClient(l2RPC))
+	if err != nil {
+		log.Crit("Error finding the L2 transaction of the withdrawal", "withdrawal", imported[0].WithdrawalHash, "error", err)
+	}
+	return l2TxHash
+}
+
+// createSigner validates the signing flags and returns the selected signer.
+func (f *flags) createSigner() signer.Signer {
+	options := 0
+	if f.privateKey != "" {
+		options++
+	}
+	if f.ledger {
+		options++
+	}
+	if f.mnemonic != "" {
+		options++
+	}
+	if options != 1 {
+		log.Crit("One (and only one) of --private-key, --ledger, --mnemonic must be set")
+	}
+
+	s, err := signer.CreateSigner(f.privateKey, f.mnemonic, f.hdPath)
+	if err != nil {
+		log.Crit("Error creating signer", "error", err)
+	}
+	// custom networks keep the default mainnet --network, as their L1 isn't known yet
+	if f.privateKey != "" && strings.HasSuffix(f.network, "-mainnet") {
+		confirmRawKey(s.Address(), f.network, f.acceptKeyRisk)
+	}
+	return s
+}
+
+// confirmRawKey prints the address of the raw private key used on a mainnet network and, unless accepted
+// is set, asks the user to confirm using it, as a key passed on the command line is exposed in the shell
+// history and the process list.
+func confirmRawKey(address common.Address, network string, accepted bool) {
+	fmt.Fprintf(os.Stderr, "Signing with the --private-key of %s on %s\n", address.Hex(), network)
+	if accepted {
+		return
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Crit("Signing with --private-key on mainnet must be confirmed, pass --i-accept-key-risk to run non-interactively, or use --ledger")
+	}
+	fmt.Fprint(os.Stderr, "Private keys passed on the command line are exposed in the shell history and the process list, consider --ledger instead. Type yes to continue: ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "yes" {
+		log.Crit("Aborted, the private key was not confirmed")
+	}
+}
+
+// storeFlags holds the flags selecting the withdrawal state store used by the daemon and status commands.
+type storeFlags struct {
+	kind string
+	path string
+}
+
+// registerLogFlags registers --log-level, which replaces the default logger as soon as it is parsed. It
+// is registered once, for commands taking both the shared and the store flags.
+func registerLogFlags(fs *flag.FlagSet) {
+	if fs.Lookup("log-level") != nil {
+		return
+	}
+	fs.Func("log-level", "Log level: trace, debug, info, warn, error or crit (default: info)", func(s string) error {
+		level, err := oplog.LevelFromString(s)
+		if err != nil {
+			return err
+		}
+		cfg := oplog.DefaultCLIConfig()
+		cfg.Level = level
+		log.SetDefault(oplog.NewLogger(os.Stderr, cfg))
+		return nil
+	})
+}
+
+func registerStoreFlags(fs *flag.FlagSet) *storeFlags {
+	f := &storeFlags{}
+	registerLogFlags(fs)
+	fs.StringVar(&f.kind, "store", "file", "Backend to persist withdrawal state with (one of: file, sqlite, postgres)")
+	fs.StringVar(&f.path, "state", "withdrawer-state.json", "Path to the JSON file or SQLite database, or PostgreSQL connection string, the daemon persists withdrawal state to")
+	return f
+}
+
+func (f *storeFlags) open() store.Store {
+	st, err := store.Open(f.kind, f.path)
+	if err != nil {
+		log.Crit("Error opening state", "store", f.kind, "error", err)
+	}
+	return st
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runHistory implements the history subcommand, which lists all L1 transactions that proved
+// or finalized a withdrawal.
+func runHistory(args []string) {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	f := registerFlags(fs)
+	var fromBlock uint64
+	var blockRange uint64
+	var txs string
+	fs.Uint64Var(&fromBlock, "from-block", 0, "L1 block to start scanning from (default: first L1 block after the withdrawal was initiated)")
+	fs.Uint64Var(&blockRange, "block-range", 10000, "Maximum number of L1 blocks to query per eth_getLogs request")
+	fs.StringVar(&txs, "txs", "", "Comma-separated L1 transactions to also check for reverted prove or finalize attempts, in addition to those recorded in --journal")
+	_ = fs.Parse(args)
+
+	if blockRange == 0 {
+		log.Crit("--block-range must be at least 1")
+	}
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	ctx := context.Background()
+
+	l1Client, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	l2Client, err := n.dialL2(ctx)
+	if err != nil {
+		log.Crit("Error dialing L2 client", "error", err)
+	}
+
+	hash, err := withdraw.GetWithdrawalHash(ctx, l2Client, withdrawal)
+	if err != nil {
+		log.Crit("Error querying withdrawal hash", "error", err)
+	}
+
+	// nothing can touch the withdrawal on L1 before it was initiated on L2
+	if fromBlock == 0 {
+		l2 := ethclient.NewClient(l2Client)
+		receipt, err := l2.TransactionReceipt(ctx, withdrawal)
+		if err != nil {
+			log.Crit("Error querying withdrawal receipt", "error", err)
+		}
+		header, err := l2.HeaderByNumber(ctx, receipt.BlockNumber)
+		if err != nil {
+			log.Crit("Error querying withdrawal block", "error", err)
+		}
+		fromBlock, err = withdraw.BlockAtTime(ctx, l1Client, header.Time)
+		if err != nil {
+			log.Crit("Error finding L1 start block", "error", err)
+		}
+	}
+
+	toBlock, err := l1Client.BlockNumber(ctx)
+	if err != nil {
+		log.Crit("Error querying L1 head", "error", err)
+	}
+
+	// reverted attempts emit no events, so they are looked for among the transactions known to be sent
+	var attempts []common.Hash
+	journal := f.openJournal()
+	for _, action := range []string{"prove", "finalize"} {
+		tx, err := journal.PendingTx(withdrawal, action)
+		if err != nil {
+			log.Crit("Error reading journal", "error", err)
+		}
+		attempts = append(attempts, tx)
+	}
+	journal.Store.Close()
+	for _, tx := range strings.Split(txs, ",") {
+		if tx = strings.TrimSpace(tx); tx != "" {
+			attempts = append(attempts, common.HexToHash(tx))
+		}
+	}
+
+	entries, err := withdraw.WithdrawalHistory(ctx, l1Client, n.PortalAddress, hash, fromBlock, toBlock, blockRange, attempts)
+	if err != nil {
+		log.Crit("Error scanning withdrawal history", "error", err)
+	}
+
+	fmt.Printf("Withdrawal %s (hash %s), L1 blocks %d-%d:\n", withdrawal.String(), hash.String(), fromBlock, toBlock)
+	if len(entries) == 0 {
+		fmt.Println("No prove or finalize transactions found")
+		return
+	}
+	for _, e := range entries {
+		fmt.Printf("%-8s block %-10d tx %s from %s (%s)\n", e.Action, e.BlockNumber, e.TxHash.String(), e.Submitter.String(), e.Status())
+	}
+}
@@ -2,299 +2,693 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"math/big"
+	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 
-	"github.com/ethereum-optimism/optimism/op-node/bindings"
-	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
 	oplog "github.com/ethereum-optimism/optimism/op-service/log"
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	txmetrics "github.com/ethereum-optimism/optimism/op-service/txmgr/metrics"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/base-org/withdrawer/failover"
+	"github.com/base-org/withdrawer/fees"
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/pkg/withdrawer"
+	"github.com/base-org/withdrawer/price"
 	"github.com/base-org/withdrawer/signer"
 	"github.com/base-org/withdrawer/withdraw"
 )
 
+// network is the withdrawer.Network withdrawals are made from, along with the settings of the RPC clients.
 type network struct {
-	l2RPC              string
-	portalAddress      string
-	l2OOAddress        string
-	disputeGameFactory string
-	faultProofs        bool
+	withdrawer.Network
+	// custom is set for networks configured with --portal-address, whose portal is checked to belong
+	// to the L2 chain before use.
+	custom bool
+	// l2ProofRPC, if set, are the comma-separated L2 RPC urls eth_getProof requests are pinned to, and
+	// l2RoundRobin spreads requests over the comma-separated l2RPC urls instead of failing over in order.
+	l2ProofRPC   string
+	l2RoundRobin bool
+	// rpcRetries is the number of retries of RPC requests failing with transient errors, backing off
+	// from rpcRetryBackoff.
+	rpcRetries      int
+	rpcRetryBackoff time.Duration
+	// rpcHeaders are sent with every L1 and L2 RPC request.
+	rpcHeaders http.Header
+	// rpcRateLimit, if set, is the maximum number of requests per second sent to each RPC endpoint.
+	rpcRateLimit float64
+	// rpcDialTimeout and rpcTimeout limit connecting to RPC endpoints and waiting for their responses.
+	rpcDialTimeout time.Duration
+	rpcTimeout     time.Duration
+	// rpcIdleTimeout is how long idle connections to RPC endpoints are kept for reuse (0 for none), and
+	// rpcMaxIdleConns how many are kept per endpoint.
+	rpcIdleTimeout  time.Duration
+	rpcMaxIdleConns int
+	// l1VerifiedRPC, if set, is the url of a light client that L1 state reads are verified through.
+	l1VerifiedRPC string
+	// verifyRPC, if set, is the url of a second L1 provider that the data transactions rely on is
+	// cross-checked against.
+	verifyRPC string
+	// traceRPC logs every RPC request.
+	traceRPC bool
 }
 
-var networks = map[string]network{
-	"base-mainnet": {
-		l2RPC:              "https://mainnet.base.org",
-		portalAddress:      "0x49048044D57e1C92A77f79988d21Fa8fAF74E97e",
-		l2OOAddress:        "0x0000000000000000000000000000000000000000",
-		disputeGameFactory: "0x43edB88C4B80fDD2AdFF2412A7BebF9dF42cB40e",
-		faultProofs:        true,
-	},
-	"base-sepolia": {
-		l2RPC:              "https://sepolia.base.org",
-		portalAddress:      "0x49f53e41452C74589E85cA1677426Ba426459e85",
-		l2OOAddress:        "0x0000000000000000000000000000000000000000",
-		disputeGameFactory: "0xd6E6dBf4F7EA0ac412fD8b65ED297e64BB7a06E1",
-		faultProofs:        true,
-	},
-	"op-mainnet": {
-		l2RPC:              "https://mainnet.optimism.io",
-		portalAddress:      "0xbEb5Fc579115071764c7423A4f12eDde41f106Ed",
-		l2OOAddress:        "0x0000000000000000000000000000000000000000",
-		disputeGameFactory: "0xe5965Ab5962eDc7477C8520243A95517CD252fA9",
-		faultProofs:        true,
-	},
-	"op-sepolia": {
-		l2RPC:              "https://sepolia.optimism.io",
-		portalAddress:      "0x16Fc5058F25648194471939df75CF27A2fdC48BC",
-		l2OOAddress:        "0x0000000000000000000000000000000000000000",
-		disputeGameFactory: "0x05F9613aDB30026FFd634f38e5C4dFd30a197Fa1",
-		faultProofs:        true,
-	},
+// rpcOptions returns the failover options shared by the L1 and L2 RPC clients.
+func (n network) rpcOptions() failover.Options {
+	return failover.Options{
+		Retries:           n.rpcRetries,
+		RetryBackoff:      n.rpcRetryBackoff,
+		Headers:           n.rpcHeaders,
+		RateLimit:         n.rpcRateLimit,
+		DialTimeout:       n.rpcDialTimeout,
+		ResponseTimeout:   n.rpcTimeout,
+		IdleConnTimeout:   n.rpcIdleTimeout,
+		MaxIdleConns:      n.rpcMaxIdleConns,
+		DisableKeepAlives: n.rpcIdleTimeout == 0,
+		Trace:             n.traceRPC,
+	}
 }
 
-func main() {
-	var networkKeys []string
-	for n := range networks {
-		networkKeys = append(networkKeys, n)
-	}
-
-	var rpcFlag string
-	var networkFlag string
-	var l2RpcFlag string
-	var faultProofs bool
-	var portalAddress string
-	var l2OOAddress string
-	var dgfAddress string
-	var withdrawalFlag string
-	var privateKey string
-	var ledger bool
-	var mnemonic string
-	var hdPath string
-
-	flag.StringVar(&rpcFlag, "rpc", "", "Ethereum L1 RPC url")
-	flag.StringVar(&networkFlag, "network", "base-mainnet", fmt.Sprintf("op-stack network to withdraw.go from (one of: %s)", strings.Join(networkKeys, ", ")))
-	flag.StringVar(&l2RpcFlag, "l2-rpc", "", "Custom network L2 RPC url")
-	flag.BoolVar(&faultProofs, "fault-proofs", false, "Use fault proofs")
-	flag.StringVar(&portalAddress, "portal-address", "", "Custom network OptimismPortal address")
-	flag.StringVar(&l2OOAddress, "l2oo-address", "", "Custom network L2OutputOracle address")
-	flag.StringVar(&dgfAddress, "dfg-address", "", "Custom network DisputeGameFactory address")
-	flag.StringVar(&withdrawalFlag, "withdrawal", "", "TX hash of the L2 withdrawal transaction")
-	flag.StringVar(&privateKey, "private-key", "", "Private key to use for signing transactions")
-	flag.BoolVar(&ledger, "ledger", false, "Use ledger device for signing transactions")
-	flag.StringVar(&mnemonic, "mnemonic", "", "Mnemonic to use for signing transactions")
-	flag.StringVar(&hdPath, "hd-path", "m/44'/60'/0'/0/0", "Hierarchical deterministic derivation path for mnemonic or ledger")
-	flag.Parse()
+// dialL1 dials the L1 RPC endpoint url.
+func (n network) dialL1(ctx context.Context, url string) (*ethclient.Client, error) {
+	opts := n.rpcOptions()
+	opts.VerifiedURL = n.l1VerifiedRPC
+	client, err := failover.Dial(ctx, url, opts)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
 
-	log.SetDefault(oplog.NewLogger(os.Stderr, oplog.DefaultCLIConfig()))
+// dialL2 dials the L2 RPC endpoints of the network.
+func (n network) dialL2(ctx context.Context) (*rpc.Client, error) {
+	opts := n.rpcOptions()
+	opts.ProofURLs, opts.RoundRobin = n.l2ProofRPC, n.l2RoundRobin
+	return failover.Dial(ctx, n.L2RPC, opts)
+}
 
-	n, ok := networks[networkFlag]
-	if !ok {
-		log.Crit("Unknown network", "network", networkFlag)
-	}
+// explorers returns the block explorers transactions of the network are linked to.
+func (n network) explorers() notify.Explorers {
+	return notify.Explorers{L1: n.L1Explorer, L2: n.L2Explorer}
+}
 
-	// check for non-compatible networks with given flags
-	if faultProofs {
-		if n.faultProofs == false {
-			log.Crit("Fault proofs are not supported on this network")
-		}
-	} else {
-		if n.faultProofs == true {
-			log.Crit("Fault proofs are required on this network, please provide the --fault-proofs flag")
-		}
-	}
+// commands maps subcommand names to their entrypoints; without a subcommand the default
+// withdraw flow (prove or finalize, whichever is next) is run.
+var commands = map[string]func(args []string){
+	"prove":         runProve,
+	"history":       runHistory,
+	"daemon":        runDaemon,
+	"status":        runStatus,
+	"schema":        runSchema,
+	"accounting":    runAccounting,
+	"relay":         runRelay,
+	"step":          runStep,
+	"batch":         runBatch,
+	"speed-up":      runSpeedUp,
+	"cancel":        runCancel,
+	"tui":           runTUI,
+	"wizard":        runWizard,
+	"devnet":        runDevnet,
+	"self-update":   runSelfUpdate,
+	"version":       runVersion,
+	"bridge-status": runBridgeStatus,
+	"export-viem":   runExportViem,
+	"stats":         runStats,
+	"activity":      runActivity,
+	"check-token":   runCheckToken,
+	"report":        runReport,
+}
 
-	// check for non-empty flags for non-fault proof networks
-	if !faultProofs && (l2RpcFlag != "" || portalAddress != "" || l2OOAddress != "") {
-		if l2RpcFlag == "" {
-			log.Crit("Missing --l2-rpc flag")
-		}
-		if portalAddress == "" {
-			log.Crit("Missing --portal-address flag")
-		}
-		if l2OOAddress == "" {
-			log.Crit("Missing --l2oo-address flag")
-		}
-		n = network{
-			l2RPC:         l2RpcFlag,
-			portalAddress: portalAddress,
-			l2OOAddress:   l2OOAddress,
-			faultProofs:   faultProofs,
+// interruptContext returns a context that is canceled on SIGINT or SIGTERM, so that in-flight
+// transactions are recorded before exiting. A second signal exits immediately.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		log.Warn("Interrupted, shutting down (interrupt again to force)")
+		cancel()
+		<-sigs
+		os.Exit(130)
+	}()
+	return ctx
+}
+
+// exitCodes are the exit codes of the errors of the withdraw package, so that scripts can tell why a run
+// failed, e.g. to retry later if the withdrawal isn't provable yet.
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{withdraw.ErrNotProvableYet, 3},
+	{withdraw.ErrChallengePeriodActive, 4},
+	{withdraw.ErrGameNotResolved, 4},
+	{withdraw.ErrGameInvalidated, 5},
+	{withdraw.ErrNotProven, 6},
+	{withdraw.ErrAlreadyFinalized, 7},
+	{withdraw.ErrWithdrawalFailed, 8},
+	{withdraw.ErrDeliveryFailed, 9},
+	{withdraw.ErrNotWithdrawal, 10},
+}
+
+// exitWithError logs msg and err, and exits with the exit code of err.
+func exitWithError(msg string, err error) {
+	log.Error(msg, "error", err)
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the exit code of err, or 1 for other errors.
+func exitCode(err error) int {
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
 		}
 	}
+	return 1
+}
 
-	// check for non-empty flags for fault proof networks
-	if faultProofs && (l2RpcFlag != "" || dgfAddress != "" || portalAddress != "") {
-		if l2RpcFlag == "" {
-			log.Crit("Missing --l2-rpc flag")
-		}
-		if dgfAddress == "" {
-			log.Crit("Missing --dfg-address flag")
-		}
-		if portalAddress == "" {
-			log.Crit("Missing --portal-address flag")
-		}
-		n = network{
-			l2RPC:              l2RpcFlag,
-			portalAddress:      portalAddress,
-			disputeGameFactory: dgfAddress,
-			faultProofs:        faultProofs,
+func main() {
+	log.SetDefault(oplog.NewLogger(os.Stderr, oplog.DefaultCLIConfig()))
+
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			cmd(args[1:])
+			return
 		}
 	}
+	runWithdraw(args)
+}
 
-	if rpcFlag == "" {
-		log.Crit("Missing --rpc flag")
+func runWithdraw(args []string) {
+	fs := flag.NewFlagSet("withdrawer", flag.ExitOnError)
+	f := registerFlags(fs)
+	var printCast, rehearse bool
+	var from, anvilURL string
+	fs.BoolVar(&printCast, "print-cast", false, "Print the cast commands decoding, simulating and sending the next prove or finalize transaction instead of sending it")
+	fs.BoolVar(&rehearse, "rehearse", false, "Rehearse proving and finalizing the withdrawal on an Anvil fork of L1, impersonating the signer and fast-forwarding past the challenge window, instead of sending anything")
+	fs.StringVar(&anvilURL, "anvil-url", "", "URL of a running Anvil fork of L1 to rehearse against (default: start one with anvil --fork-url on --rpc)")
+	fs.StringVar(&from, "from", "", "Address the transaction printed with --print-cast, or rehearsed with --rehearse, is sent from (default: the signer's address)")
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	faultProofs := n.FaultProofs
+
+	if printCast && rehearse {
+		log.Crit("--print-cast and --rehearse can't be combined")
 	}
 
-	if withdrawalFlag == "" {
-		log.Crit("Missing --withdrawal flag")
+	// instantiate shared variables
+	var s signer.Signer
+	if !(printCast || rehearse) || from == "" {
+		s = f.createSigner()
 	}
-	withdrawal := common.HexToHash(withdrawalFlag)
 
-	options := 0
-	if privateKey != "" {
-		options++
-	}
-	if ledger {
-		options++
-	}
-	if mnemonic != "" {
-		options++
-	}
-	if options != 1 {
-		log.Crit("One (and only one) of --private-key, --ledger, --mnemonic must be set")
+	if rehearse {
+		address := common.HexToAddress(from)
+		if from == "" {
+			address = s.Address()
+		} else if !common.IsHexAddress(from) {
+			log.Crit("Invalid --from address", "address", from)
+		}
+		runRehearsal(interruptContext(), f, n, withdrawal, address, anvilURL)
+		return
 	}
 
-	// instantiate shared variables
-	s, err := signer.CreateSigner(privateKey, mnemonic, hdPath)
-	if err != nil {
-		log.Crit("Error creating signer", "error", err)
+	journal := f.openJournal()
+	defer journal.Store.Close()
+
+	hc := f.helperConfig(journal)
+	if printCast {
+		if from != "" {
+			if !common.IsHexAddress(from) {
+				log.Crit("Invalid --from address", "address", from)
+			}
+			hc.from = common.HexToAddress(from)
+		} else {
+			hc.from = s.Address()
+		}
+		hc.buildOnly = printCastCommands(hc.from)
+		// the commands are printed to stdout, so it is kept free of progress messages
+		hc.logger = withdraw.NewWriterLogger(os.Stderr)
 	}
 
-	withdrawer, err := CreateWithdrawHelper(rpcFlag, withdrawal, n, s)
+	ctx := interruptContext()
+	withdrawer, err := CreateWithdrawHelper(ctx, f.rpc, withdrawal, n, s, hc)
 	if err != nil {
-		log.Crit("Error creating withdrawer", "error", err)
+		exitWithError("Error creating withdrawer", err)
 	}
 
+	explorers := n.explorers()
+	summary := runSummary{withdrawal: withdrawal}
+
 	// handle withdrawals with or without the fault proofs withdrawer
-	isFinalized, err := withdrawer.IsProofFinalized()
+	isFinalized, err := withdrawer.IsProofFinalized(ctx)
 	if err != nil {
-		log.Crit("Error querying withdrawal finalization status", "error", err)
+		exitWithError("Error querying withdrawal finalization status", err)
 	}
 	if isFinalized {
-		fmt.Println("Withdrawal already finalized")
+		summary.result, summary.next = "Already finalized", "Nothing, the withdrawal is complete"
+		summary.print(explorers)
 		return
 	}
 
 	// TODO: Add functionality to generate output root proposal and prove to that proposal for FPs
-	err = withdrawer.CheckIfProvable()
+	err = withdrawer.CheckIfProvable(ctx)
 	if err != nil {
-		log.Crit("Withdrawal is not provable", "error", err)
+		if errors.Is(err, withdraw.ErrNotProvableYet) {
+			summary.result = "Not provable yet"
+			summary.next = rerunAfter(ctx, withdrawer, "Re-run", "prove", "Re-run once the next output or dispute game covering the withdrawal is proposed")
+			summary.print(explorers)
+		}
+		exitWithError("Withdrawal is not provable", err)
 	}
 
-	proofTime, err := withdrawer.GetProvenWithdrawalTime()
+	proofTime, err := withdrawer.GetProvenWithdrawalTime(ctx)
 	if err != nil {
-		log.Crit("Error querying withdrawal proof", "error", err)
+		exitWithError("Error querying withdrawal proof", err)
 	}
 
 	if proofTime == 0 {
-		err = withdrawer.ProveWithdrawal()
+		tx, err := withdrawer.ProveWithdrawal(ctx)
 		if err != nil {
-			log.Crit("Error proving withdrawal", "error", err)
+			exitWithError("Error proving withdrawal", err)
+		}
+		if printCast {
+			return
+		}
+		if f.noWait {
+			printSentTx(explorers, withdrawal, "prove", tx)
+			return
 		}
 
+		fallback := "Re-run to finalize once the finalization period has elapsed"
 		if faultProofs {
-			fmt.Println("The withdrawal has been successfully proven, finalization of the withdrawal can be done once the dispute game has finished and the finalization period has elapsed")
-		} else {
-			fmt.Println("The withdrawal has been successfully proven, finalization of the withdrawal can be done once the finalization period has elapsed")
+			fallback = "Re-run to finalize once the dispute game has resolved and the finalization period has elapsed"
 		}
+		summary.result, summary.proveTx = "Proven", tx
+		summary.next = rerunAfter(ctx, withdrawer, "Re-run", "finalize", fallback)
+		summary.print(explorers)
 		return
 	}
 
 	// TODO: Add edge-case handling for FPs if a withdrawal needs to be re-proven due to blacklisted / failed dispute game resolution
-	err = withdrawer.FinalizeWithdrawal()
+	tx, err := withdrawer.FinalizeWithdrawal(ctx)
 	if err != nil {
-		log.Crit("Error completing withdrawal", "error", err)
+		if errors.Is(err, withdraw.ErrChallengePeriodActive) || errors.Is(err, withdraw.ErrGameNotResolved) {
+			summary.result = "Proven, not finalizable yet"
+			summary.next = rerunAfter(ctx, withdrawer, "Re-run", "finalize", "Re-run to finalize once the finalization period has elapsed")
+			summary.print(explorers)
+		}
+		exitWithError("Error completing withdrawal", err)
 	}
+	if printCast {
+		return
+	}
+	if f.noWait {
+		printSentTx(explorers, withdrawal, "finalize", tx)
+		return
+	}
+	summary.result, summary.finalizeTx = "Finalized", tx
+	summary.next = "Nothing, the withdrawal is complete"
+	summary.print(explorers)
 }
 
-func CreateWithdrawHelper(l1Rpc string, withdrawal common.Hash, n network, s signer.Signer) (withdraw.WithdrawHelper, error) {
-	ctx := context.Background()
+// sentTx is the JSON printed for a transaction sent with --no-wait, described by the "sent-tx" output schema.
+type sentTx struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Withdrawal    common.Hash `json:"withdrawal"`
+	Action        string      `json:"action"`
+	Tx            common.Hash `json:"tx"`
+	// TxURL links Tx on the L1 block explorer of the network, if it has one.
+	TxURL string `json:"txUrl,omitempty"`
+}
 
-	l1Client, err := ethclient.DialContext(ctx, l1Rpc)
-	if err != nil {
-		return nil, fmt.Errorf("Error dialing L1 client: %w", err)
+// printSentTx prints the transaction sent for action as JSON, as the last line of output.
+func printSentTx(explorers notify.Explorers, withdrawal common.Hash, action string, tx common.Hash) {
+	printJSON(sentTx{SchemaVersion: schemaVersion, Withdrawal: withdrawal, Action: action, Tx: tx, TxURL: explorers.L1TxURL(tx)})
+}
+
+// helperConfig holds the settings of a withdraw helper that don't depend on the network.
+type helperConfig struct {
+	journal      withdraw.Journal
+	pollInterval time.Duration
+	// gasFeeCap and gasTipCap cap the EIP-1559 fees of sent transactions, if set.
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+	// legacyTx sends legacy (type-0) transactions paying gasPrice, or the node's suggested gas
+	// price if it is unset.
+	legacyTx bool
+	gasPrice *big.Int
+	// gasLimit overrides the estimated gas limit of sent transactions, if non-zero.
+	gasLimit uint64
+	// nonce overrides the pending nonce of the signer, if set, replacing any pending transaction
+	// with that nonce.
+	nonce *uint64
+	// txMgr sends transactions with the op-service tx manager, resubmitting them with bumped fees if
+	// they aren't mined within resubmissionTimeout.
+	txMgr               bool
+	resubmissionTimeout time.Duration
+	// maxBaseFee is the L1 base fee above which no transactions are sent, if set.
+	maxBaseFee *big.Int
+	// newOracle, if set, creates the oracle suggesting fees instead of the node, respecting gasFeeCap
+	// and gasTipCap.
+	newOracle func(ctx context.Context, client *ethclient.Client) (fees.Oracle, error)
+	// gasBuffer multiplies the gas estimates of sent transactions, if above 1.
+	gasBuffer float64
+	// newPriceFeed, if set, creates the ETH/USD price feed fees are also reported in.
+	newPriceFeed func(ctx context.Context, client *ethclient.Client) (price.Feed, error)
+	// spendCap, if set, caps the fees paid across the run.
+	spendCap *withdraw.SpendCap
+	// privateRPC, if set, is the URL of the private relay transactions are sent to, falling back to
+	// sending them publicly if they aren't included within privateBlocks blocks.
+	privateRPC    string
+	privateBlocks uint64
+	// noWait returns right after sending transactions, without waiting for them to be mined.
+	noWait bool
+	// confirmations is the number of blocks that must follow a transaction's block before it is
+	// considered confirmed.
+	confirmations uint64
+	// confirmTag, if set, requires the block of a transaction to be at or below the block with
+	// this tag before it is considered confirmed.
+	confirmTag rpc.BlockNumber
+	// l2BlockTag, if set, requires the L2 block of a withdrawal to be at or below the L2 block with
+	// this tag before it is proven.
+	l2BlockTag rpc.BlockNumber
+	// reorgCheckBlocks, if set, is the number of blocks after which confirmed transactions are checked
+	// to still be canonical.
+	reorgCheckBlocks uint64
+	// pendingTxs is the policy for pending transactions of the signer: warn, wait or replace.
+	pendingTxs string
+	// nonces, if set, coordinates the nonces of helpers sending transactions concurrently, instead of
+	// each using the pending nonce at creation.
+	nonces *withdraw.NonceManager
+	// logger, if set, receives the progress messages of the helper instead of stdout.
+	logger withdraw.Logger
+	// buildOnly, if set, is passed the transactions built by the helper, which are then not sent. Their
+	// gas is estimated from the from address, as no signer is needed. Without a signer, from is also
+	// the submitter whose fault proofs are queried.
+	buildOnly func(l2TxHash common.Hash, action string, tx *types.Transaction)
+	from      common.Address
+}
+
+// replaceJournal records sent transactions in a journal but ignores previously recorded ones, so that
+// a transaction sent with an explicit nonce replaces a pending one instead of being waited for.
+type replaceJournal struct {
+	withdraw.Journal
+}
+
+func (replaceJournal) PendingTx(common.Hash, string) (common.Hash, error) {
+	return common.Hash{}, nil
+}
+
+// applyTo sets the transaction overrides of c on opts.
+func (c helperConfig) applyTo(ctx context.Context, client *ethclient.Client, opts *bind.TransactOpts) error {
+	opts.GasFeeCap = c.gasFeeCap
+	opts.GasTipCap = c.gasTipCap
+	opts.GasPrice = c.gasPrice
+	opts.GasLimit = c.gasLimit
+	if c.newOracle != nil && !c.legacyTx {
+		oracle, err := c.newOracle(ctx, client)
+		if err != nil {
+			return err
+		}
+		suggestion, err := oracle.SuggestFees(ctx)
+		if err != nil {
+			return err
+		}
+		opts.GasTipCap = capFee(suggestion.GasTipCap, c.gasTipCap)
+		opts.GasFeeCap = capFee(suggestion.GasFeeCap, c.gasFeeCap)
+		if opts.GasTipCap.Cmp(opts.GasFeeCap) > 0 {
+			opts.GasTipCap = opts.GasFeeCap
+		}
+	}
+	// bind only sends legacy transactions if the gas price is set
+	if c.legacyTx && opts.GasPrice == nil {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("Error querying gas price: %w", err)
+		}
+		opts.GasPrice = gasPrice
 	}
+	return nil
+}
 
-	l1ChainID, err := l1Client.ChainID(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("Error querying chain ID: %w", err)
+// capFee returns the suggested fee, lowered to the cap if there is one.
+func capFee(suggested, limit *big.Int) *big.Int {
+	if limit != nil && suggested.Cmp(limit) > 0 {
+		return limit
 	}
+	return suggested
+}
 
-	l1Nonce, err := l1Client.PendingNonceAt(ctx, s.Address())
+func CreateWithdrawHelper(ctx context.Context, l1Rpc string, withdrawal common.Hash, n network, s signer.Signer, cfg helperConfig) (withdraw.WithdrawHelper, error) {
+	clients, err := dialHelperClients(ctx, l1Rpc, n, s, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("Error querying nonce: %w", err)
+		return nil, err
 	}
+	return newWithdrawHelper(ctx, clients, withdrawal, n, s, cfg)
+}
 
-	l1opts := &bind.TransactOpts{
-		From:    s.Address(),
-		Signer:  s.SignerFn(l1ChainID),
-		Context: ctx,
-		Nonce:   big.NewInt(int64(l1Nonce)),
+// helperClients holds the clients of the withdraw helpers of a network, which helpers created for many
+// withdrawals, such as by the daemon, share instead of each dialing their own.
+type helperClients struct {
+	l1         *ethclient.Client
+	l1ChainID  *big.Int
+	l2         *rpc.Client
+	supervisor withdraw.SupervisorClient
+	// privateRelay is the client of cfg.privateRPC, if set.
+	privateRelay *rpc.Client
+	// txMgr is the tx manager sending the transactions of the signer, if cfg.txMgr is set.
+	txMgr txmgr.TxManager
+	extra []withdraw.Option
+	// conns are the dialed clients, closed by Close.
+	conns []interface{ Close() }
+}
+
+// Close closes the clients.
+func (c *helperClients) Close() {
+	for _, conn := range c.conns {
+		conn.Close()
 	}
+}
 
-	l2Client, err := rpc.DialContext(ctx, n.l2RPC)
-	if err != nil {
+// dialHelperClients dials the clients of the withdraw helpers of n, signing with s, and checks the
+// contracts of custom networks.
+func dialHelperClients(ctx context.Context, l1Rpc string, n network, s signer.Signer, cfg helperConfig) (_ *helperClients, err error) {
+	c := &helperClients{}
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+
+	if c.l1, err = n.dialL1(ctx, l1Rpc); err != nil {
+		return nil, fmt.Errorf("Error dialing L1 client: %w", err)
+	}
+	c.conns = append(c.conns, c.l1)
+	if c.l1ChainID, err = c.l1.ChainID(ctx); err != nil {
+		return nil, fmt.Errorf("Error querying chain ID: %w", err)
+	}
+	if cfg.txMgr && s != nil && cfg.buildOnly == nil {
+		if c.txMgr, err = newTxManager(c.l1, c.l1ChainID, s, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.privateRPC != "" {
+		if c.privateRelay, err = failover.Dial(ctx, cfg.privateRPC, failover.Options{}); err != nil {
+			return nil, fmt.Errorf("Error dialing private relay: %w", err)
+		}
+		c.conns = append(c.conns, c.privateRelay)
+	}
+
+	if c.l2, err = n.dialL2(ctx); err != nil {
 		return nil, fmt.Errorf("Error dialing L2 client: %w", err)
 	}
+	c.conns = append(c.conns, c.l2)
+	if n.custom {
+		if err := checkCustomNetwork(ctx, c.l1, withdraw.NewL2Client(c.l2), n); err != nil {
+			return nil, err
+		}
+	}
 
-	if n.faultProofs {
-		portal, err := bindingspreview.NewOptimismPortal2(common.HexToAddress(n.portalAddress), l1Client)
+	if n.Interop {
+		supervisor, err := failover.Dial(ctx, n.SupervisorRPC, failover.Options{})
 		if err != nil {
-			return nil, fmt.Errorf("Error binding OptimismPortal2 contract: %w", err)
+			return nil, fmt.Errorf("Error dialing supervisor client: %w", err)
 		}
+		c.supervisor = supervisor
+		c.conns = append(c.conns, supervisor)
+	}
 
-		dgf, err := bindings.NewDisputeGameFactory(common.HexToAddress(n.disputeGameFactory), l1Client)
+	if n.verifyRPC != "" {
+		// the verification provider is dialed without --l1-verified-rpc, to stay independent of --rpc
+		verifyClient, err := failover.Dial(ctx, n.verifyRPC, n.rpcOptions())
 		if err != nil {
-			return nil, fmt.Errorf("Error binding DisputeGameFactory contract: %w", err)
+			return nil, fmt.Errorf("Error dialing verification L1 client: %w", err)
 		}
-
-		return &withdraw.FPWithdrawer{
-			Ctx:      ctx,
-			L1Client: l1Client,
-			L2Client: l2Client,
-			L2TxHash: withdrawal,
-			Portal:   portal,
-			Factory:  dgf,
-			Opts:     l1opts,
-		}, nil
-	} else {
-		portal, err := bindings.NewOptimismPortal(common.HexToAddress(n.portalAddress), l1Client)
+		c.conns = append(c.conns, verifyClient)
+		c.extra = append(c.extra, withdraw.WithVerifyClient(ethclient.NewClient(verifyClient)))
+	}
+	if n.RollupRPC != "" {
+		rollup, err := failover.Dial(ctx, n.RollupRPC, n.rpcOptions())
 		if err != nil {
-			return nil, fmt.Errorf("Error binding OptimismPortal contract: %w", err)
+			return nil, fmt.Errorf("Error dialing rollup client: %w", err)
 		}
+		c.conns = append(c.conns, rollup)
+		c.extra = append(c.extra, withdraw.WithRollupClient(rollup))
+	}
+	return c, nil
+}
 
-		l2oo, err := bindings.NewL2OutputOracle(common.HexToAddress(n.l2OOAddress), l1Client)
-		if err != nil {
-			return nil, fmt.Errorf("Error binding L2OutputOracle contract: %w", err)
+// newWithdrawHelper creates the withdraw helper of the withdrawal initiated by the L2 transaction withdrawal
+// from the clients of n.
+func newWithdrawHelper(ctx context.Context, c *helperClients, withdrawal common.Hash, n network, s signer.Signer, cfg helperConfig) (withdraw.WithdrawHelper, error) {
+	var err error
+	var txMgr txmgr.TxManager
+	// a nil signer yields a read-only helper, which can query and generate proofs but not submit them,
+	// querying fault proofs submitted by cfg.from
+	l1opts := &bind.TransactOpts{Context: ctx, NoSend: true, From: cfg.from}
+	if cfg.buildOnly != nil {
+		l1opts.From, l1opts.GasLimit = cfg.from, cfg.gasLimit
+	} else if s != nil {
+		var l1Nonce uint64
+		switch {
+		case cfg.nonces != nil:
+			// the nonce is assigned when sending
+		case cfg.nonce != nil:
+			l1Nonce = *cfg.nonce
+			if cfg.journal != nil {
+				cfg.journal = replaceJournal{cfg.journal}
+			}
+		default:
+			var replace bool
+			l1Nonce, replace, err = checkPendingTxs(ctx, c.l1, s.Address(), cfg.pendingTxs, cfg.pollInterval)
+			if err != nil {
+				return nil, err
+			}
+			if replace && cfg.journal != nil {
+				cfg.journal = replaceJournal{cfg.journal}
+			}
+		}
+
+		l1opts = &bind.TransactOpts{
+			From:    s.Address(),
+			Signer:  s.SignerFn(c.l1ChainID),
+			Context: ctx,
+			Nonce:   big.NewInt(int64(l1Nonce)),
+		}
+		if err := cfg.applyTo(ctx, c.l1, l1opts); err != nil {
+			return nil, err
 		}
+		if c.txMgr != nil {
+			txMgr = c.txMgr
+			// the helpers only build the transactions, which are signed and sent by the tx manager
+			l1opts.NoSend = true
+			l1opts.Signer = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+				return tx, nil
+			}
+		}
+	}
 
-		return &withdraw.Withdrawer{
-			Ctx:      ctx,
-			L1Client: l1Client,
-			L2Client: l2Client,
-			L2TxHash: withdrawal,
-			Portal:   portal,
-			Oracle:   l2oo,
-			Opts:     l1opts,
-		}, nil
+	settings := withdraw.TxSettings{
+		Journal:          cfg.journal,
+		PollInterval:     cfg.pollInterval,
+		TxManager:        txMgr,
+		MaxBaseFee:       cfg.maxBaseFee,
+		GasBuffer:        cfg.gasBuffer,
+		SpendCap:         cfg.spendCap,
+		NoWait:           cfg.noWait,
+		Confirmations:    cfg.confirmations,
+		ConfirmTag:       cfg.confirmTag,
+		L2BlockTag:       cfg.l2BlockTag,
+		ReorgCheckBlocks: cfg.reorgCheckBlocks,
+		Nonces:           cfg.nonces,
+		Logger:           cfg.logger,
+		BuildOnly:        cfg.buildOnly,
+	}
+	if c.privateRelay != nil {
+		settings.PrivateRelay = c.privateRelay
+		settings.PrivateFallbackBlocks = cfg.privateBlocks
+	}
+	if cfg.newPriceFeed != nil {
+		if settings.PriceFeed, err = cfg.newPriceFeed(ctx, c.l1); err != nil {
+			return nil, err
+		}
+	}
+
+	l2Client := withdraw.NewL2Client(c.l2)
+	if err := withdraw.CheckWithdrawalTx(ctx, c.l1, l2Client, withdrawal); err != nil {
+		return nil, err
+	}
+
+	return withdrawer.NewHelper(n.Network, c.l1, l2Client, c.supervisor, withdrawal, l1opts, settings, c.extra...)
+}
+
+// checkedPortals holds the portals of custom networks checked by checkCustomNetwork, which are only
+// checked once per run.
+var checkedPortals sync.Map
+
+// checkCustomNetwork checks the contracts of the custom network n on L1, and its portal to belong to
+// the L2 chain of its L2 RPC, unless they were already checked.
+func checkCustomNetwork(ctx context.Context, l1 withdraw.L1Client, l2 withdraw.L2Client, n network) error {
+	if _, ok := checkedPortals.Load(n.PortalAddress); ok {
+		return nil
+	}
+	if err := withdraw.CheckNetworkContracts(ctx, l1, n.PortalAddress, n.L2OutputOracle, n.DisputeGameFactory, n.FaultProofs); err != nil {
+		return fmt.Errorf("Error checking the custom network, check --portal-address, --l2oo-address, --dfg-address and --fault-proofs: %w", err)
+	}
+	// super root games are for several chains, so interop portals aren't checked against the L2 chain
+	if !n.Interop {
+		if err := withdraw.CheckPortalChain(ctx, l1, l2, n.PortalAddress, n.FaultProofs); err != nil {
+			return fmt.Errorf("Error checking --portal-address: %w", err)
+		}
+	}
+	checkedPortals.Store(n.PortalAddress, true)
+	return nil
+}
+
+// newTxManager creates the op-service tx manager sending transactions signed by s.
+func newTxManager(client *ethclient.Client, chainID *big.Int, s signer.Signer, cfg helperConfig) (txmgr.TxManager, error) {
+	signFn := s.SignerFn(chainID)
+	defaults := txmgr.DefaultBatcherFlagValues
+	receiptInterval := cfg.pollInterval
+	if receiptInterval <= 0 {
+		receiptInterval = defaults.ReceiptQueryInterval
+	}
+	mgr, err := txmgr.NewSimpleTxManagerFromConfig("withdrawer", log.Root(), &txmetrics.NoopTxMetrics{}, txmgr.Config{
+		Backend: client,
+		ChainID: chainID,
+		From:    s.Address(),
+		Signer: func(_ context.Context, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return signFn(from, tx)
+		},
+		ResubmissionTimeout:       cfg.resubmissionTimeout,
+		FeeLimitMultiplier:        defaults.FeeLimitMultiplier,
+		NumConfirmations:          cfg.confirmations + 1,
+		SafeAbortNonceTooLowCount: defaults.SafeAbortNonceTooLowCount,
+		NetworkTimeout:            defaults.NetworkTimeout,
+		TxNotInMempoolTimeout:     defaults.TxNotInMempoolTimeout,
+		ReceiptQueryInterval:      receiptInterval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error creating tx manager: %w", err)
 	}
+	return mgr, nil
 }
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Discord posts events to a Discord channel webhook.
+type Discord struct {
+	webhookURL string
+	explorers  Explorers
+	client     *http.Client
+}
+
+// NewDiscord creates a Discord notifier posting to webhookURL, linking transactions on explorers.
+func NewDiscord(webhookURL string, explorers Explorers) *Discord {
+	return &Discord{webhookURL: webhookURL, explorers: explorers, client: http.DefaultClient}
+}
+
+func (d *Discord) Notify(ctx context.Context, e Event) error {
+	var content strings.Builder
+	content.WriteString(e.Summary())
+	for _, l := range e.links(d.explorers) {
+		// angle brackets suppress Discord's link previews
+		fmt.Fprintf(&content, "\n- [%s](<%s>)", l.label, l.url)
+	}
+	if e.Err != "" {
+		fmt.Fprintf(&content, "\n```%s```", truncate(e.Err, maxErrLength))
+	}
+	return postJSON(ctx, d.client, d.webhookURL, map[string]string{"content": content.String()})
+}
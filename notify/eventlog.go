@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/base-org/withdrawer/store"
+)
+
+// eventLogVersion is the version of the lines written by EventLog, reported in their schemaVersion field.
+const eventLogVersion = 1
+
+// EventLog appends events as JSON lines to a file per withdrawal, named after its L2 tx hash, for data
+// pipelines to tail. Unlike the chat notifiers, it is meant to be read by machines.
+type EventLog struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// eventLine is a line of the event log of a withdrawal.
+type eventLine struct {
+	SchemaVersion  int          `json:"schemaVersion"`
+	Time           time.Time    `json:"time"`
+	Event          EventKind    `json:"event"`
+	Withdrawal     common.Hash  `json:"withdrawal"`
+	WithdrawalHash *common.Hash `json:"withdrawalHash,omitempty"`
+	Network        string       `json:"network,omitempty"`
+	Status         store.Status `json:"status"`
+	PreviousStatus store.Status `json:"previousStatus,omitempty"`
+	Action         string       `json:"action,omitempty"`
+	ProveTx        *common.Hash `json:"proveTx,omitempty"`
+	FinalizeTx     *common.Hash `json:"finalizeTx,omitempty"`
+	Attempts       int          `json:"attempts,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// NewEventLog creates an event log writing to dir, which is created if it doesn't exist.
+func NewEventLog(dir string) (*EventLog, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &EventLog{dir: dir}, nil
+}
+
+func (l *EventLog) Notify(_ context.Context, e Event) error {
+	line := eventLine{
+		SchemaVersion:  eventLogVersion,
+		Time:           time.Now().UTC(),
+		Event:          e.Kind,
+		Withdrawal:     e.Withdrawal.TxHash,
+		WithdrawalHash: nonZero(e.Withdrawal.WithdrawalHash),
+		Network:        e.Withdrawal.Network,
+		Status:         e.Withdrawal.Status,
+		PreviousStatus: e.PreviousStatus,
+		Action:         e.Action,
+		ProveTx:        nonZero(e.Withdrawal.ProveTx),
+		FinalizeTx:     nonZero(e.Withdrawal.FinalizeTx),
+		Attempts:       e.Withdrawal.Attempts,
+		Error:          e.Err,
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	// the daemon's workers may notify concurrently, and lines must not interleave
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(filepath.Join(l.dir, e.Withdrawal.TxHash.Hex()+".jsonl"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func nonZero(hash common.Hash) *common.Hash {
+	if hash == (common.Hash{}) {
+		return nil
+	}
+	return &hash
+}
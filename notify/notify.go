@@ -0,0 +1,127 @@
+// Package notify delivers daemon events, such as withdrawals changing state, to chat and alerting services.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/base-org/withdrawer/store"
+)
+
+// EventKind is the kind of a daemon event.
+type EventKind string
+
+const (
+	// StatusChanged events are sent when a withdrawal moves to a new status.
+	StatusChanged EventKind = "status-changed"
+	// ActionFailed events are sent when a prove or finalize attempt failed repeatedly.
+	ActionFailed EventKind = "action-failed"
+)
+
+// Event describes a change to a tracked withdrawal.
+type Event struct {
+	Kind       EventKind
+	Withdrawal store.Withdrawal
+	// PreviousStatus is the status of the withdrawal before a StatusChanged event.
+	PreviousStatus store.Status
+	// Action is the failed action ("prove" or "finalize") of an ActionFailed event.
+	Action string
+	Err    string
+}
+
+// Notifier delivers events to an external service.
+type Notifier interface {
+	Notify(ctx context.Context, e Event) error
+}
+
+// Explorers holds the block explorer base URLs (e.g. https://etherscan.io) used to link transactions
+// in notifications. Empty URLs disable links for that chain.
+type Explorers struct {
+	L1 string
+	L2 string
+}
+
+// L1TxURL returns the explorer URL of an L1 transaction, or an empty string if there is no L1 explorer.
+func (e Explorers) L1TxURL(hash common.Hash) string {
+	return txURL(e.L1, hash)
+}
+
+// L2TxURL returns the explorer URL of an L2 transaction, or an empty string if there is no L2 explorer.
+func (e Explorers) L2TxURL(hash common.Hash) string {
+	return txURL(e.L2, hash)
+}
+
+func txURL(explorer string, hash common.Hash) string {
+	if explorer == "" || hash == (common.Hash{}) {
+		return ""
+	}
+	return strings.TrimSuffix(explorer, "/") + "/tx/" + hash.Hex()
+}
+
+// Summary returns a one-line, plain text description of e, prefixed with the network of the withdrawal
+// unless it is on the daemon's default network.
+func (e Event) Summary() string {
+	var s string
+	switch e.Kind {
+	case StatusChanged:
+		s = fmt.Sprintf("Withdrawal %s is now %s (was %s)", e.Withdrawal.TxHash.Hex(), e.Withdrawal.Status, e.PreviousStatus)
+	case ActionFailed:
+		s = fmt.Sprintf("Withdrawal %s: %s failed %d times in a row", e.Withdrawal.TxHash.Hex(), e.Action, e.Withdrawal.Attempts)
+	default:
+		s = fmt.Sprintf("Withdrawal %s: %s", e.Withdrawal.TxHash.Hex(), e.Kind)
+	}
+	if e.Withdrawal.Network != "" {
+		s = "[" + e.Withdrawal.Network + "] " + s
+	}
+	return s
+}
+
+// link is a labelled URL included in a notification.
+type link struct {
+	label string
+	url   string
+}
+
+// links returns the explorer links relevant to e.
+func (e Event) links(explorers Explorers) []link {
+	var links []link
+	for _, l := range []link{
+		{"L2 withdrawal tx", explorers.L2TxURL(e.Withdrawal.TxHash)},
+		{"prove tx", explorers.L1TxURL(e.Withdrawal.ProveTx)},
+		{"finalize tx", explorers.L1TxURL(e.Withdrawal.FinalizeTx)},
+	} {
+		if l.url != "" {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// Multi is a Notifier that delivers events to all of its notifiers.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, e Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// maxErrLength bounds the length of errors included in notifications, keeping messages within the
+// size limits of the chat services.
+const maxErrLength = 1000
+
+// truncate shortens s to at most n bytes, marking the cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
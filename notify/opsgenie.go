@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// opsgenieAlertsURL is the Opsgenie Alert API endpoint.
+const opsgenieAlertsURL = "https://api.opsgenie.com/v2/alerts"
+
+// Opsgenie opens alerts through the Opsgenie Alert API.
+type Opsgenie struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewOpsgenie creates an Opsgenie pager authenticating with an API integration's apiKey.
+func NewOpsgenie(apiKey string) *Opsgenie {
+	return &Opsgenie{apiKey: apiKey, client: http.DefaultClient}
+}
+
+func (o *Opsgenie) Trigger(ctx context.Context, a Alert) error {
+	return postJSON(ctx, o.client, opsgenieAlertsURL, map[string]any{
+		"message":  truncate(a.Summary, 130),
+		"alias":    a.Key,
+		"source":   "withdrawer",
+		"priority": "P2",
+		"details":  a.Details,
+	}, o.authorize)
+}
+
+func (o *Opsgenie) Resolve(ctx context.Context, key string) error {
+	return postJSON(ctx, o.client, opsgenieAlertsURL+"/"+url.PathEscape(key)+"/close?identifierType=alias", map[string]any{
+		"source": "withdrawer",
+	}, o.authorize)
+}
+
+func (o *Opsgenie) authorize(req *http.Request) {
+	req.Header.Set("Authorization", "GenieKey "+o.apiKey)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// Alert is an incident that needs operator attention.
+type Alert struct {
+	// Key identifies the incident, so that repeated triggers are deduplicated and it can be resolved later.
+	Key     string
+	Summary string
+	Details map[string]string
+}
+
+// Pager opens and resolves incidents in an on-call alerting service.
+type Pager interface {
+	Trigger(ctx context.Context, a Alert) error
+	Resolve(ctx context.Context, key string) error
+}
+
+// MultiPager is a Pager that forwards to all of its pagers.
+type MultiPager []Pager
+
+func (m MultiPager) Trigger(ctx context.Context, a Alert) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Trigger(ctx, a); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m MultiPager) Resolve(ctx context.Context, key string) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Resolve(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
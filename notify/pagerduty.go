@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty opens incidents through the PagerDuty Events API v2.
+type PagerDuty struct {
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDuty creates a PagerDuty pager sending events to the service integration with routingKey.
+func NewPagerDuty(routingKey string) *PagerDuty {
+	return &PagerDuty{routingKey: routingKey, client: http.DefaultClient}
+}
+
+func (p *PagerDuty) Trigger(ctx context.Context, a Alert) error {
+	return postJSON(ctx, p.client, pagerDutyEventsURL, map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "trigger",
+		"dedup_key":    a.Key,
+		"payload": map[string]any{
+			"summary":        a.Summary,
+			"source":         "withdrawer",
+			"severity":       "error",
+			"custom_details": a.Details,
+		},
+	})
+}
+
+func (p *PagerDuty) Resolve(ctx context.Context, key string) error {
+	return postJSON(ctx, p.client, pagerDutyEventsURL, map[string]any{
+		"routing_key":  p.routingKey,
+		"event_action": "resolve",
+		"dedup_key":    key,
+	})
+}
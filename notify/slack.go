@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// Slack posts events to a Slack incoming webhook.
+type Slack struct {
+	webhookURL string
+	explorers  Explorers
+	client     *http.Client
+}
+
+// NewSlack creates a Slack notifier posting to webhookURL, linking transactions on explorers.
+func NewSlack(webhookURL string, explorers Explorers) *Slack {
+	return &Slack{webhookURL: webhookURL, explorers: explorers, client: http.DefaultClient}
+}
+
+func (s *Slack) Notify(ctx context.Context, e Event) error {
+	var text strings.Builder
+	text.WriteString(e.Summary())
+	for _, l := range e.links(s.explorers) {
+		fmt.Fprintf(&text, "\n• <%s|%s>", l.url, l.label)
+	}
+	if e.Err != "" {
+		fmt.Fprintf(&text, "\n```%s```", truncate(e.Err, maxErrLength))
+	}
+	return postJSON(ctx, s.client, s.webhookURL, map[string]string{"text": text.String()})
+}
+
+// postJSON posts body as JSON to url, failing on non-2xx responses. The request can be amended,
+// e.g. with authorization headers, by the given functions.
+func postJSON(ctx context.Context, client *http.Client, url string, body any, amend ...func(*http.Request)) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for _, f := range amend {
+		f(req)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// don't leak webhook URLs, which embed their credentials, into logs
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
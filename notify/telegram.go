@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// Telegram sends events to a Telegram chat through a bot.
+type Telegram struct {
+	botToken  string
+	chatID    string
+	explorers Explorers
+	client    *http.Client
+}
+
+// NewTelegram creates a Telegram notifier sending messages as the bot with botToken to chatID,
+// linking transactions on explorers.
+func NewTelegram(botToken, chatID string, explorers Explorers) *Telegram {
+	return &Telegram{botToken: botToken, chatID: chatID, explorers: explorers, client: http.DefaultClient}
+}
+
+func (t *Telegram) Notify(ctx context.Context, e Event) error {
+	var text strings.Builder
+	text.WriteString(html.EscapeString(e.Summary()))
+	for _, l := range e.links(t.explorers) {
+		fmt.Fprintf(&text, "\n• <a href=\"%s\">%s</a>", html.EscapeString(l.url), l.label)
+	}
+	if e.Err != "" {
+		fmt.Fprintf(&text, "\n<pre>%s</pre>", html.EscapeString(truncate(e.Err, maxErrLength)))
+	}
+	return postJSON(ctx, t.client, "https://api.telegram.org/bot"+t.botToken+"/sendMessage", map[string]any{
+		"chat_id":                  t.chatID,
+		"text":                     text.String(),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+}
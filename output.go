@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// schemaVersion is the version of the JSON documents printed by the step, status, accounting, activity and
+// report subcommands and with --no-wait, and of the failures written by batch. Fields may be added within a
+// version, while renaming, removing or changing the meaning of a field requires a new version. It is
+// reported in the schemaVersion field of every document.
+const schemaVersion = 1
+
+// outputSchemas are the JSON Schemas of the printed documents and of the lines of the daemon's --event-log, by the name passed to the schema subcommand.
+var outputSchemas = map[string]string{
+	"step": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer step result",
+  "type": "object",
+  "required": ["schemaVersion", "withdrawal", "status"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+    "status": {"enum": ["pending", "proven", "finalized"], "description": "status after the step, not accounting for a transaction sent with --no-wait"},
+    "action": {"enum": ["prove", "finalize"], "description": "action taken, if any"},
+    "tx": {"$ref": "#/$defs/hash", "description": "L1 transaction of the action"},
+    "txUrl": {"type": "string", "format": "uri", "description": "link to tx on the L1 block explorer of the network"},
+    "reason": {"type": "string", "description": "why no action was taken"},
+    "nextRunAt": {"type": "string", "format": "date-time", "description": "earliest time rerunning could make progress"}
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+	"sent-tx": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer transaction sent with --no-wait",
+  "type": "object",
+  "required": ["schemaVersion", "withdrawal", "action", "tx"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+    "action": {"enum": ["prove", "finalize"]},
+    "tx": {"$ref": "#/$defs/hash", "description": "L1 transaction sent for the action"},
+    "txUrl": {"type": "string", "format": "uri", "description": "link to tx on the L1 block explorer of the network"}
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+	"status": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer status --json",
+  "type": "object",
+  "required": ["schemaVersion", "withdrawals"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "withdrawals": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["txHash", "withdrawalHash", "status", "proveTx", "finalizeTx", "createdAt", "updatedAt", "provenAt", "finalizedAt", "attempts", "nextAttemptAt"],
+        "properties": {
+          "txHash": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+          "withdrawalHash": {"$ref": "#/$defs/hash", "description": "zero until first queried"},
+          "status": {"enum": ["pending", "proven", "finalized", "dead-letter"]},
+          "proveTx": {"$ref": "#/$defs/hash", "description": "zero if not proven by the daemon"},
+          "finalizeTx": {"$ref": "#/$defs/hash", "description": "zero if not finalized by the daemon"},
+          "createdAt": {"type": "string", "format": "date-time"},
+          "updatedAt": {"type": "string", "format": "date-time"},
+          "provenAt": {"type": "string", "format": "date-time", "description": "zero time if not proven"},
+          "finalizedAt": {"type": "string", "format": "date-time", "description": "zero time if not finalized"},
+          "lastError": {"type": "string"},
+          "attempts": {"type": "integer", "description": "consecutive failed attempts"},
+          "nextAttemptAt": {"type": "string", "format": "date-time"},
+          "network": {"type": "string", "description": "name of the network in the daemon's --networks file, absent for its default network"}
+        }
+      }
+    }
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+	"accounting": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer accounting --format json",
+  "type": "object",
+  "required": ["schemaVersion", "transactions"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "transactions": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["withdrawal", "withdrawalHash", "action", "tx", "block", "time", "from", "gasUsed", "gasPrice", "fee", "feeEth"],
+        "properties": {
+          "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+          "withdrawalHash": {"$ref": "#/$defs/hash", "description": "zero if not recorded"},
+          "action": {"enum": ["prove", "finalize"]},
+          "tx": {"$ref": "#/$defs/hash", "description": "L1 transaction of the action"},
+          "txUrl": {"type": "string", "format": "uri", "description": "link to tx on the L1 block explorer of the network"},
+          "block": {"type": "integer"},
+          "time": {"type": "string", "format": "date-time", "description": "time of the block including tx"},
+          "from": {"type": "string", "pattern": "^0x[0-9a-fA-F]{40}$", "description": "address that paid for tx"},
+          "gasUsed": {"type": "integer"},
+          "gasPrice": {"type": "string", "pattern": "^[0-9]+$", "description": "effective gas price, in wei"},
+          "fee": {"type": "string", "pattern": "^[0-9]+$", "description": "fee paid, in wei"},
+          "feeEth": {"type": "string", "description": "fee paid, in ETH"},
+          "ethUsd": {"type": "number", "description": "ETH price in USD at the time, with --usd-price"},
+          "feeUsd": {"type": "number", "description": "fee paid in USD, with --usd-price"}
+        }
+      }
+    }
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+	"activity": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer activity --format json",
+  "type": "object",
+  "required": ["schemaVersion", "events"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "events": {
+      "type": "array",
+      "description": "with --format ndjson, the items are printed one per line instead",
+      "items": {
+        "type": "object",
+        "required": ["action", "block", "time", "tx", "logIndex", "withdrawalHash", "submitter"],
+        "properties": {
+          "action": {"enum": ["prove", "finalize"]},
+          "block": {"type": "integer"},
+          "time": {"type": "string", "format": "date-time", "description": "time of the block including tx"},
+          "tx": {"$ref": "#/$defs/hash", "description": "L1 transaction emitting the event"},
+          "logIndex": {"type": "integer"},
+          "withdrawalHash": {"$ref": "#/$defs/hash"},
+          "submitter": {"$ref": "#/$defs/address", "description": "sender of tx"},
+          "success": {"type": "boolean", "description": "whether the call to the L1 target succeeded, for finalize events"},
+          "sender": {"$ref": "#/$defs/address", "description": "L2 sender of the withdrawal, if tx called the portal directly"},
+          "target": {"$ref": "#/$defs/address", "description": "L1 target of the withdrawal, if tx called the portal directly"},
+          "value": {"type": "string", "pattern": "^[0-9]+$", "description": "ETH withdrawn, in wei, if tx called the portal directly"},
+          "from": {"$ref": "#/$defs/address", "description": "L2 account the withdrawal is attributed to: the sender of its standard bridge transfer or messenger message, or its sender"},
+          "to": {"$ref": "#/$defs/address", "description": "L1 account the withdrawal is attributed to: the recipient of its standard bridge transfer, the target of its messenger message, or its target"},
+          "bridgeToken": {"$ref": "#/$defs/address", "description": "L1 token of a standard bridge transfer, zero for ETH"},
+          "bridgeFrom": {"$ref": "#/$defs/address"},
+          "bridgeTo": {"$ref": "#/$defs/address"},
+          "bridgeAmount": {"type": "string", "pattern": "^[0-9]+$", "description": "amount of a standard bridge transfer, in the token's base unit"}
+        }
+      }
+    }
+  },
+  "$defs": {
+    "hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"},
+    "address": {"type": "string", "pattern": "^0x[0-9a-fA-F]{40}$"}
+  }
+}`,
+	"report": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer report --format json",
+  "type": "object",
+  "required": ["schemaVersion", "network", "generatedAt", "accounts", "fromBlock", "toBlock", "withdrawals", "finalized"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "network": {"type": "string"},
+    "generatedAt": {"type": "string", "format": "date-time"},
+    "accounts": {"type": "array", "items": {"$ref": "#/$defs/address"}, "description": "L2 accounts whose withdrawals are reported"},
+    "fromBlock": {"type": "integer", "description": "first L2 block scanned"},
+    "toBlock": {"type": "integer", "description": "last L2 block scanned"},
+    "withdrawals": {
+      "type": "array",
+      "description": "in-flight withdrawals, soonest actionable first",
+      "items": {
+        "type": "object",
+        "required": ["withdrawal", "account", "value", "initiatedAt", "ageSeconds", "stage", "nextAction"],
+        "properties": {
+          "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+          "url": {"type": "string", "format": "uri", "description": "link to withdrawal on the L2 block explorer of the network"},
+          "account": {"$ref": "#/$defs/address", "description": "account of accounts that initiated the withdrawal"},
+          "value": {"type": "string", "pattern": "^[0-9]+$", "description": "ETH withdrawn, in wei"},
+          "token": {"$ref": "#/$defs/address", "description": "L1 token of a standard bridge token transfer"},
+          "amount": {"type": "string", "pattern": "^[0-9]+$", "description": "amount of a standard bridge token transfer, in the token's base unit"},
+          "initiatedAt": {"type": "string", "format": "date-time"},
+          "ageSeconds": {"type": "integer"},
+          "stage": {"enum": ["initiated", "proven"]},
+          "provenBy": {"$ref": "#/$defs/address", "description": "account that proved the withdrawal, with Fault Proofs"},
+          "nextAction": {"enum": ["prove", "finalize"]},
+          "blockedBy": {"type": "string", "description": "why nextAction can't be taken yet, absent if it can be taken now"},
+          "actionableAt": {"type": "string", "format": "date-time", "description": "earliest time nextAction can be taken, if it could be estimated"}
+        }
+      }
+    },
+    "finalized": {"type": "integer", "description": "number of withdrawals found that are already finalized and left out"}
+  },
+  "$defs": {
+    "hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"},
+    "address": {"type": "string", "pattern": "^0x[0-9a-fA-F]{40}$"}
+  }
+}`,
+	"failures": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer batch --failures",
+  "type": "object",
+  "required": ["schemaVersion", "network", "total", "failures"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "network": {"type": "string"},
+    "total": {"type": "integer", "description": "number of withdrawals of the run"},
+    "failures": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["withdrawal", "error", "code"],
+        "properties": {
+          "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+          "error": {"type": "string"},
+          "code": {"type": "integer", "description": "exit code of the error, as returned by the step command"}
+        }
+      }
+    }
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+	"event": `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "title": "withdrawer daemon --event-log line",
+  "type": "object",
+  "required": ["schemaVersion", "time", "event", "withdrawal", "status"],
+  "properties": {
+    "schemaVersion": {"const": 1},
+    "time": {"type": "string", "format": "date-time"},
+    "event": {"enum": ["status-changed", "action-failed"]},
+    "withdrawal": {"$ref": "#/$defs/hash", "description": "L2 transaction initiating the withdrawal"},
+    "withdrawalHash": {"$ref": "#/$defs/hash"},
+    "network": {"type": "string", "description": "name of the network in the daemon's --networks file, absent for its default network"},
+    "status": {"enum": ["pending", "proven", "finalized", "dead-letter"], "description": "status after the event"},
+    "previousStatus": {"enum": ["pending", "proven", "finalized", "dead-letter"], "description": "status before a status-changed event"},
+    "action": {"enum": ["prove", "finalize"], "description": "failed action of an action-failed event"},
+    "proveTx": {"$ref": "#/$defs/hash"},
+    "finalizeTx": {"$ref": "#/$defs/hash"},
+    "attempts": {"type": "integer", "description": "consecutive failed attempts"},
+    "error": {"type": "string"}
+  },
+  "$defs": {"hash": {"type": "string", "pattern": "^0x[0-9a-f]{64}$"}}
+}`,
+}
+
+// printJSON prints v as a single line of JSON to stdout.
+func printJSON(v any) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		log.Crit("Error encoding output", "error", err)
+	}
+	os.Stdout.Write(append(out, '\n'))
+}
+
+// runSchema implements the schema subcommand, which prints the JSON Schema of a printed document.
+func runSchema(args []string) {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	var names []string
+	for name := range outputSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if fs.NArg() != 1 {
+		log.Crit("Expected the name of a document", "documents", strings.Join(names, ", "))
+	}
+	schema, ok := outputSchemas[fs.Arg(0)]
+	if !ok {
+		log.Crit("Unknown document", "name", fs.Arg(0), "documents", strings.Join(names, ", "))
+	}
+	fmt.Println(schema)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Policies for transactions of the signer that are still pending when a new one is sent, selected with
+// --pending-txs.
+const (
+	pendingWarn    = "warn"
+	pendingWait    = "wait"
+	pendingReplace = "replace"
+)
+
+// checkPendingTxs looks for transactions of the signer that are still pending, or queued behind a nonce gap,
+// and returns the nonce to send the next transaction with according to policy: warn sends it after them, wait
+// waits for them to be mined first, and replace sends it with the nonce of the oldest pending one, in which
+// case replace is true.
+func checkPendingTxs(ctx context.Context, client *ethclient.Client, from common.Address, policy string, pollInterval time.Duration) (nonce uint64, replace bool, err error) {
+	for first := true; ; first = false {
+		mined, err := client.NonceAt(ctx, from, nil)
+		if err != nil {
+			return 0, false, fmt.Errorf("Error querying nonce: %w", err)
+		}
+		pending, err := client.PendingNonceAt(ctx, from)
+		if err != nil {
+			return 0, false, fmt.Errorf("Error querying nonce: %w", err)
+		}
+		if first {
+			if queued := queuedTxs(ctx, client, from); queued > 0 {
+				log.Warn("Signer has transactions queued behind a nonce gap, which the next transaction may fill", "count", queued, "nonce", pending)
+			}
+		}
+		if pending <= mined {
+			return pending, false, nil
+		}
+
+		switch policy {
+		case pendingWait:
+			log.Info("Waiting for pending transactions of the signer to be mined", "count", pending-mined, "nonces", fmt.Sprintf("%d-%d", mined, pending-1))
+			select {
+			case <-ctx.Done():
+				return 0, false, ctx.Err()
+			case <-time.After(pollInterval):
+			}
+		case pendingReplace:
+			log.Warn("Replacing the oldest pending transaction of the signer", "nonce", mined)
+			return mined, true, nil
+		default:
+			log.Warn("Signer has pending transactions, which the new one will be mined after (see --pending-txs, speed-up and cancel)", "count", pending-mined, "nonces", fmt.Sprintf("%d-%d", mined, pending-1))
+			return pending, false, nil
+		}
+	}
+}
+
+// queuedTxs returns the number of transactions of from that the node holds back because of a nonce gap, or 0
+// if the node doesn't support txpool_contentFrom.
+func queuedTxs(ctx context.Context, client *ethclient.Client, from common.Address) int {
+	var content struct {
+		Queued map[string]json.RawMessage `json:"queued"`
+	}
+	if err := client.Client().CallContext(ctx, &content, "txpool_contentFrom", from); err != nil {
+		log.Debug("Error querying txpool content", "error", err)
+		return 0
+	}
+	return len(content.Queued)
+}
@@ -0,0 +1,50 @@
+package withdrawer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// NewHelper binds the contracts of the network and returns the helper for the withdrawal initiated in
+// l2TxHash, sending transactions with opts and settings. The supervisor client is only used on interop
+// networks. Sent transactions are linked to the L1Explorer of the network unless settings has one.
+// *ethclient.Client is an L1Client, and withdraw.NewL2Client wraps L2 JSON-RPC clients. Options such as
+// withdraw.WithVerifyClient can be passed in extra.
+func NewHelper(n Network, l1 withdraw.L1Client, l2 withdraw.L2Client, supervisor withdraw.SupervisorClient, l2TxHash common.Hash, opts *bind.TransactOpts, settings withdraw.TxSettings, extra ...withdraw.Option) (withdraw.WithdrawHelper, error) {
+	if settings.L1Explorer == "" {
+		settings.L1Explorer = n.L1Explorer
+	}
+	options := []withdraw.Option{
+		withdraw.WithL1Client(l1),
+		withdraw.WithL2Client(l2),
+		withdraw.WithL2TxHash(l2TxHash),
+		withdraw.WithPortal(n.PortalAddress),
+		withdraw.WithPortalAdapter(n.PortalAdapter),
+		withdraw.WithTransactOpts(opts),
+		withdraw.WithTxSettings(settings),
+	}
+	options = append(options, extra...)
+	// the constructors are called separately, so that no typed nil helper is returned on error
+	if !n.FaultProofs {
+		h, err := withdraw.NewWithdrawer(append(options, withdraw.WithL2OutputOracle(n.L2OutputOracle))...)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	}
+	options = append(options, withdraw.WithDisputeGameFactory(n.DisputeGameFactory))
+	if n.Interop {
+		h, err := withdraw.NewSuperRootWithdrawer(append(options, withdraw.WithSupervisor(supervisor))...)
+		if err != nil {
+			return nil, err
+		}
+		return h, nil
+	}
+	h, err := withdraw.NewFPWithdrawer(options...)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
@@ -0,0 +1,61 @@
+package withdrawer
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Network holds the L2 RPC url and the L1 contracts of an OP Stack chain withdrawals are made from.
+type Network struct {
+	// L2RPC holds the comma-separated L2 RPC urls to fail over between.
+	L2RPC              string
+	PortalAddress      common.Address
+	L2OutputOracle     common.Address
+	DisputeGameFactory common.Address
+	FaultProofs        bool
+	// Interop networks prove withdrawals against super roots queried from SupervisorRPC.
+	Interop       bool
+	SupervisorRPC string
+	// RollupRPC, if set, is the url of an op-node rollup RPC that the output roots withdrawals are proven
+	// against are checked with.
+	RollupRPC string
+	// PortalAdapter is the name of the withdraw.PortalAdapter binding the portal, by default the one of
+	// the OptimismPortal matching FaultProofs.
+	PortalAdapter string
+	// L1Explorer and L2Explorer are the block explorers transactions are linked to.
+	L1Explorer string
+	L2Explorer string
+}
+
+// Networks are the known networks, by name.
+var Networks = map[string]Network{
+	"base-mainnet": {
+		L2RPC:              "https://mainnet.base.org",
+		PortalAddress:      common.HexToAddress("0x49048044D57e1C92A77f79988d21Fa8fAF74E97e"),
+		DisputeGameFactory: common.HexToAddress("0x43edB88C4B80fDD2AdFF2412A7BebF9dF42cB40e"),
+		FaultProofs:        true,
+		L1Explorer:         "https://etherscan.io",
+		L2Explorer:         "https://basescan.org",
+	},
+	"base-sepolia": {
+		L2RPC:              "https://sepolia.base.org",
+		PortalAddress:      common.HexToAddress("0x49f53e41452C74589E85cA1677426Ba426459e85"),
+		DisputeGameFactory: common.HexToAddress("0xd6E6dBf4F7EA0ac412fD8b65ED297e64BB7a06E1"),
+		FaultProofs:        true,
+		L1Explorer:         "https://sepolia.etherscan.io",
+		L2Explorer:         "https://sepolia.basescan.org",
+	},
+	"op-mainnet": {
+		L2RPC:              "https://mainnet.optimism.io",
+		PortalAddress:      common.HexToAddress("0xbEb5Fc579115071764c7423A4f12eDde41f106Ed"),
+		DisputeGameFactory: common.HexToAddress("0xe5965Ab5962eDc7477C8520243A95517CD252fA9"),
+		FaultProofs:        true,
+		L1Explorer:         "https://etherscan.io",
+		L2Explorer:         "https://optimistic.etherscan.io",
+	},
+	"op-sepolia": {
+		L2RPC:              "https://sepolia.optimism.io",
+		PortalAddress:      common.HexToAddress("0x16Fc5058F25648194471939df75CF27A2fdC48BC"),
+		DisputeGameFactory: common.HexToAddress("0x05F9613aDB30026FFd634f38e5C4dFd30a197Fa1"),
+		FaultProofs:        true,
+		L1Explorer:         "https://sepolia.etherscan.io",
+		L2Explorer:         "https://sepolia-optimism.etherscan.io",
+	},
+}
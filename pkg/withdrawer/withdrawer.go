@@ -0,0 +1,185 @@
+// Package withdrawer proves and finalizes withdrawals from OP Stack chains, for backends embedding
+// withdrawal handling instead of running the withdrawer binary.
+//
+//	w, err := withdrawer.NewFromNetwork(ctx, "base-mainnet", l1RPC, s)
+//	status, err := w.Status(ctx, l2TxHash)
+//	if status == withdrawer.StatusProvable {
+//		tx, err := w.Prove(ctx, l2TxHash)
+//	}
+package withdrawer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/base-org/withdrawer/failover"
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// Status is the state of a withdrawal.
+type Status string
+
+const (
+	// StatusPending withdrawals can't be proven yet, as no output or dispute game covers them.
+	StatusPending Status = "pending"
+	// StatusProvable withdrawals can be proven.
+	StatusProvable Status = "provable"
+	// StatusProven withdrawals are proven, but can't be finalized yet.
+	StatusProven Status = "proven"
+	// StatusFinalizable withdrawals can be finalized.
+	StatusFinalizable Status = "finalizable"
+	// StatusFinalized withdrawals are complete.
+	StatusFinalized Status = "finalized"
+)
+
+// Withdrawer proves and finalizes the withdrawals of a network, signing transactions with its signer.
+type Withdrawer struct {
+	network    Network
+	l1         *ethclient.Client
+	l2         *rpc.Client
+	supervisor *rpc.Client
+	rollup     *rpc.Client
+	l1ChainID  *big.Int
+	signer     signer.Signer
+
+	// TxSettings configure how transactions are sent and waited for.
+	TxSettings withdraw.TxSettings
+}
+
+// NewFromNetwork returns a withdrawer for the known network with the given name, connecting to the
+// L1 RPC url. A nil signer yields a withdrawer that can only query withdrawals.
+func NewFromNetwork(ctx context.Context, name, l1RPC string, s signer.Signer) (*Withdrawer, error) {
+	n, ok := Networks[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown network %s", name)
+	}
+	return New(ctx, n, l1RPC, s)
+}
+
+// New returns a withdrawer for the network, connecting to the L1 RPC url. A nil signer yields a
+// withdrawer that can only query withdrawals.
+func New(ctx context.Context, n Network, l1RPC string, s signer.Signer) (*Withdrawer, error) {
+	l1c, err := failover.Dial(ctx, l1RPC, failover.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("error dialing L1 client: %w", err)
+	}
+	l1 := ethclient.NewClient(l1c)
+	l1ChainID, err := l1.ChainID(ctx)
+	if err != nil {
+		l1.Close()
+		return nil, fmt.Errorf("error querying chain ID: %w", err)
+	}
+	l2, err := failover.Dial(ctx, n.L2RPC, failover.Options{})
+	if err != nil {
+		l1.Close()
+		return nil, fmt.Errorf("error dialing L2 client: %w", err)
+	}
+	w := &Withdrawer{network: n, l1: l1, l2: l2, l1ChainID: l1ChainID, signer: s}
+	if n.Interop {
+		if w.supervisor, err = failover.Dial(ctx, n.SupervisorRPC, failover.Options{}); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("error dialing supervisor client: %w", err)
+		}
+	}
+	if n.RollupRPC != "" {
+		if w.rollup, err = failover.Dial(ctx, n.RollupRPC, failover.Options{}); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("error dialing rollup client: %w", err)
+		}
+	}
+	return w, nil
+}
+
+// Close closes the connections of the withdrawer.
+func (w *Withdrawer) Close() {
+	w.l1.Close()
+	w.l2.Close()
+	if w.supervisor != nil {
+		w.supervisor.Close()
+	}
+	if w.rollup != nil {
+		w.rollup.Close()
+	}
+}
+
+// helper returns the helper for the withdrawal initiated in l2TxHash, bound to ctx.
+func (w *Withdrawer) helper(ctx context.Context, l2TxHash common.Hash) (withdraw.WithdrawHelper, error) {
+	opts := &bind.TransactOpts{Context: ctx, NoSend: true}
+	if w.signer != nil {
+		// the nonce is left unset, so that the pending nonce is used when sending
+		opts = &bind.TransactOpts{From: w.signer.Address(), Signer: w.signer.SignerFn(w.l1ChainID), Context: ctx}
+	}
+	var supervisor withdraw.SupervisorClient
+	if w.supervisor != nil {
+		supervisor = w.supervisor
+	}
+	var extra []withdraw.Option
+	if w.rollup != nil {
+		extra = append(extra, withdraw.WithRollupClient(w.rollup))
+	}
+	return NewHelper(w.network, w.l1, withdraw.NewL2Client(w.l2), supervisor, l2TxHash, opts, w.TxSettings, extra...)
+}
+
+// Status returns the state of the withdrawal initiated in l2TxHash. Withdrawals that can't progress, e.g.
+// as their dispute game was invalidated, are reported with the corresponding error of the withdraw package.
+func (w *Withdrawer) Status(ctx context.Context, l2TxHash common.Hash) (Status, error) {
+	h, err := w.helper(ctx, l2TxHash)
+	if err != nil {
+		return "", err
+	}
+	finalized, err := h.IsProofFinalized(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error querying withdrawal finalization status: %w", err)
+	}
+	if finalized {
+		return StatusFinalized, nil
+	}
+	proofTime, err := h.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error querying withdrawal proof: %w", err)
+	}
+	if proofTime == 0 {
+		if err := h.CheckIfProvable(ctx); errors.Is(err, withdraw.ErrNotProvableYet) {
+			return StatusPending, nil
+		} else if err != nil {
+			return "", err
+		}
+		return StatusProvable, nil
+	}
+	if err := h.CheckIfFinalizable(ctx); errors.Is(err, withdraw.ErrChallengePeriodActive) || errors.Is(err, withdraw.ErrGameNotResolved) {
+		return StatusProven, nil
+	} else if err != nil {
+		return "", err
+	}
+	return StatusFinalizable, nil
+}
+
+// Prove proves the withdrawal initiated in l2TxHash and returns the hash of the proving transaction.
+func (w *Withdrawer) Prove(ctx context.Context, l2TxHash common.Hash) (common.Hash, error) {
+	h, err := w.helper(ctx, l2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := h.CheckIfProvable(ctx); err != nil {
+		return common.Hash{}, err
+	}
+	return h.ProveWithdrawal(ctx)
+}
+
+// Finalize finalizes the proven withdrawal initiated in l2TxHash and returns the hash of the finalizing
+// transaction.
+func (w *Withdrawer) Finalize(ctx context.Context, l2TxHash common.Hash) (common.Hash, error) {
+	h, err := w.helper(ctx, l2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return h.FinalizeWithdrawal(ctx)
+}
@@ -0,0 +1,61 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ChainlinkFeeds are the Chainlink ETH/USD price feeds by L1 chain ID.
+var ChainlinkFeeds = map[uint64]common.Address{
+	1:        common.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419"),
+	11155111: common.HexToAddress("0x694AA1769357215DE4FAC081bf1f309aDC325306"),
+}
+
+const aggregatorABI = `[
+	{"inputs":[],"name":"decimals","outputs":[{"type":"uint8"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"latestRoundData","outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}],"stateMutability":"view","type":"function"}
+]`
+
+// Chainlink reads the ETH price from a Chainlink ETH/USD aggregator.
+type Chainlink struct {
+	feed *bind.BoundContract
+}
+
+func NewChainlink(address common.Address, backend bind.ContractCaller) (*Chainlink, error) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+	return &Chainlink{feed: bind.NewBoundContract(address, parsed, backend, nil, nil)}, nil
+}
+
+func (c *Chainlink) ETHUSD(ctx context.Context) (float64, error) {
+	return c.ethUSD(&bind.CallOpts{Context: ctx})
+}
+
+// ETHUSDAt reads the price the feed reported at block, which requires an archive node for old blocks.
+func (c *Chainlink) ETHUSDAt(ctx context.Context, block *big.Int, _ time.Time) (float64, error) {
+	return c.ethUSD(&bind.CallOpts{Context: ctx, BlockNumber: block})
+}
+
+func (c *Chainlink) ethUSD(opts *bind.CallOpts) (float64, error) {
+	var out []interface{}
+	if err := c.feed.Call(opts, &out, "decimals"); err != nil {
+		return 0, fmt.Errorf("error querying Chainlink feed decimals: %w", err)
+	}
+	decimals := out[0].(uint8)
+	out = nil
+	if err := c.feed.Call(opts, &out, "latestRoundData"); err != nil {
+		return 0, fmt.Errorf("error querying Chainlink feed: %w", err)
+	}
+	answer := out[1].(*big.Int)
+	price, _ := new(big.Float).Quo(new(big.Float).SetInt(answer), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))).Float64()
+	return price, nil
+}
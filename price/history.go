@@ -0,0 +1,67 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CoinGeckoHistoryURL is the CoinGecko endpoint for the daily price of ETH, with the date to append.
+const CoinGeckoHistoryURL = "https://api.coingecko.com/api/v3/coins/ethereum/history?localization=false&date="
+
+// CoinGeckoHistory fetches the daily ETH price from the CoinGecko history API, which is the price at
+// 00:00 UTC of the day, caching it per day.
+type CoinGeckoHistory struct {
+	url    string
+	client *http.Client
+
+	mu     sync.Mutex
+	prices map[string]float64
+}
+
+func NewCoinGeckoHistory(url string) *CoinGeckoHistory {
+	return &CoinGeckoHistory{url: url, client: http.DefaultClient, prices: make(map[string]float64)}
+}
+
+func (h *CoinGeckoHistory) ETHUSDAt(ctx context.Context, _ *big.Int, t time.Time) (float64, error) {
+	date := t.UTC().Format("02-01-2006")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if price, ok := h.prices[date]; ok {
+		return price, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url+date, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error querying price API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("unexpected price API status %s", resp.Status)
+	}
+	var body struct {
+		MarketData struct {
+			CurrentPrice struct {
+				USD *float64 `json:"usd"`
+			} `json:"current_price"`
+		} `json:"market_data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("error decoding price API response: %w", err)
+	}
+	if body.MarketData.CurrentPrice.USD == nil {
+		return 0, errors.New("price API response has no market_data.current_price.usd price")
+	}
+	h.prices[date] = *body.MarketData.CurrentPrice.USD
+	return h.prices[date], nil
+}
@@ -0,0 +1,80 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Feed provides the price of ETH in USD.
+type Feed interface {
+	ETHUSD(ctx context.Context) (float64, error)
+}
+
+// HistoricalFeed provides the price of ETH in USD at a past L1 block, mined at time t.
+type HistoricalFeed interface {
+	ETHUSDAt(ctx context.Context, block *big.Int, t time.Time) (float64, error)
+}
+
+// CoinGeckoURL is the CoinGecko simple price endpoint for ETH in USD.
+const CoinGeckoURL = "https://api.coingecko.com/api/v3/simple/price?ids=ethereum&vs_currencies=usd"
+
+// API fetches the ETH price from an HTTP endpoint in the CoinGecko simple price format,
+// {"ethereum": {"usd": <price>}}.
+type API struct {
+	url    string
+	client *http.Client
+}
+
+func NewAPI(url string) *API {
+	return &API{url: url, client: http.DefaultClient}
+}
+
+func (a *API) ETHUSD(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error querying price API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("unexpected price API status %s", resp.Status)
+	}
+	var body struct {
+		Ethereum struct {
+			USD *float64 `json:"usd"`
+		} `json:"ethereum"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("error decoding price API response: %w", err)
+	}
+	if body.Ethereum.USD == nil {
+		return 0, errors.New("price API response has no ethereum.usd price")
+	}
+	return *body.Ethereum.USD, nil
+}
+
+// FormatETH formats an amount in wei as ETH, followed by its USD value if feed is set and the price
+// could be fetched.
+func FormatETH(ctx context.Context, feed Feed, wei *big.Int) string {
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Float64()
+	s := fmt.Sprintf("%.6f ETH", eth)
+	if feed == nil {
+		return s
+	}
+	usd, err := feed.ETHUSD(ctx)
+	if err != nil {
+		return s + " (USD price unavailable: " + err.Error() + ")"
+	}
+	return fmt.Sprintf("%s ($%.2f)", s, eth*usd)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runProve implements the prove subcommand, which proves a withdrawal or, with --export,
+// writes the proof parameters to a file without submitting them.
+func runProve(args []string) {
+	fs := flag.NewFlagSet("prove", flag.ExitOnError)
+	f := registerFlags(fs)
+	var export string
+	fs.StringVar(&export, "export", "", "Write the proof parameters as JSON to this file instead of submitting them")
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	if export != "" && n.Interop {
+		log.Crit("--export isn't supported with --interop, as the exported parameters don't include the super root proof")
+	}
+	withdrawal := f.withdrawalHash()
+
+	// exporting doesn't submit anything, so no signer is required
+	var s signer.Signer
+	if export == "" {
+		s = f.createSigner()
+	}
+
+	journal := f.openJournal()
+	defer journal.Store.Close()
+
+	ctx := interruptContext()
+	withdrawer, err := CreateWithdrawHelper(ctx, f.rpc, withdrawal, n, s, f.helperConfig(journal))
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+
+	explorers := n.explorers()
+	summary := runSummary{withdrawal: withdrawal}
+
+	err = withdrawer.CheckIfProvable(ctx)
+	if err != nil {
+		if errors.Is(err, withdraw.ErrNotProvableYet) && export == "" {
+			summary.result = "Not provable yet"
+			summary.next = rerunAfter(ctx, withdrawer, "Re-run", "prove", "Re-run once the next output or dispute game covering the withdrawal is proposed")
+			summary.print(explorers)
+		}
+		exitWithError("Withdrawal is not provable", err)
+	}
+
+	if export != "" {
+		g, ok := withdrawer.(withdraw.ProofGenerator)
+		if !ok {
+			log.Crit("Proof export is not supported for this withdrawal flow")
+		}
+		if err := withdraw.ExportProof(ctx, g, withdrawal, export); err != nil {
+			log.Crit("Error exporting proof", "error", err)
+		}
+		fmt.Printf("Wrote proof parameters for %s to %s\n", withdrawal.String(), export)
+		return
+	}
+
+	tx, err := withdrawer.ProveWithdrawal(ctx)
+	if err != nil {
+		exitWithError("Error proving withdrawal", err)
+	}
+	if f.noWait {
+		printSentTx(explorers, withdrawal, "prove", tx)
+		return
+	}
+	summary.result, summary.proveTx = "Proven", tx
+	summary.next = rerunAfter(ctx, withdrawer, "Run the withdrawer without a subcommand", "finalize", "Run the withdrawer without a subcommand to finalize once the finalization period has elapsed")
+	summary.print(explorers)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/price"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// queueEntry is an in-flight withdrawal in the report subcommand's output.
+type queueEntry struct {
+	Withdrawal common.Hash `json:"withdrawal"`
+	// URL links Withdrawal on the L2 block explorer of the network, if it has one.
+	URL     string         `json:"url,omitempty"`
+	Account common.Address `json:"account"`
+	// Value is the ETH withdrawn, in wei, and Token and Amount the L1 token and amount of a standard
+	// bridge token transfer.
+	Value       string          `json:"value"`
+	Token       *common.Address `json:"token,omitempty"`
+	Amount      string          `json:"amount,omitempty"`
+	InitiatedAt time.Time       `json:"initiatedAt"`
+	AgeSeconds  int64           `json:"ageSeconds"`
+	// Stage is initiated or proven, and NextAction prove or finalize accordingly.
+	Stage      string          `json:"stage"`
+	ProvenBy   *common.Address `json:"provenBy,omitempty"`
+	NextAction string          `json:"nextAction"`
+	// BlockedBy is why NextAction can't be taken yet, and is empty if it can be taken now.
+	BlockedBy string `json:"blockedBy,omitempty"`
+	// ActionableAt is the earliest time NextAction can be taken, if it could be estimated.
+	ActionableAt *time.Time `json:"actionableAt,omitempty"`
+}
+
+// queueReport is the report printed by the report subcommand, as JSON with --format json.
+type queueReport struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	Network       string           `json:"network"`
+	GeneratedAt   time.Time        `json:"generatedAt"`
+	Accounts      []common.Address `json:"accounts"`
+	FromBlock     uint64           `json:"fromBlock"`
+	ToBlock       uint64           `json:"toBlock"`
+	Withdrawals   []queueEntry     `json:"withdrawals"`
+	// Finalized is the number of withdrawals found that are already finalized, which are left out.
+	Finalized int `json:"finalized"`
+
+	explorers notify.Explorers
+}
+
+// runReport implements the report subcommand, which summarizes the in-flight withdrawals of a set of
+// accounts, with their stage, what blocks them and when they can progress, for the daily reports of
+// operations teams.
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	f := registerFlags(fs)
+	var senders, provers, format, out string
+	var fromBlock, blockRange uint64
+	var lookback time.Duration
+	fs.StringVar(&senders, "senders", "", "Comma-separated L2 addresses to report the in-flight withdrawals of")
+	fs.StringVar(&provers, "provers", "", "Comma-separated L1 addresses that may have proven the withdrawals with Fault Proofs, in addition to the withdrawing address")
+	fs.Uint64Var(&fromBlock, "from-block", 0, "L2 block to start scanning for withdrawals from (default: --lookback before now)")
+	fs.DurationVar(&lookback, "lookback", 14*24*time.Hour, "How far back to scan for withdrawals when --from-block isn't set")
+	fs.Uint64Var(&blockRange, "block-range", 5000, "Maximum number of L2 blocks to query per eth_getLogs request")
+	fs.StringVar(&format, "format", "markdown", "Report format (one of: markdown, json)")
+	fs.StringVar(&out, "out", "", "File to write the report to (default: stdout)")
+	_ = fs.Parse(args)
+
+	accounts, extraProvers := parseAddresses(senders), parseAddresses(provers)
+	if len(accounts) == 0 {
+		log.Crit("Missing --senders flag")
+	}
+	if format != "markdown" && format != "json" {
+		log.Crit("Invalid --format, expected markdown or json", "value", format)
+	}
+	if blockRange == 0 {
+		log.Crit("--block-range must be at least 1")
+	}
+	n := f.resolveNetwork()
+	ctx := interruptContext()
+
+	// the read-only helpers of all withdrawals share the clients
+	clients, err := dialHelperClients(ctx, f.rpc, n, nil, f.helperConfig(nil))
+	if err != nil {
+		log.Crit("Error dialing clients", "error", err)
+	}
+	defer clients.Close()
+	l2 := ethclient.NewClient(clients.l2)
+	toBlock, err := l2.BlockNumber(ctx)
+	if err != nil {
+		log.Crit("Error querying L2 head", "error", err)
+	}
+	if fromBlock == 0 {
+		fromBlock, err = withdraw.BlockAtTime(ctx, l2, uint64(time.Now().Add(-lookback).Unix()))
+		if err != nil {
+			log.Crit("Error finding L2 start block", "error", err)
+		}
+	}
+
+	initiated, err := withdraw.InitiatedWithdrawals(ctx, l2, accounts, fromBlock, toBlock, blockRange)
+	if err != nil {
+		log.Crit("Error scanning withdrawals", "error", err)
+	}
+	report := queueReport{
+		SchemaVersion: schemaVersion,
+		Network:       f.network,
+		GeneratedAt:   time.Now().UTC(),
+		Accounts:      accounts,
+		FromBlock:     fromBlock,
+		ToBlock:       toBlock,
+		Withdrawals:   []queueEntry{},
+		explorers:     n.explorers(),
+	}
+	for _, w := range initiated {
+		e := newQueueEntry(w, report.GeneratedAt, report.explorers)
+		// with Fault Proofs, proofs are recorded per submitter, so they are looked for from the accounts
+		// that could have proven the withdrawal
+		var from []common.Address
+		if n.FaultProofs {
+			from = append([]common.Address{w.Account}, extraProvers...)
+		}
+		finalized := queueStage(ctx, clients, f, n, &e, from)
+		if finalized {
+			report.Finalized++
+			continue
+		}
+		report.Withdrawals = append(report.Withdrawals, e)
+	}
+	sort.SliceStable(report.Withdrawals, func(i, j int) bool {
+		a, b := report.Withdrawals[i].ActionableAt, report.Withdrawals[j].ActionableAt
+		return a != nil && (b == nil || a.Before(*b))
+	})
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		file, err := os.Create(out)
+		if err != nil {
+			log.Crit("Error creating report file", "error", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if format == "json" {
+		err = json.NewEncoder(w).Encode(report)
+	} else {
+		err = report.writeMarkdown(w)
+	}
+	if err != nil {
+		log.Crit("Error writing report", "error", err)
+	}
+}
+
+func newQueueEntry(w withdraw.InitiatedWithdrawal, now time.Time, explorers notify.Explorers) queueEntry {
+	e := queueEntry{
+		Withdrawal:  w.TxHash,
+		URL:         explorers.L2TxURL(w.TxHash),
+		Account:     w.Account,
+		Value:       w.Value.String(),
+		InitiatedAt: w.Time,
+		AgeSeconds:  int64(now.Sub(w.Time) / time.Second),
+	}
+	if b := w.Route.Bridged; b != nil && b.Token != (common.Address{}) {
+		token := b.Token
+		e.Token, e.Amount = &token, b.Amount.String()
+	}
+	return e
+}
+
+// queueStage sets the stage of the withdrawal of e, what blocks its next action and when it can be
+// taken, and returns whether the withdrawal is already finalized. Fault proofs are looked for from each
+// of provers, with helpers using clients. Errors querying the withdrawal are reported as what blocks it.
+func queueStage(ctx context.Context, clients *helperClients, f *flags, n network, e *queueEntry, provers []common.Address) bool {
+	e.Stage, e.NextAction = "initiated", "prove"
+	hc := f.helperConfig(nil)
+	if len(provers) == 0 {
+		provers = []common.Address{{}}
+	}
+	var helper withdraw.WithdrawHelper
+	for i, prover := range provers {
+		hc.from = prover
+		h, err := newWithdrawHelper(ctx, clients, e.Withdrawal, n, nil, hc)
+		if err != nil {
+			e.BlockedBy = fmt.Sprintf("error creating withdrawer: %v", err)
+			return false
+		}
+		if i == 0 {
+			helper = h
+			finalized, err := h.IsProofFinalized(ctx)
+			if err != nil {
+				e.BlockedBy = fmt.Sprintf("error querying finalization status: %v", err)
+				return false
+			}
+			if finalized {
+				return true
+			}
+		}
+		proofTime, err := h.GetProvenWithdrawalTime(ctx)
+		if err != nil {
+			e.BlockedBy = fmt.Sprintf("error querying proof: %v", err)
+			return false
+		}
+		if proofTime > 0 {
+			e.Stage, e.NextAction = "proven", "finalize"
+			if n.FaultProofs {
+				e.ProvenBy = &provers[i]
+			}
+			helper = h
+			break
+		}
+	}
+
+	check := helper.CheckIfProvable
+	if e.Stage == "proven" {
+		check = helper.CheckIfFinalizable
+	}
+	if err := check(ctx); err != nil {
+		e.BlockedBy = err.Error()
+		if scheduler, ok := helper.(withdraw.Scheduler); ok {
+			estimate := scheduler.EarliestProveTime
+			if e.Stage == "proven" {
+				estimate = scheduler.EarliestFinalizeTime
+			}
+			e.ActionableAt = nextRunAt(ctx, estimate)
+		}
+		return false
+	}
+	now := time.Now().UTC()
+	e.ActionableAt = &now
+	return false
+}
+
+// writeMarkdown writes the report as Markdown, with a table of the withdrawals, for pasting into
+// reports and ops summaries.
+func (r queueReport) writeMarkdown(w io.Writer) error {
+	var toProve, toFinalize, actionable int
+	for _, e := range r.Withdrawals {
+		if e.NextAction == "prove" {
+			toProve++
+		} else {
+			toFinalize++
+		}
+		if e.BlockedBy == "" {
+			actionable++
+		}
+	}
+	accounts := make([]string, len(r.Accounts))
+	for i, a := range r.Accounts {
+		accounts[i] = "`" + a.Hex() + "`"
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "# Pending withdrawals\n\n")
+	fmt.Fprintf(b, "- Network: %s\n", r.Network)
+	fmt.Fprintf(b, "- Generated: %s\n", r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(b, "- Accounts: %s\n", strings.Join(accounts, ", "))
+	fmt.Fprintf(b, "- L2 blocks: %d to %d\n", r.FromBlock, r.ToBlock)
+	fmt.Fprintf(b, "- In flight: %d (%d to prove, %d to finalize, %d actionable now), %d finalized left out\n",
+		len(r.Withdrawals), toProve, toFinalize, actionable, r.Finalized)
+	if len(r.Withdrawals) > 0 {
+		fmt.Fprintf(b, "\n| Withdrawal | Account | Amount | Age | Stage | Next action | Blocked by | Actionable |\n| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+		for _, e := range r.Withdrawals {
+			fmt.Fprintf(b, "| %s | `%s` | %s | %s | %s | %s | %s | %s |\n", markdownLink(e.Withdrawal, e.URL), e.Account.Hex(),
+				e.amount(), countdown(time.Duration(e.AgeSeconds)*time.Second), e.Stage, e.NextAction, markdownCell(e.BlockedBy), e.actionable(r.GeneratedAt))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (e queueEntry) amount() string {
+	if e.Token != nil {
+		return fmt.Sprintf("%s of `%s`", e.Amount, e.Token.Hex())
+	}
+	wei, _ := new(big.Int).SetString(e.Value, 10)
+	return price.FormatETH(context.Background(), nil, wei)
+}
+
+func (e queueEntry) actionable(now time.Time) string {
+	switch {
+	case e.BlockedBy == "":
+		return "now"
+	case e.ActionableAt == nil:
+		return "unknown"
+	}
+	s := e.ActionableAt.Format("2006-01-02 15:04 MST")
+	if d := e.ActionableAt.Sub(now); d >= time.Minute {
+		s += fmt.Sprintf(" (in %s)", countdown(d))
+	}
+	return s
+}
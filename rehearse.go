@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/base-org/withdrawer/price"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// rehearsal runs the prove and finalize steps of a withdrawal against an Anvil fork of L1, sending the
+// transactions as the impersonated signer, so that their outcome is known before anything is sent to
+// the real chain.
+type rehearsal struct {
+	fork   *rpc.Client
+	client *ethclient.Client
+	from   common.Address
+	// receipt and err are the outcome of the last transaction sent to the fork.
+	receipt *types.Receipt
+	err     error
+}
+
+// runRehearsal rehearses the withdrawal on a fork of the L1 of n, started with anvil unless anvilURL is
+// set, as from, and prints the expected outcome of each step.
+func runRehearsal(ctx context.Context, f *flags, n network, withdrawal common.Hash, from common.Address, anvilURL string) {
+	if anvilURL == "" {
+		url, stop, err := startAnvil(ctx, strings.Split(f.rpc, ",")[0])
+		if err != nil {
+			log.Crit("Error starting anvil, install Foundry or pass the URL of a running fork with --anvil-url", "error", err)
+		}
+		defer stop()
+		anvilURL = url
+	}
+	fork, err := rpc.DialContext(ctx, anvilURL)
+	if err != nil {
+		log.Crit("Error dialing the fork", "error", err)
+	}
+	r := &rehearsal{fork: fork, client: ethclient.NewClient(fork), from: from}
+	if err := fork.CallContext(ctx, nil, "anvil_impersonateAccount", from); err != nil {
+		log.Crit("Error impersonating the signer on the fork", "error", err)
+	}
+
+	// the light client and the verification provider follow the real chain, which the fork diverges from
+	n.l1VerifiedRPC, n.verifyRPC = "", ""
+	hc := f.helperConfig(nil)
+	hc.from, hc.buildOnly = from, r.send
+	helper, err := CreateWithdrawHelper(ctx, anvilURL, withdrawal, n, nil, hc)
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+	fmt.Printf("Rehearsing withdrawal %s as %s on a fork of L1, nothing is sent to %s\n", withdrawal, from, f.network)
+
+	finalized, err := helper.IsProofFinalized(ctx)
+	if err != nil {
+		exitWithError("Error querying withdrawal finalization status", err)
+	}
+	if finalized {
+		fmt.Println("The withdrawal is already finalized, there is nothing to rehearse")
+		return
+	}
+	proofTime, err := helper.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		exitWithError("Error querying withdrawal proof", err)
+	}
+	if proofTime == 0 {
+		if err := helper.CheckIfProvable(ctx); err != nil {
+			exitWithError("Rehearsal stopped, the withdrawal is not provable", err)
+		}
+		r.step(ctx, "prove", helper.ProveWithdrawal)
+	} else {
+		fmt.Println("The withdrawal is already proven by the signer")
+	}
+
+	if err := r.warp(ctx, helper, n); err != nil {
+		exitWithError("Error fast-forwarding the fork past the challenge window", err)
+	}
+	r.step(ctx, "finalize", helper.FinalizeWithdrawal)
+	fmt.Println("Rehearsal succeeded: the withdrawal can be proven and finalized with these settings")
+}
+
+// send implements the buildOnly hook of the helper, sending the built transaction to the fork from the
+// impersonated signer and waiting for its receipt.
+func (r *rehearsal) send(_ common.Hash, _ string, tx *types.Transaction) {
+	r.receipt, r.err = r.sendTx(context.Background(), tx.To(), tx.Data(), tx.Value(), tx.Gas())
+}
+
+// sendTx sends a transaction from the impersonated signer, which the fork mines right away.
+func (r *rehearsal) sendTx(ctx context.Context, to *common.Address, data []byte, value *big.Int, gas uint64) (*types.Receipt, error) {
+	args := map[string]any{"from": r.from, "to": to, "data": hexutil.Bytes(data), "value": (*hexutil.Big)(value)}
+	if gas > 0 {
+		args["gas"] = hexutil.Uint64(gas)
+	}
+	var hash common.Hash
+	if err := r.fork.CallContext(ctx, &hash, "eth_sendTransaction", args); err != nil {
+		return nil, err
+	}
+	for i := 0; ; i++ {
+		receipt, err := r.client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("tx %s reverted", hash)
+			}
+			return receipt, nil
+		}
+		if i == 50 {
+			return nil, fmt.Errorf("tx %s was not mined by the fork: %w", hash, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// step rehearses the action and prints its outcome, exiting if it failed.
+func (r *rehearsal) step(ctx context.Context, action string, run func(context.Context) (common.Hash, error)) {
+	r.receipt, r.err = nil, nil
+	if _, err := run(ctx); err != nil {
+		exitWithError(fmt.Sprintf("Rehearsal failed, the %s tx would fail", action), err)
+	}
+	if r.err != nil {
+		exitWithError(fmt.Sprintf("Rehearsal failed, the %s tx failed on the fork", action), r.err)
+	}
+	fee := new(big.Int).SetUint64(r.receipt.GasUsed)
+	if r.receipt.EffectiveGasPrice != nil {
+		fee.Mul(fee, r.receipt.EffectiveGasPrice)
+	}
+	fmt.Printf("Rehearsed %s: succeeded, %d gas used, %s in fees at current prices\n", action, r.receipt.GasUsed, price.FormatETH(ctx, nil, fee))
+}
+
+// warp fast-forwards the fork until the proven withdrawal has matured, resolving the dispute game it
+// was proven against on the way if it is unchallenged.
+func (r *rehearsal) warp(ctx context.Context, helper withdraw.WithdrawHelper, n network) error {
+	scheduler, ok := helper.(withdraw.Scheduler)
+	if !ok {
+		return errors.New("the finalization time of the withdrawal can't be estimated")
+	}
+	// the finality delay of a dispute game only starts once it is resolved, which takes a second warp
+	for i := 0; i < 2; i++ {
+		at, err := scheduler.EarliestFinalizeTime(ctx)
+		if err != nil {
+			return err
+		}
+		if err := fastForward(ctx, r.fork, at); err != nil {
+			return err
+		}
+		if !n.FaultProofs {
+			return nil
+		}
+		send := func(ctx context.Context, to common.Address, data []byte) error {
+			_, err := r.sendTx(ctx, &to, data, new(big.Int), 0)
+			return err
+		}
+		if err := resolveGame(ctx, r.client, helper, n.PortalAddress, r.from, send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fastForward mines a block on the L1 node of an Anvil fork or devnet with a timestamp past at, unless
+// its latest block is already past it.
+func fastForward(ctx context.Context, l1 *rpc.Client, at time.Time) error {
+	head, err := ethclient.NewClient(l1).HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if at.Unix() < int64(head.Time) {
+		return nil
+	}
+	fmt.Printf("Fast-forwarding L1 to %s\n", at.UTC().Format(time.RFC3339))
+	if err := l1.CallContext(ctx, nil, "evm_setNextBlockTimestamp", at.Unix()+1); err != nil {
+		return err
+	}
+	return l1.CallContext(ctx, nil, "evm_mine")
+}
+
+// resolveGame resolves the dispute game the withdrawal was proven against by submitter, if it hasn't
+// resolved yet, sending the resolving transactions with send. Only unchallenged games can be resolved
+// this way.
+func resolveGame(ctx context.Context, l1 *ethclient.Client, helper withdraw.WithdrawHelper, portalAddress, submitter common.Address, send func(ctx context.Context, to common.Address, data []byte) error) error {
+	hash, err := helper.WithdrawalHash(ctx)
+	if err != nil {
+		return err
+	}
+	portal, err := bindingspreview.NewOptimismPortal2Caller(portalAddress, l1)
+	if err != nil {
+		return err
+	}
+	proven, err := portal.ProvenWithdrawals(&bind.CallOpts{Context: ctx}, hash, submitter)
+	if err != nil {
+		return fmt.Errorf("error querying proven withdrawal: %w", err)
+	}
+	gameABI := snapshots.LoadFaultDisputeGameABI()
+	game := bind.NewBoundContract(proven.DisputeGameProxy, *gameABI, l1, nil, nil)
+	var out []any
+	if err := game.Call(&bind.CallOpts{Context: ctx}, &out, "resolvedAt"); err != nil {
+		return fmt.Errorf("error querying dispute game: %w", err)
+	}
+	if resolvedAt, _ := out[0].(uint64); resolvedAt != 0 {
+		return nil
+	}
+
+	fmt.Printf("Resolving dispute game %s\n", proven.DisputeGameProxy)
+	for _, call := range [][]any{{"resolveClaim", big.NewInt(0), big.NewInt(0)}, {"resolve"}} {
+		data, err := gameABI.Pack(call[0].(string), call[1:]...)
+		if err != nil {
+			return err
+		}
+		if err := send(ctx, proven.DisputeGameProxy, data); err != nil {
+			return fmt.Errorf("error resolving dispute game %s, it may be challenged: %w", proven.DisputeGameProxy, err)
+		}
+	}
+	return nil
+}
+
+// startAnvil starts anvil forking the L1 at forkURL on a free port, returning its URL and a function
+// stopping it.
+func startAnvil(ctx context.Context, forkURL string) (string, func(), error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cmd := exec.Command("anvil", "--fork-url", forkURL, "--port", fmt.Sprint(port), "--silent")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return "", nil, err
+	}
+	stop := func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	fmt.Println("Starting an anvil fork of L1...")
+	deadline := time.Now().Add(time.Minute)
+	for {
+		var chainID hexutil.Big
+		client, err := rpc.DialContext(ctx, url)
+		if err == nil {
+			err = client.CallContext(ctx, &chainID, "eth_chainId")
+			client.Close()
+		}
+		if err == nil {
+			return url, stop, nil
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			stop()
+			return "", nil, fmt.Errorf("anvil did not start: %w", err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/relayer"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runRelay implements the relay subcommand, which finalizes anyone's proven and matured withdrawals.
+func runRelay(args []string) {
+	fs := flag.NewFlagSet("relay", flag.ExitOnError)
+	f := registerFlags(fs)
+	var cfg relayer.Config
+	var senders, targets, minValue string
+	var lookback time.Duration
+	fs.StringVar(&senders, "senders", "", "Comma-separated L2 sender addresses to finalize withdrawals of (default: any)")
+	fs.StringVar(&targets, "targets", "", "Comma-separated L1 target addresses to finalize withdrawals to (default: any)")
+	fs.StringVar(&minValue, "min-value", "0", "Minimum ETH value, in wei, of withdrawals to finalize")
+	fs.Uint64Var(&cfg.FromBlock, "from-block", 0, "L1 block to start scanning for proven withdrawals from (default: --lookback before now)")
+	fs.DurationVar(&lookback, "lookback", 14*24*time.Hour, "How far back to scan for proven withdrawals when --from-block isn't set")
+	fs.Uint64Var(&cfg.ChunkSize, "block-range", 10000, "Maximum number of L1 blocks to query per eth_getLogs request")
+	fs.DurationVar(&cfg.Interval, "interval", time.Minute, "Interval between polls for finalizable withdrawals")
+	_ = fs.Parse(args)
+
+	if cfg.ChunkSize == 0 {
+		log.Crit("--block-range must be at least 1")
+	}
+	if f.nonce != "" {
+		log.Crit("--nonce can't be used with the relay command, as it sends many transactions")
+	}
+	if f.txMgr {
+		log.Crit("--txmgr isn't supported by the relay command")
+	}
+	if f.noWait {
+		log.Crit("--no-wait isn't supported by the relay command")
+	}
+	if f.privateRPC != "" {
+		log.Crit("--private-rpc isn't supported by the relay command")
+	}
+
+	n := f.resolveNetwork()
+	s := f.createSigner()
+	ctx := interruptContext()
+
+	cfg.FaultProofs = n.FaultProofs
+	cfg.GasBuffer = f.gasBuffer
+	cfg.SpendCap = f.sharedSpendCap()
+	cfg.Filter.Senders = parseAddresses(senders)
+	cfg.Filter.Targets = parseAddresses(targets)
+	var ok bool
+	cfg.Filter.MinValue, ok = new(big.Int).SetString(minValue, 10)
+	if !ok {
+		log.Crit("Invalid --min-value", "value", minValue)
+	}
+
+	l1Client, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	l1ChainID, err := l1Client.ChainID(ctx)
+	if err != nil {
+		log.Crit("Error querying chain ID", "error", err)
+	}
+
+	if cfg.FromBlock == 0 {
+		cfg.FromBlock, err = withdraw.BlockAtTime(ctx, l1Client, uint64(time.Now().Add(-lookback).Unix()))
+		if err != nil {
+			log.Crit("Error finding L1 start block", "error", err)
+		}
+	}
+
+	// the nonce is left unset, so it is queried for every finalization
+	opts := &bind.TransactOpts{
+		From:    s.Address(),
+		Signer:  s.SignerFn(l1ChainID),
+		Context: ctx,
+	}
+	if err := f.helperConfig(nil).applyTo(ctx, l1Client, opts); err != nil {
+		log.Crit("Error configuring transactions", "error", err)
+	}
+	r, err := relayer.New(l1Client, n.PortalAddress, opts, cfg)
+	if err != nil {
+		log.Crit("Error creating relayer", "error", err)
+	}
+
+	log.Info("Starting relayer", "portal", n.PortalAddress, "fromBlock", cfg.FromBlock, "interval", cfg.Interval)
+	if err := r.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		log.Crit("Relayer stopped", "error", err)
+	}
+	log.Info("Relayer stopped")
+}
+
+// parseAddresses parses a comma-separated list of addresses.
+func parseAddresses(list string) []common.Address {
+	var addrs []common.Address
+	for _, a := range strings.Split(list, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if !common.IsHexAddress(a) {
+			log.Crit("Invalid address", "address", a)
+		}
+		addrs = append(addrs, common.HexToAddress(a))
+	}
+	return addrs
+}
@@ -0,0 +1,327 @@
+// Package relayer finalizes any withdrawal that has been proven and has matured, regardless of who
+// initiated it, so that teams can run a public-goods finalizer for their users.
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// Filter restricts which withdrawals are finalized. Empty lists match any address. Senders and targets
+// match the withdrawal's own sender and target, and the accounts it is attributed to if it was routed
+// through the messenger or standard bridge, e.g. by an aggregator.
+type Filter struct {
+	Senders  []common.Address
+	Targets  []common.Address
+	MinValue *big.Int
+}
+
+func (f Filter) matches(tx bindings.TypesWithdrawalTransaction) bool {
+	route := withdraw.DecodeRoute(tx.Sender, tx.Target, tx.Data)
+	if len(f.Senders) > 0 && !contains(f.Senders, tx.Sender) && !contains(f.Senders, route.From()) {
+		return false
+	}
+	if len(f.Targets) > 0 && !contains(f.Targets, tx.Target) && !contains(f.Targets, route.To()) {
+		return false
+	}
+	return f.MinValue == nil || tx.Value.Cmp(f.MinValue) >= 0
+}
+
+func contains(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Config configures the relayer.
+type Config struct {
+	// FaultProofs selects the OptimismPortal2 finalization flow, in which proofs are stored per submitter.
+	FaultProofs bool
+	// FromBlock is the L1 block to start scanning for WithdrawalProven events from.
+	FromBlock uint64
+	// ChunkSize is the maximum number of L1 blocks to query per eth_getLogs request.
+	ChunkSize uint64
+	// Interval is the time between polls for new proofs and finalizable withdrawals.
+	Interval time.Duration
+	Filter   Filter
+	// GasBuffer, if above 1, multiplies the gas estimates of finalization transactions.
+	GasBuffer float64
+	// SpendCap, if set, caps the fees paid for finalizations.
+	SpendCap *withdraw.SpendCap
+}
+
+// candidate is a proven withdrawal that has not been finalized yet.
+type candidate struct {
+	hash      common.Hash
+	tx        bindings.TypesWithdrawalTransaction
+	submitter common.Address
+}
+
+// Relayer finalizes proven withdrawals that match its filter once they have matured.
+type Relayer struct {
+	l1            *ethclient.Client
+	portalAddress common.Address
+	portal        *bindings.OptimismPortal
+	portalABI     *abi.ABI
+	portal2ABI    *abi.ABI
+	opts          *bind.TransactOpts
+	cfg           Config
+
+	next       uint64
+	candidates map[common.Hash]*candidate
+}
+
+// New creates a Relayer finalizing withdrawals proven on the portal at portalAddress, sending
+// transactions with opts.
+func New(l1 *ethclient.Client, portalAddress common.Address, opts *bind.TransactOpts, cfg Config) (*Relayer, error) {
+	if cfg.ChunkSize == 0 {
+		return nil, errors.New("chunk size must be at least 1")
+	}
+	// the events and finalizedWithdrawals are identical in OptimismPortal and OptimismPortal2
+	portal, err := bindings.NewOptimismPortal(portalAddress, l1)
+	if err != nil {
+		return nil, err
+	}
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	portal2ABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return &Relayer{
+		l1:            l1,
+		portalAddress: portalAddress,
+		portal:        portal,
+		portalABI:     portalABI,
+		portal2ABI:    portal2ABI,
+		opts:          opts,
+		cfg:           cfg,
+		next:          cfg.FromBlock,
+		candidates:    make(map[common.Hash]*candidate),
+	}, nil
+}
+
+// Run polls for proven withdrawals and finalizes them every interval until ctx is canceled.
+func (r *Relayer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Poll(ctx); err != nil {
+			log.Error("Error polling withdrawals", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll picks up withdrawals proven since the last poll and finalizes all candidates that have matured.
+func (r *Relayer) Poll(ctx context.Context) error {
+	if err := r.scan(ctx); err != nil {
+		return err
+	}
+
+	for hash, c := range r.candidates {
+		finalized, err := r.portal.FinalizedWithdrawals(&bind.CallOpts{Context: ctx}, hash)
+		if err != nil {
+			log.Warn("Error querying finalization status", "withdrawal", hash, "error", err)
+			continue
+		}
+		if finalized {
+			log.Debug("Withdrawal finalized elsewhere", "withdrawal", hash)
+			delete(r.candidates, hash)
+			continue
+		}
+
+		data, err := r.finalizeCalldata(c)
+		if err != nil {
+			return err
+		}
+		if _, err := r.l1.CallContract(ctx, ethereum.CallMsg{From: r.opts.From, To: &r.portalAddress, Data: data}, nil); err != nil {
+			// not matured yet, or the game it was proven against was invalidated
+			log.Debug("Withdrawal not finalizable", "withdrawal", hash, "reason", err)
+			continue
+		}
+
+		if err := r.finalize(ctx, c, data); err != nil {
+			log.Error("Error finalizing withdrawal", "withdrawal", hash, "error", err)
+			continue
+		}
+		delete(r.candidates, hash)
+	}
+	return nil
+}
+
+// scan adds the withdrawals proven in blocks since the last scan that match the filter to the candidates.
+func (r *Relayer) scan(ctx context.Context) error {
+	head, err := r.l1.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying L1 head: %w", err)
+	}
+
+	for start := r.next; start <= head; start += r.cfg.ChunkSize {
+		end := start + r.cfg.ChunkSize - 1
+		if end > head {
+			end = head
+		}
+		proven, err := r.portal.FilterWithdrawalProven(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil, nil, nil)
+		if err != nil {
+			return fmt.Errorf("error filtering WithdrawalProven events in blocks %d-%d: %w", start, end, err)
+		}
+		for proven.Next() {
+			hash := common.Hash(proven.Event.WithdrawalHash)
+			c, err := r.candidateFromProof(ctx, hash, proven.Event.Raw.TxHash)
+			if err != nil {
+				log.Warn("Skipping proven withdrawal", "withdrawal", hash, "proveTx", proven.Event.Raw.TxHash, "reason", err)
+				continue
+			}
+			if !r.cfg.Filter.matches(c.tx) {
+				continue
+			}
+			route := withdraw.DecodeRoute(c.tx.Sender, c.tx.Target, c.tx.Data)
+			log.Info("Found proven withdrawal", "withdrawal", hash, "from", route.From(), "to", route.To(), "value", c.tx.Value, "submitter", c.submitter)
+			r.candidates[hash] = c
+		}
+		if err := proven.Error(); err != nil {
+			return err
+		}
+		proven.Close()
+		r.next = end + 1
+	}
+	return nil
+}
+
+// errIndirectProof is returned for proofs submitted through another contract, whose withdrawal
+// parameters can't be recovered from the transaction calldata.
+var errIndirectProof = errors.New("proof was not submitted directly to the portal")
+
+// candidateFromProof recovers the withdrawal transaction and proof submitter from the L1 transaction
+// that proved the withdrawal with the given hash.
+func (r *Relayer) candidateFromProof(ctx context.Context, hash, proveTx common.Hash) (*candidate, error) {
+	tx, _, err := r.l1.TransactionByHash(ctx, proveTx)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tx: %w", err)
+	}
+	if tx.To() == nil || *tx.To() != r.portalAddress || len(tx.Data()) < 4 {
+		return nil, errIndirectProof
+	}
+
+	var args []interface{}
+	for _, parsed := range []*abi.ABI{r.portalABI, r.portal2ABI} {
+		method, err := parsed.MethodById(tx.Data()[:4])
+		if err != nil || method.Name != "proveWithdrawalTransaction" {
+			continue
+		}
+		if args, err = method.Inputs.Unpack(tx.Data()[4:]); err == nil {
+			break
+		}
+	}
+	if len(args) == 0 {
+		return nil, errIndirectProof
+	}
+	wtx := *abi.ConvertType(args[0], new(bindings.TypesWithdrawalTransaction)).(*bindings.TypesWithdrawalTransaction)
+
+	computed, err := withdrawals.WithdrawalHash(&bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    wtx.Nonce,
+		Sender:   wtx.Sender,
+		Target:   wtx.Target,
+		Value:    wtx.Value,
+		GasLimit: wtx.GasLimit,
+		Data:     wtx.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if computed != hash {
+		// e.g. a batch of proofs in a single transaction
+		return nil, errIndirectProof
+	}
+
+	submitter, err := types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return nil, fmt.Errorf("error recovering proof submitter: %w", err)
+	}
+	return &candidate{hash: hash, tx: wtx, submitter: submitter}, nil
+}
+
+// finalizeCalldata packs the finalization call for c. With fault proofs, proofs are stored per
+// submitter, so withdrawals proven by someone else are finalized against the submitter's proof.
+func (r *Relayer) finalizeCalldata(c *candidate) ([]byte, error) {
+	if !r.cfg.FaultProofs {
+		return r.portalABI.Pack("finalizeWithdrawalTransaction", c.tx)
+	}
+	tx := bindingspreview.TypesWithdrawalTransaction(c.tx)
+	if c.submitter == r.opts.From {
+		return r.portal2ABI.Pack("finalizeWithdrawalTransaction", tx)
+	}
+	return r.portal2ABI.Pack("finalizeWithdrawalTransactionExternalProof", tx, c.submitter)
+}
+
+func (r *Relayer) finalize(ctx context.Context, c *candidate, data []byte) error {
+	opts := *r.opts
+	opts.Context = ctx
+	// the transaction is built first, to check its maximum fee against the spending cap
+	opts.NoSend = true
+	tx, err := bind.NewBoundContract(r.portalAddress, abi.ABI{}, r.l1, withdraw.WithGasBuffer(r.l1, r.cfg.GasBuffer), r.l1).RawTransact(&opts, data)
+	if err != nil {
+		return err
+	}
+	var reserved *big.Int
+	if r.cfg.SpendCap != nil {
+		if reserved, err = r.cfg.SpendCap.Reserve(tx); err != nil {
+			return err
+		}
+	}
+	if err := r.l1.SendTransaction(ctx, tx); err != nil {
+		if r.cfg.SpendCap != nil {
+			r.cfg.SpendCap.Release(reserved)
+		}
+		return err
+	}
+	log.Info("Submitted finalization", "withdrawal", c.hash, "tx", tx.Hash())
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, r.l1, tx)
+	if err != nil {
+		return err
+	}
+	if r.cfg.SpendCap != nil {
+		r.cfg.SpendCap.Settle(reserved, new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice))
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("finalization tx %s reverted", tx.Hash())
+	}
+	for _, l := range receipt.Logs {
+		if l.Address != r.portalAddress {
+			continue
+		}
+		if ev, err := r.portal.ParseWithdrawalFinalized(*l); err == nil && ev.WithdrawalHash == c.hash {
+			log.Info("Finalized withdrawal", "withdrawal", c.hash, "tx", tx.Hash(), "success", ev.Success)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runSpeedUp implements the speed-up subcommand, which re-sends a stuck prove or finalize transaction
+// with higher fees.
+func runSpeedUp(args []string) {
+	runReplace("speed-up", args, false)
+}
+
+// runCancel implements the cancel subcommand, which replaces a stuck prove or finalize transaction
+// with an empty self-transfer.
+func runCancel(args []string) {
+	runReplace("cancel", args, true)
+}
+
+// runReplace replaces the pending transaction recorded in the journal for the withdrawal.
+func runReplace(name string, args []string, cancel bool) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	f := registerFlags(fs)
+	var action string
+	fs.StringVar(&action, "action", "", "Action whose pending transaction to replace (one of: prove, finalize; default: whichever has a pending transaction)")
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	s := f.createSigner()
+	cfg := f.helperConfig(nil)
+	ctx := interruptContext()
+
+	journal := f.openJournal()
+	defer journal.Store.Close()
+
+	l1Client, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+	l1ChainID, err := l1Client.ChainID(ctx)
+	if err != nil {
+		log.Crit("Error querying chain ID", "error", err)
+	}
+
+	actions := []string{"finalize", "prove"}
+	if action != "" {
+		if action != "prove" && action != "finalize" {
+			log.Crit("Invalid --action", "action", action)
+		}
+		actions = []string{action}
+	}
+	var pending common.Hash
+	for _, a := range actions {
+		pending, err = journal.PendingTx(withdrawal, a)
+		if err != nil {
+			log.Crit("Error reading journal", "error", err)
+		}
+		if pending != (common.Hash{}) {
+			action = a
+			break
+		}
+	}
+	if pending == (common.Hash{}) {
+		log.Crit("No transaction recorded in the journal for the withdrawal", "withdrawal", withdrawal, "journal", f.journal)
+	}
+
+	// the fee flags override the bumped fees, as long as they are high enough to replace the transaction
+	opts := &bind.TransactOpts{
+		From:      s.Address(),
+		Signer:    s.SignerFn(l1ChainID),
+		Context:   ctx,
+		GasFeeCap: cfg.gasFeeCap,
+		GasTipCap: cfg.gasTipCap,
+		GasPrice:  cfg.gasPrice,
+	}
+	tx, err := withdraw.ReplaceTx(ctx, l1Client, opts, pending, cancel)
+	if err != nil {
+		log.Crit("Error replacing transaction", "tx", pending, "error", err)
+	}
+
+	if cancel {
+		// the canceled action is sent again by the next run, as its recorded transaction won't be mined
+		fmt.Printf("Sent cancellation %s of %s tx %s\n", withLink(tx.Hash(), n.explorers().L1TxURL(tx.Hash())), action, pending.String())
+	} else {
+		if err := journal.RecordTx(withdrawal, action, tx.Hash()); err != nil {
+			log.Warn("Failed to record replacement in the journal", "tx", tx.Hash(), "error", err)
+		}
+		fmt.Printf("Sent replacement %s of %s tx %s\n", withLink(tx.Hash(), n.explorers().L1TxURL(tx.Hash())), action, pending.String())
+	}
+	if f.noWait {
+		printSentTx(n.explorers(), withdrawal, action, tx.Hash())
+		return
+	}
+
+	// either the replacement or, if it won the race, the original transaction gets mined
+	for {
+		for _, h := range []common.Hash{tx.Hash(), pending} {
+			receipt, err := l1Client.TransactionReceipt(ctx, h)
+			if err == nil {
+				fmt.Printf("%s mined in block %d\n", h.String(), receipt.BlockNumber.Uint64())
+				return
+			} else if !errors.Is(err, ethereum.NotFound) {
+				log.Crit("Error waiting for replacement", "tx", h, "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			log.Crit("Interrupted while waiting for replacement", "tx", tx.Hash())
+		case <-time.After(cfg.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/price"
+)
+
+// batchReport is the Markdown report of a batch run written with --report, for pasting into incident
+// docs or ops summaries.
+type batchReport struct {
+	network   string
+	explorers notify.Explorers
+	started   time.Time
+	finished  time.Time
+	results   []batchResult
+}
+
+// write writes the report, with a table of the withdrawals of each outcome.
+func (r batchReport) write(w io.Writer) error {
+	var proven, finalized, skipped, failed []batchResult
+	for _, res := range r.results {
+		switch {
+		case res.failed:
+			failed = append(failed, res)
+		case res.Action == "prove":
+			proven = append(proven, res)
+		case res.Action == "finalize":
+			finalized = append(finalized, res)
+		default:
+			skipped = append(skipped, res)
+		}
+	}
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "# Withdrawal report\n\n")
+	fmt.Fprintf(b, "- Network: %s\n", r.network)
+	fmt.Fprintf(b, "- Run: %s to %s\n", r.started.UTC().Format(time.RFC3339), r.finished.UTC().Format(time.RFC3339))
+	fmt.Fprintf(b, "- Withdrawals: %d (%d proven, %d finalized, %d skipped, %d failed)\n",
+		len(r.results), len(proven), len(finalized), len(skipped), len(failed))
+
+	if len(proven) > 0 {
+		fmt.Fprintf(b, "\n## Proven\n\n| Withdrawal | Prove tx | Gas used |\n| --- | --- | --- |\n")
+		for _, res := range proven {
+			fmt.Fprintf(b, "| %s | %s | %s |\n", r.l2Link(res.Withdrawal), r.l1Link(res.Tx), gasUsedCell(res))
+		}
+	}
+	if len(finalized) > 0 {
+		fmt.Fprintf(b, "\n## Finalized\n\n| Withdrawal | Finalize tx | Gas used |\n| --- | --- | --- |\n")
+		for _, res := range finalized {
+			fmt.Fprintf(b, "| %s | %s | %s |\n", r.l2Link(res.Withdrawal), r.l1Link(res.Tx), gasUsedCell(res))
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(b, "\n## Skipped\n\n| Withdrawal | Status | Reason | Next run |\n| --- | --- | --- | --- |\n")
+		for _, res := range skipped {
+			next := ""
+			if res.NextRunAt != nil {
+				next = res.NextRunAt.UTC().Format("2006-01-02 15:04 MST")
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", r.l2Link(res.Withdrawal), res.Status, markdownCell(res.Error), next)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(b, "\n## Failed\n\n| Withdrawal | Error |\n| --- | --- |\n")
+		for _, res := range failed {
+			fmt.Fprintf(b, "| %s | %s |\n", r.l2Link(res.Withdrawal), markdownCell(res.Error))
+		}
+	}
+	if spends := gasSpends(r.results); spends[len(spends)-1].txs > 0 {
+		fmt.Fprintf(b, "\n## Gas\n\n| Transactions | Count | Gas used | Fees | Average fee |\n| --- | --- | --- | --- | --- |\n")
+		for _, s := range spends {
+			if s.txs > 0 {
+				fmt.Fprintf(b, "| %s | %d | %d | %s | %s |\n", s.label, s.txs, s.gasUsed,
+					price.FormatETH(context.Background(), nil, s.fees), price.FormatETH(context.Background(), nil, s.average()))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r batchReport) l2Link(hash common.Hash) string {
+	return markdownLink(hash, r.explorers.L2TxURL(hash))
+}
+
+func (r batchReport) l1Link(hash *common.Hash) string {
+	if hash == nil {
+		return ""
+	}
+	return markdownLink(*hash, r.explorers.L1TxURL(*hash))
+}
+
+func markdownLink(hash common.Hash, url string) string {
+	if url == "" {
+		return "`" + hash.Hex() + "`"
+	}
+	return fmt.Sprintf("[`%s`](%s)", hash.Hex(), url)
+}
+
+func gasUsedCell(res batchResult) string {
+	if res.GasUsed == 0 {
+		return ""
+	}
+	return fmt.Sprint(res.GasUsed)
+}
+
+// markdownCell escapes s for use in a table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
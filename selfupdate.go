@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/mod/semver"
+
+	"github.com/base-org/withdrawer/update"
+)
+
+var (
+	// version is the release the binary was built from, set with -ldflags "-X main.version=v1.2.3".
+	version string
+	// releaseKey is the hex ed25519 public key release checksums are signed with, set with
+	// -ldflags "-X main.releaseKey=<key>" by the release workflow.
+	releaseKey string
+)
+
+// updateTimeout bounds each request of self-update, including downloading the binary.
+const updateTimeout = 5 * time.Minute
+
+// currentVersion returns the release the binary was built from, falling back to the module version
+// recorded by go install, or "dev" for local builds.
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && semver.IsValid(info.Main.Version) {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// runVersion implements the version subcommand.
+func runVersion([]string) {
+	fmt.Println(currentVersion())
+}
+
+// runSelfUpdate implements the self-update subcommand, which replaces the running binary with the
+// latest release, once its checksum and the signature of the checksums by the release key are verified.
+func runSelfUpdate(args []string) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	var check, force bool
+	var releaseURL, publicKey string
+	fs.BoolVar(&check, "check", false, "Only report whether a newer release is available")
+	fs.BoolVar(&force, "force", false, "Install the latest release even if it isn't newer than the running binary")
+	fs.StringVar(&releaseURL, "release-url", update.LatestURL, "GitHub API url of the release to install")
+	fs.StringVar(&publicKey, "public-key", releaseKey, "Hex ed25519 public key the release checksums must be signed with (default: the key of official builds)")
+	_ = fs.Parse(args)
+
+	ctx := interruptContext()
+	client := &http.Client{Timeout: updateTimeout}
+	current := currentVersion()
+	release, err := update.Latest(ctx, client, releaseURL)
+	if err != nil {
+		log.Crit("Error checking for updates", "error", err)
+	}
+	if !force && semver.IsValid(current) && semver.Compare(release.Tag, current) <= 0 {
+		fmt.Printf("withdrawer %s is up to date\n", current)
+		return
+	}
+	if check {
+		fmt.Printf("withdrawer %s is available (running %s), run withdrawer self-update to install it\n", release.Tag, current)
+		return
+	}
+
+	key, err := hex.DecodeString(publicKey)
+	if publicKey == "" || err != nil || len(key) != ed25519.PublicKeySize {
+		log.Crit("Invalid or missing --public-key, releases can't be verified without the release key", "value", publicKey)
+	}
+	name := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	checksums, err := release.Download(ctx, client, update.ChecksumsAsset)
+	if err != nil {
+		log.Crit("Error downloading release", "error", err)
+	}
+	signature, err := release.Download(ctx, client, update.SignatureAsset)
+	if err != nil {
+		log.Crit("Error downloading release", "error", err)
+	}
+	if err := update.VerifySignature(key, release.Tag, checksums, signature); err != nil {
+		log.Crit("Error verifying release, not installing it", "release", release.Tag, "error", err)
+	}
+	binary, err := release.Download(ctx, client, name)
+	if err != nil {
+		log.Crit("Error downloading release", "error", err)
+	}
+	if err := update.VerifyChecksum(checksums, name, binary); err != nil {
+		log.Crit("Error verifying release, not installing it", "release", release.Tag, "error", err)
+	}
+
+	path, err := os.Executable()
+	if err == nil {
+		path, err = filepath.EvalSymlinks(path)
+	}
+	if err != nil {
+		log.Crit("Error locating the running binary", "error", err)
+	}
+	if err := update.Replace(path, binary); err != nil {
+		log.Crit("Error replacing the binary, check it is writable", "path", path, "error", err)
+	}
+	fmt.Printf("Updated withdrawer from %s to %s\n", current, release.Tag)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runStats implements the stats subcommand, which reports how long withdrawals recently took to become
+// provable and finalizable, from the recent output proposals or dispute games of the network.
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	f := registerFlags(fs)
+	var samples int
+	fs.IntVar(&samples, "samples", 100, "Number of recent output proposals or dispute games to analyze")
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	if f.interop {
+		log.Crit("Stats are not supported for --interop")
+	}
+	if samples < 1 {
+		log.Crit("--samples must be at least 1")
+	}
+	ctx := interruptContext()
+	l1, err := n.dialL1(ctx, f.rpc)
+	if err != nil {
+		log.Crit("Error dialing L1 client", "error", err)
+	}
+
+	var timings []withdraw.ProposalTiming
+	kind := "output proposals"
+	if n.FaultProofs {
+		kind = "dispute games"
+		l2RPC, err := n.dialL2(ctx)
+		if err != nil {
+			log.Crit("Error dialing L2 client", "error", err)
+		}
+		timings, err = withdraw.RecentGameTimings(ctx, l1, ethclient.NewClient(l2RPC), n.DisputeGameFactory, n.PortalAddress, samples)
+	} else {
+		timings, err = withdraw.RecentOutputTimings(ctx, l1, n.L2OutputOracle, samples)
+	}
+	if err != nil {
+		log.Crit("Error loading proposal timings", "error", err)
+	}
+	if len(timings) == 0 {
+		log.Crit("Not enough " + kind + " to analyze")
+	}
+
+	// every L2 block is weighted equally, as withdrawals are initiated in any of them
+	var cadence []time.Duration
+	var provable, finalizable []delays
+	for _, t := range timings {
+		first, last := time.Unix(int64(t.FirstL2Time), 0), time.Unix(int64(t.LastL2Time), 0)
+		provableAt := time.Unix(int64(t.ProvableAt), 0)
+		d := delays{min: provableAt.Sub(last), max: provableAt.Sub(first), blocks: t.Blocks}
+		provable = append(provable, d)
+		if t.FinalizableAfter > 0 {
+			finalizable = append(finalizable, delays{min: d.min + t.FinalizableAfter, max: d.max + t.FinalizableAfter, blocks: t.Blocks})
+		}
+		cadence = append(cadence, last.Sub(first))
+	}
+	sort.Slice(cadence, func(i, j int) bool { return cadence[i] < cadence[j] })
+
+	from, to := time.Unix(int64(timings[0].FirstL2Time), 0), time.Unix(int64(timings[len(timings)-1].LastL2Time), 0)
+	fmt.Printf("Last %d %s, covering L2 blocks initiated %s to %s:\n", len(timings), kind, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	fmt.Printf("  proposal interval     median %s\n", countdown(cadence[len(cadence)/2]))
+	fmt.Printf("  time to provable      median %s, p90 %s, max %s\n", countdown(percentile(provable, 0.5)), countdown(percentile(provable, 0.9)), countdown(percentile(provable, 1)))
+	if len(finalizable) == 0 {
+		fmt.Println("  time to finalizable   unknown, none of the dispute games has resolved yet")
+	} else {
+		fmt.Printf("  time to finalizable   median %s, p90 %s, max %s\n", countdown(percentile(finalizable, 0.5)), countdown(percentile(finalizable, 0.9)), countdown(percentile(finalizable, 1)))
+	}
+	fmt.Println("Times are from the L2 block initiating a withdrawal, assuming it is proven as soon as possible.")
+	if len(finalizable) > 0 && len(finalizable) < len(provable) {
+		fmt.Printf("Time to finalizable is over the %d of %d dispute games that have resolved.\n", len(finalizable), len(provable))
+	}
+}
+
+// delays are the delays of the withdrawals initiated in a range of L2 blocks, spread evenly between min,
+// for the last block, and max, for the first.
+type delays struct {
+	min, max time.Duration
+	blocks   uint64
+}
+
+// percentile returns the delay that the share p of the withdrawals initiated in the L2 blocks of ds
+// don't exceed.
+func percentile(ds []delays, p float64) time.Duration {
+	var total uint64
+	lo, hi := ds[0].min, ds[0].max
+	for _, d := range ds {
+		total += d.blocks
+		lo, hi = min(lo, d.min), max(hi, d.max)
+	}
+	share := func(x time.Duration) float64 {
+		var below float64
+		for _, d := range ds {
+			switch {
+			case x >= d.max:
+				below += float64(d.blocks)
+			case x > d.min:
+				below += float64(d.blocks) * float64(x-d.min) / float64(d.max-d.min)
+			}
+		}
+		return below / float64(total)
+	}
+	// the share is monotonic, so the delay is found by bisection, to the second
+	for hi-lo > time.Second {
+		mid := lo + (hi-lo)/2
+		if share(mid) >= p {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi
+}
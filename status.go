@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/store"
+)
+
+// statusOutput is the JSON printed by the status subcommand with --json.
+type statusOutput struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	Withdrawals   []*store.Withdrawal `json:"withdrawals"`
+}
+
+// runStatus implements the status subcommand, which prints the state of the withdrawals tracked by the daemon.
+func runStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	sf := registerStoreFlags(fs)
+	var jsonOutput bool
+	fs.BoolVar(&jsonOutput, "json", false, "Print the withdrawals as JSON, described by the \"status\" output schema")
+	_ = fs.Parse(args)
+
+	st := sf.open()
+	defer st.Close()
+
+	withdrawals, err := st.List()
+	if err != nil {
+		log.Crit("Error listing withdrawals", "error", err)
+	}
+	if jsonOutput {
+		if withdrawals == nil {
+			withdrawals = []*store.Withdrawal{}
+		}
+		printJSON(statusOutput{SchemaVersion: schemaVersion, Withdrawals: withdrawals})
+		return
+	}
+
+	deadLettered := 0
+	for _, w := range withdrawals {
+		fmt.Printf("%s %-11s attempts %d", w.TxHash.String(), w.Status, w.Attempts)
+		if w.Network != "" {
+			fmt.Printf(", network %s", w.Network)
+		}
+		if w.Status != store.StatusDeadLetter && w.NextAttemptAt.After(time.Now()) {
+			fmt.Printf(", next attempt %s", w.NextAttemptAt.UTC().Format(time.RFC3339))
+		}
+		if w.LastError != "" {
+			fmt.Printf(", last error: %s", w.LastError)
+		}
+		fmt.Println()
+		if w.Status == store.StatusDeadLetter {
+			deadLettered++
+		}
+	}
+	if deadLettered > 0 {
+		fmt.Printf("%d withdrawal(s) in the dead-letter state, pass them to the daemon with --withdrawals to requeue them\n", deadLettered)
+	}
+}
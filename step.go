@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// stepResult is the JSON summary printed by the step subcommand, described by the "step" output schema.
+type stepResult struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Withdrawal    common.Hash `json:"withdrawal"`
+	// Status is the withdrawal's status after the step: pending, proven or finalized. With --no-wait,
+	// the status doesn't account for the sent transaction, which may not be mined yet.
+	Status string `json:"status"`
+	// Action is the action taken, if any: prove or finalize.
+	Action string       `json:"action,omitempty"`
+	Tx     *common.Hash `json:"tx,omitempty"`
+	// TxURL links Tx on the L1 block explorer of the network, if it has one.
+	TxURL string `json:"txUrl,omitempty"`
+	// Reason explains why no action was taken.
+	Reason string `json:"reason,omitempty"`
+	// NextRunAt is the earliest time rerunning could make progress, if nothing was actionable.
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+}
+
+// runStep implements the step subcommand, which performs at most one step of a withdrawal and reports
+// the outcome as JSON, so it can be scheduled from cron or a workflow scheduler.
+func runStep(args []string) {
+	fs := flag.NewFlagSet("step", flag.ExitOnError)
+	f := registerFlags(fs)
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	s := f.createSigner()
+
+	journal := f.openJournal()
+	defer journal.Store.Close()
+
+	ctx := interruptContext()
+	// the result is printed as JSON, so stdout is kept free of progress messages
+	hc := f.helperConfig(journal)
+	hc.logger = withdraw.NewWriterLogger(os.Stderr)
+	withdrawer, err := CreateWithdrawHelper(ctx, f.rpc, withdrawal, n, s, hc)
+	if err != nil {
+		log.Crit("Error creating withdrawer", "error", err)
+	}
+
+	res, err := step(ctx, withdrawer, withdrawal, f.noWait, n.explorers())
+	if err != nil {
+		var se *stepError
+		errors.As(err, &se)
+		exitWithError(se.msg, se.err)
+	}
+	printJSON(res)
+}
+
+// stepError is returned by step when querying the withdrawal or sending a transaction failed.
+type stepError struct {
+	msg string
+	err error
+}
+
+func (e *stepError) Error() string {
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *stepError) Unwrap() error {
+	return e.err
+}
+
+// step performs at most one step of the withdrawal, proving or finalizing it if possible. A withdrawal
+// that can't progress yet is not an error, its result tells why and when to try again instead.
+func step(ctx context.Context, withdrawer withdraw.WithdrawHelper, withdrawal common.Hash, noWait bool, explorers notify.Explorers) (stepResult, error) {
+	res := stepResult{SchemaVersion: schemaVersion, Withdrawal: withdrawal, Status: "pending"}
+
+	isFinalized, err := withdrawer.IsProofFinalized(ctx)
+	if err != nil {
+		return res, &stepError{"Error querying withdrawal finalization status", err}
+	}
+	if isFinalized {
+		res.Status = "finalized"
+		return res, nil
+	}
+
+	proofTime, err := withdrawer.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		return res, &stepError{"Error querying withdrawal proof", err}
+	}
+
+	scheduler, _ := withdrawer.(withdraw.Scheduler)
+	if proofTime == 0 {
+		if err := withdrawer.CheckIfProvable(ctx); err != nil {
+			res.Reason = err.Error()
+			if scheduler != nil {
+				res.NextRunAt = nextRunAt(ctx, scheduler.EarliestProveTime)
+			}
+			return res, nil
+		}
+		tx, err := withdrawer.ProveWithdrawal(ctx)
+		if err != nil {
+			return res, &stepError{"Error proving withdrawal", err}
+		}
+		res.Action, res.Tx, res.TxURL = "prove", &tx, explorers.L1TxURL(tx)
+		if !noWait {
+			res.Status = "proven"
+		}
+		return res, nil
+	}
+
+	res.Status = "proven"
+	if err := withdrawer.CheckIfFinalizable(ctx); err != nil {
+		res.Reason = err.Error()
+		if scheduler != nil {
+			res.NextRunAt = nextRunAt(ctx, scheduler.EarliestFinalizeTime)
+		}
+		return res, nil
+	}
+	tx, err := withdrawer.FinalizeWithdrawal(ctx)
+	if err != nil {
+		return res, &stepError{"Error completing withdrawal", err}
+	}
+	res.Action, res.Tx, res.TxURL = "finalize", &tx, explorers.L1TxURL(tx)
+	if !noWait {
+		res.Status = "finalized"
+	}
+	return res, nil
+}
+
+// nextRunAt returns the estimate, or nil if it failed. Estimates in the past (e.g. an overdue proposal)
+// mean the step may succeed any time, so they are moved to now.
+func nextRunAt(ctx context.Context, estimate func(context.Context) (time.Time, error)) *time.Time {
+	t, err := estimate(ctx)
+	if err != nil {
+		log.Warn("Error estimating next run time", "error", err)
+		return nil
+	}
+	if now := time.Now(); t.Before(now) {
+		t = now
+	}
+	t = t.UTC()
+	return &t
+}
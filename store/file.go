@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FileStore is a Store backed by a single JSON file, which is rewritten atomically on every update.
+type FileStore struct {
+	*fileLeader
+	path        string
+	mu          sync.Mutex
+	withdrawals map[common.Hash]*Withdrawal
+}
+
+// NewFileStore opens the JSON state file at path, creating it on the first update if it doesn't exist.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		fileLeader:  newFileLeader(path),
+		path:        path,
+		withdrawals: make(map[common.Hash]*Withdrawal),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var withdrawals []*Withdrawal
+	if err := json.Unmarshal(data, &withdrawals); err != nil {
+		return nil, err
+	}
+	for _, w := range withdrawals {
+		s.withdrawals[w.TxHash] = w
+	}
+	return s, nil
+}
+
+func (s *FileStore) List() ([]*Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(), nil
+}
+
+func (s *FileStore) list() []*Withdrawal {
+	withdrawals := make([]*Withdrawal, 0, len(s.withdrawals))
+	for _, w := range s.withdrawals {
+		c := *w
+		withdrawals = append(withdrawals, &c)
+	}
+	sort.Slice(withdrawals, func(i, j int) bool {
+		return withdrawals[i].CreatedAt.Before(withdrawals[j].CreatedAt)
+	})
+	return withdrawals
+}
+
+func (s *FileStore) Get(txHash common.Hash) (*Withdrawal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.withdrawals[txHash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := *w
+	return &c, nil
+}
+
+func (s *FileStore) Put(w *Withdrawal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := *w
+	s.withdrawals[w.TxHash] = &c
+	return s.flush()
+}
+
+// Ping checks that the directory holding the state file is still accessible.
+func (s *FileStore) Ping(ctx context.Context) error {
+	_, err := os.Stat(filepath.Dir(s.path))
+	return err
+}
+
+func (s *FileStore) Close() error {
+	return s.ReleaseLeadership()
+}
+
+// flush writes the state to a temporary file and renames it over the state file, so a crash
+// mid-write never leaves a corrupt state file behind.
+func (s *FileStore) flush() error {
+	data, err := json.MarshalIndent(s.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
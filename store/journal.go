@@ -0,0 +1,52 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Journal records sent prove and finalize transactions in the ProveTx and FinalizeTx of the withdrawals
+// in a Store. It implements withdraw.Journal.
+type Journal struct {
+	Store Store
+}
+
+func (j Journal) PendingTx(l2TxHash common.Hash, action string) (common.Hash, error) {
+	w, err := j.Store.Get(l2TxHash)
+	if errors.Is(err, ErrNotFound) {
+		return common.Hash{}, nil
+	} else if err != nil {
+		return common.Hash{}, err
+	}
+	switch action {
+	case "prove":
+		return w.ProveTx, nil
+	case "finalize":
+		return w.FinalizeTx, nil
+	default:
+		return common.Hash{}, fmt.Errorf("unknown action %q", action)
+	}
+}
+
+func (j Journal) RecordTx(l2TxHash common.Hash, action string, tx common.Hash) error {
+	now := time.Now()
+	w, err := j.Store.Get(l2TxHash)
+	if errors.Is(err, ErrNotFound) {
+		w = &Withdrawal{TxHash: l2TxHash, Status: StatusPending, CreatedAt: now}
+	} else if err != nil {
+		return err
+	}
+	switch action {
+	case "prove":
+		w.ProveTx = tx
+	case "finalize":
+		w.FinalizeTx = tx
+	default:
+		return fmt.Errorf("unknown action %q", action)
+	}
+	w.UpdatedAt = now
+	return j.Store.Put(w)
+}
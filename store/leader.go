@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/gofrs/flock"
+)
+
+// Leader is implemented by stores that can elect a single leader among several daemon replicas sharing
+// the store, so that only one of them submits transactions.
+type Leader interface {
+	// TryAcquireLeadership attempts to become the leader, or confirms that leadership is still held. It
+	// returns false if another replica is the leader.
+	TryAcquireLeadership(ctx context.Context) (bool, error)
+	// ReleaseLeadership gives up leadership, if held.
+	ReleaseLeadership() error
+}
+
+// fileLeader elects a leader among processes on the same host using an exclusive lock on a lock file.
+type fileLeader struct {
+	lock *flock.Flock
+}
+
+func newFileLeader(path string) *fileLeader {
+	return &fileLeader{lock: flock.New(path + ".lock")}
+}
+
+func (l *fileLeader) TryAcquireLeadership(ctx context.Context) (bool, error) {
+	return l.lock.TryLock()
+}
+
+func (l *fileLeader) ReleaseLeadership() error {
+	return l.lock.Unlock()
+}
+
+// postgresLeaderLockKey is the advisory lock key shared by all daemon replicas.
+const postgresLeaderLockKey = 0x77697468647277 // "withdrw"
+
+// postgresLeader elects a leader among replicas sharing a PostgreSQL database using a session-level
+// advisory lock. The lock is tied to a dedicated connection, so the server releases it automatically
+// if the leader dies or loses its connection. conn is guarded by mu, as the daemons of several
+// networks share the store.
+type postgresLeader struct {
+	db   *sql.DB
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func (l *postgresLeader) TryAcquireLeadership(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// the session holding the lock is gone, so is the lock
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, postgresLeaderLockKey).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *postgresLeader) ReleaseLeadership() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, postgresLeaderLockKey)
+	l.conn.Close()
+	l.conn = nil
+	return err
+}
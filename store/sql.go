@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const withdrawalColumns = `tx_hash, withdrawal_hash, status, prove_tx, finalize_tx, created_at, updated_at, proven_at, finalized_at, last_error, attempts, next_attempt_at, network`
+
+// migrate adds the columns introduced after the first release to databases created before them.
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`SELECT network FROM withdrawals LIMIT 0`); err != nil {
+		if _, err := db.Exec(`ALTER TABLE withdrawals ADD COLUMN network TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("adding network column: %w", err)
+		}
+	}
+	return nil
+}
+
+// SQLStore is a Store backed by a SQL database, so that progress can be queried with standard SQL
+// tooling. Hashes are stored as hex strings (empty when unset) and timestamps as unix seconds (0 when unset).
+type SQLStore struct {
+	Leader
+	db *sql.DB
+	// numbered is set for dialects using $1, $2, ... instead of ? placeholders
+	numbered bool
+}
+
+// rebind converts the ? placeholders in query to the dialect of the store.
+func (s *SQLStore) rebind(query string) string {
+	if !s.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (s *SQLStore) List() ([]*Withdrawal, error) {
+	rows, err := s.db.Query(`SELECT ` + withdrawalColumns + ` FROM withdrawals ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var withdrawals []*Withdrawal
+	for rows.Next() {
+		w, err := scanWithdrawal(rows)
+		if err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, rows.Err()
+}
+
+func (s *SQLStore) Get(txHash common.Hash) (*Withdrawal, error) {
+	row := s.db.QueryRow(s.rebind(`SELECT `+withdrawalColumns+` FROM withdrawals WHERE tx_hash = ?`), txHash.Hex())
+	w, err := scanWithdrawal(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return w, err
+}
+
+func (s *SQLStore) Put(w *Withdrawal) error {
+	_, err := s.db.Exec(s.rebind(`INSERT INTO withdrawals (`+withdrawalColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (tx_hash) DO UPDATE SET
+			withdrawal_hash = excluded.withdrawal_hash,
+			status = excluded.status,
+			prove_tx = excluded.prove_tx,
+			finalize_tx = excluded.finalize_tx,
+			updated_at = excluded.updated_at,
+			proven_at = excluded.proven_at,
+			finalized_at = excluded.finalized_at,
+			last_error = excluded.last_error,
+			attempts = excluded.attempts,
+			next_attempt_at = excluded.next_attempt_at,
+			network = excluded.network`),
+		w.TxHash.Hex(), hashString(w.WithdrawalHash), string(w.Status), hashString(w.ProveTx), hashString(w.FinalizeTx),
+		unixTime(w.CreatedAt), unixTime(w.UpdatedAt), unixTime(w.ProvenAt), unixTime(w.FinalizedAt), w.LastError,
+		w.Attempts, unixTime(w.NextAttemptAt), w.Network)
+	return err
+}
+
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *SQLStore) Close() error {
+	s.ReleaseLeadership()
+	return s.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWithdrawal(row scanner) (*Withdrawal, error) {
+	var txHash, withdrawalHash, status, proveTx, finalizeTx, lastError, network string
+	var createdAt, updatedAt, provenAt, finalizedAt, nextAttemptAt int64
+	var attempts int
+	err := row.Scan(&txHash, &withdrawalHash, &status, &proveTx, &finalizeTx, &createdAt, &updatedAt, &provenAt, &finalizedAt, &lastError, &attempts, &nextAttemptAt, &network)
+	if err != nil {
+		return nil, err
+	}
+	return &Withdrawal{
+		TxHash:         common.HexToHash(txHash),
+		Network:        network,
+		WithdrawalHash: common.HexToHash(withdrawalHash),
+		Status:         Status(status),
+		ProveTx:        common.HexToHash(proveTx),
+		FinalizeTx:     common.HexToHash(finalizeTx),
+		CreatedAt:      fromUnixTime(createdAt),
+		UpdatedAt:      fromUnixTime(updatedAt),
+		ProvenAt:       fromUnixTime(provenAt),
+		FinalizedAt:    fromUnixTime(finalizedAt),
+		LastError:      lastError,
+		Attempts:       attempts,
+		NextAttemptAt:  fromUnixTime(nextAttemptAt),
+	}, nil
+}
+
+func hashString(h common.Hash) string {
+	if h == (common.Hash{}) {
+		return ""
+	}
+	return h.Hex()
+}
+
+func unixTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func fromUnixTime(sec int64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"database/sql"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS withdrawals (
+	tx_hash         TEXT PRIMARY KEY,
+	withdrawal_hash TEXT NOT NULL DEFAULT '',
+	status          TEXT NOT NULL,
+	prove_tx        TEXT NOT NULL DEFAULT '',
+	finalize_tx     TEXT NOT NULL DEFAULT '',
+	created_at      INTEGER NOT NULL,
+	updated_at      INTEGER NOT NULL,
+	proven_at       INTEGER NOT NULL DEFAULT 0,
+	finalized_at    INTEGER NOT NULL DEFAULT 0,
+	last_error      TEXT NOT NULL DEFAULT '',
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL DEFAULT 0,
+	network         TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS withdrawals_status ON withdrawals (status);`
+
+// NewSQLiteStore opens, and if needed creates, the embedded SQLite database at path.
+func NewSQLiteStore(path string) (*SQLStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only supports a single writer
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`PRAGMA journal_mode = WAL; PRAGMA busy_timeout = 5000;`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLStore{Leader: newFileLeader(path), db: db}, nil
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Status is the stage a tracked withdrawal has reached.
+type Status string
+
+const (
+	// StatusPending withdrawals have been initiated on L2 but not proven yet.
+	StatusPending Status = "pending"
+	// StatusProven withdrawals have been proven and are waiting to be finalized.
+	StatusProven Status = "proven"
+	// StatusFinalized withdrawals have been finalized and need no further action.
+	StatusFinalized Status = "finalized"
+	// StatusDeadLetter withdrawals exhausted their retries and are no longer processed until requeued.
+	StatusDeadLetter Status = "dead-letter"
+)
+
+// Statuses lists all statuses, in lifecycle order.
+var Statuses = []Status{StatusPending, StatusProven, StatusFinalized, StatusDeadLetter}
+
+// ErrNotFound is returned when a withdrawal is not tracked by the store.
+var ErrNotFound = errors.New("withdrawal not found")
+
+// Withdrawal is the persisted state of a tracked withdrawal.
+type Withdrawal struct {
+	TxHash         common.Hash `json:"txHash"`
+	WithdrawalHash common.Hash `json:"withdrawalHash"`
+	Status         Status      `json:"status"`
+	ProveTx        common.Hash `json:"proveTx"`
+	FinalizeTx     common.Hash `json:"finalizeTx"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	UpdatedAt      time.Time   `json:"updatedAt"`
+	ProvenAt       time.Time   `json:"provenAt"`
+	FinalizedAt    time.Time   `json:"finalizedAt"`
+	LastError      string      `json:"lastError,omitempty"`
+	// Attempts is the number of consecutive failed prove or finalize attempts.
+	Attempts int `json:"attempts"`
+	// NextAttemptAt is the earliest time the next action may be attempted after a failure.
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	// Network is the name of the network the withdrawal was initiated on, when a daemon tracks
+	// withdrawals on several networks, and empty for its default network.
+	Network string `json:"network,omitempty"`
+}
+
+// Store persists the state of tracked withdrawals across restarts.
+type Store interface {
+	// List returns all tracked withdrawals.
+	List() ([]*Withdrawal, error)
+	// Get returns the tracked withdrawal initiated in txHash, or ErrNotFound.
+	Get(txHash common.Hash) (*Withdrawal, error)
+	// Put inserts or updates a tracked withdrawal.
+	Put(w *Withdrawal) error
+	// Ping checks that the store is reachable.
+	Ping(ctx context.Context) error
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Open opens the store of the given kind ("file", "sqlite" or "postgres") at path, which is a
+// connection string for postgres.
+func Open(kind, path string) (Store, error) {
+	switch kind {
+	case "file":
+		return NewFileStore(path)
+	case "sqlite":
+		return NewSQLiteStore(path)
+	case "postgres":
+		return NewPostgresStore(path)
+	default:
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// normalized returns a copy of w with its times in UTC, as stores may return them in another location.
+func normalized(w *Withdrawal) Withdrawal {
+	c := *w
+	for _, t := range []*time.Time{&c.CreatedAt, &c.UpdatedAt, &c.ProvenAt, &c.FinalizedAt, &c.NextAttemptAt} {
+		if !t.IsZero() {
+			*t = t.UTC()
+		}
+	}
+	return c
+}
+
+// testRoundTrip checks that the withdrawals put in the store opened by open are returned by Get and
+// List, also once the store was closed and opened again.
+func testRoundTrip(t *testing.T, open func() (Store, error)) {
+	// stores keep times to the second
+	now := time.Unix(1_700_000_000, 0)
+	pending := &Withdrawal{
+		TxHash:    common.HexToHash("0x01"),
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	proven := &Withdrawal{
+		TxHash:         common.HexToHash("0x02"),
+		WithdrawalHash: common.HexToHash("0x12"),
+		Status:         StatusProven,
+		ProveTx:        common.HexToHash("0x22"),
+		CreatedAt:      now.Add(time.Second),
+		UpdatedAt:      now.Add(time.Hour),
+		ProvenAt:       now.Add(time.Hour),
+		LastError:      "challenge period is active",
+		Attempts:       2,
+		NextAttemptAt:  now.Add(2 * time.Hour),
+		Network:        "op-mainnet",
+	}
+
+	s, err := open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if _, err := s.Get(pending.TxHash); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() of untracked withdrawal error = %v, want ErrNotFound", err)
+	}
+	for _, w := range []*Withdrawal{pending, proven} {
+		if err := s.Put(w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// updates replace the withdrawal
+	proven.Status, proven.FinalizeTx, proven.FinalizedAt, proven.LastError, proven.Attempts = StatusFinalized, common.HexToHash("0x32"), now.Add(3*time.Hour), "", 0
+	if err := s.Put(proven); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(s Store) {
+		t.Helper()
+		for _, want := range []*Withdrawal{pending, proven} {
+			got, err := s.Get(want.TxHash)
+			if err != nil {
+				t.Fatalf("Get(%s) error = %v", want.TxHash, err)
+			}
+			if !reflect.DeepEqual(normalized(got), normalized(want)) {
+				t.Fatalf("Get(%s) = %+v, want %+v", want.TxHash, got, want)
+			}
+		}
+		list, err := s.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 || list[0].TxHash != pending.TxHash || list[1].TxHash != proven.TxHash {
+			t.Fatalf("List() = %+v, want %s and %s in creation order", list, pending.TxHash, proven.TxHash)
+		}
+	}
+	check(s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	check(s)
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	testRoundTrip(t, func() (Store, error) { return NewFileStore(path) })
+}
+
+func TestSQLiteStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	testRoundTrip(t, func() (Store, error) { return NewSQLiteStore(path) })
+}
+
+// TestPostgresStore runs against the database at $WITHDRAWER_TEST_POSTGRES_DSN, whose withdrawals table
+// it empties, and is skipped if it isn't set.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("WITHDRAWER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("WITHDRAWER_TEST_POSTGRES_DSN not set")
+	}
+	s, err := NewPostgresStore(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.db.Exec(`DELETE FROM withdrawals`)
+	s.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testRoundTrip(t, func() (Store, error) { return NewPostgresStore(dsn) })
+}
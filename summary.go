@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/notify"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runSummary is printed at the end of a run, telling what happened and what to do next.
+type runSummary struct {
+	withdrawal common.Hash
+	// result is what happened, e.g. "Proven".
+	result string
+	// proveTx and finalizeTx are the L1 transactions sent by the run, if any.
+	proveTx    common.Hash
+	finalizeTx common.Hash
+	// next is what the user needs to do next.
+	next string
+}
+
+// print prints the summary, linking the transactions to the explorers of the network.
+func (s runSummary) print(explorers notify.Explorers) {
+	fmt.Println()
+	fmt.Printf("Withdrawal:   %s\n", withLink(s.withdrawal, explorers.L2TxURL(s.withdrawal)))
+	fmt.Printf("Result:       %s\n", s.result)
+	if s.proveTx != (common.Hash{}) {
+		fmt.Printf("Prove tx:     %s\n", withLink(s.proveTx, explorers.L1TxURL(s.proveTx)))
+	}
+	if s.finalizeTx != (common.Hash{}) {
+		fmt.Printf("Finalize tx:  %s\n", withLink(s.finalizeTx, explorers.L1TxURL(s.finalizeTx)))
+	}
+	fmt.Printf("Next step:    %s\n", s.next)
+}
+
+func withLink(hash common.Hash, url string) string {
+	if url == "" {
+		return hash.String()
+	}
+	return fmt.Sprintf("%s (%s)", hash.String(), url)
+}
+
+// rerunAfter returns the next step of running the command again to perform action once the withdrawal
+// allows it, or fallback if the helper can't estimate when that is.
+func rerunAfter(ctx context.Context, helper withdraw.WithdrawHelper, command, action, fallback string) string {
+	scheduler, ok := helper.(withdraw.Scheduler)
+	if !ok {
+		return fallback
+	}
+	estimate := scheduler.EarliestFinalizeTime
+	if action == "prove" {
+		estimate = scheduler.EarliestProveTime
+	}
+	t, err := estimate(ctx)
+	if err != nil {
+		log.Warn("Error estimating next run time", "error", err)
+		return fallback
+	}
+	if t.Before(time.Now()) {
+		return fmt.Sprintf("%s now to %s", command, action)
+	}
+	return fmt.Sprintf("%s after %s to %s", command, t.UTC().Format("2006-01-02 15:04 MST"), action)
+}
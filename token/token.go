@@ -0,0 +1,242 @@
+// Package token checks the L1 mapping of ERC-20 tokens before they are withdrawn through the standard
+// bridge, as a token with a non-standard mapping may be burned on L2 without being redeemable on L1.
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultTokenListURL is the Superchain token list, which records the L1 and L2 addresses of the tokens
+// bridged to OP Stack chains.
+const DefaultTokenListURL = "https://static.optimism.io/optimism.tokenlist.json"
+
+// mintableABI holds the methods of OptimismMintableERC20 and its legacy version, and the ERC-20 metadata.
+const mintableABI = `[
+	{"inputs":[],"name":"remoteToken","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"bridge","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"l1Token","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"l2Bridge","outputs":[{"type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"interfaceId","type":"bytes4"}],"name":"supportsInterface","outputs":[{"type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"symbol","outputs":[{"type":"string"}],"stateMutability":"view","type":"function"}
+]`
+
+// The ERC-165 interface IDs the standard bridge checks tokens against to burn instead of escrow them.
+var (
+	mintableInterface       = [4]byte{0xec, 0x4f, 0xc8, 0xe3}
+	legacyMintableInterface = [4]byte{0x1d, 0x1d, 0x8b, 0x63}
+)
+
+// Report is the result of checking an L2 token. Problems make withdrawals of the token fail or lose
+// funds, while warnings point at mappings that are unusual but may be intended.
+type Report struct {
+	Token  common.Address
+	Symbol string
+	// Mintable is set for OptimismMintableERC20 tokens, which the bridge burns on L2 and releases on L1, and
+	// Legacy for their legacy version. Other tokens are escrowed on L2 and minted on L1.
+	Mintable, Legacy bool
+	Bridge           common.Address
+	// L1Token is the L1 token withdrawals are redeemed in.
+	L1Token  common.Address
+	Problems []string
+	Warnings []string
+}
+
+// OK returns whether no problems were found.
+func (r *Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *Report) problem(format string, args ...interface{}) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+func (r *Report) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Check checks the mapping of l2Token to L1 through the standard bridge. l1Token, if set, is the L1 token
+// the withdrawal is meant to be redeemed in, which is required to check tokens native to L2.
+func Check(ctx context.Context, l1, l2 bind.ContractCaller, l2Token, l1Token common.Address) (*Report, error) {
+	parsed, err := abi.JSON(strings.NewReader(mintableABI))
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{Token: l2Token}
+	code, err := l2.CodeAt(ctx, l2Token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying L2 token: %w", err)
+	}
+	if len(code) == 0 {
+		r.problem("%s is not a contract on L2", l2Token)
+		return r, nil
+	}
+	token := bind.NewBoundContract(l2Token, parsed, l2, nil, nil)
+	r.Symbol, _ = call[string](ctx, token, "symbol")
+	r.Mintable, _ = call[bool](ctx, token, "supportsInterface", mintableInterface)
+	if !r.Mintable {
+		r.Legacy, _ = call[bool](ctx, token, "supportsInterface", legacyMintableInterface)
+	}
+
+	switch {
+	case r.Mintable:
+		if r.L1Token, err = call[common.Address](ctx, token, "remoteToken"); err != nil {
+			return nil, fmt.Errorf("error querying remote token: %w", err)
+		}
+		if r.Bridge, err = call[common.Address](ctx, token, "bridge"); err != nil {
+			return nil, fmt.Errorf("error querying bridge: %w", err)
+		}
+	case r.Legacy:
+		if r.L1Token, err = call[common.Address](ctx, token, "l1Token"); err != nil {
+			return nil, fmt.Errorf("error querying L1 token: %w", err)
+		}
+		if r.Bridge, err = call[common.Address](ctx, token, "l2Bridge"); err != nil {
+			return nil, fmt.Errorf("error querying bridge: %w", err)
+		}
+	default:
+		return r, checkNative(ctx, l1, parsed, r, l1Token)
+	}
+
+	if r.Bridge != predeploys.L2StandardBridgeAddr {
+		r.problem("%s is minted by %s, not the L2StandardBridge (%s), which won't burn it for a withdrawal through the standard bridge", l2Token, r.Bridge, predeploys.L2StandardBridgeAddr)
+	}
+	if l1Token != (common.Address{}) && l1Token != r.L1Token {
+		r.problem("%s is redeemed in %s on L1, not %s, so the standard bridge will reject withdrawing it to %s", l2Token, r.L1Token, l1Token, l1Token)
+	}
+	code, err = l1.CodeAt(ctx, r.L1Token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error querying L1 token: %w", err)
+	}
+	if len(code) == 0 {
+		r.problem("the L1 token %s of %s is not a contract on L1, so withdrawals of it can't be redeemed", r.L1Token, l2Token)
+	}
+	return r, nil
+}
+
+// checkNative checks a token native to L2, which is escrowed by the bridge on L2 and minted on L1 by an
+// OptimismMintableERC20 whose remote token it is.
+func checkNative(ctx context.Context, l1 bind.ContractCaller, parsed abi.ABI, r *Report, l1Token common.Address) error {
+	if l1Token == (common.Address{}) {
+		r.warn("%s is not an OptimismMintableERC20, so it is native to L2 and escrowed by the bridge; pass its L1 token to check it", r.Token)
+		return nil
+	}
+	r.L1Token = l1Token
+	code, err := l1.CodeAt(ctx, l1Token, nil)
+	if err != nil {
+		return fmt.Errorf("error querying L1 token: %w", err)
+	}
+	if len(code) == 0 {
+		r.problem("the L1 token %s is not a contract on L1", l1Token)
+		return nil
+	}
+	token := bind.NewBoundContract(l1Token, parsed, l1, nil, nil)
+	if mintable, _ := call[bool](ctx, token, "supportsInterface", mintableInterface); !mintable {
+		r.problem("the L1 token %s is not an OptimismMintableERC20, so the bridge can't mint it for withdrawals of the L2-native %s", l1Token, r.Token)
+		return nil
+	}
+	remote, err := call[common.Address](ctx, token, "remoteToken")
+	if err != nil {
+		return fmt.Errorf("error querying remote token of L1 token: %w", err)
+	}
+	if remote != r.Token {
+		r.problem("the L1 token %s represents %s on L2, not %s, so finalizing withdrawals of %s to it will fail and leave them escrowed", l1Token, remote, r.Token, r.Token)
+	}
+	r.warn("%s is native to L2, it is escrowed on L2 and minted on L1 by %s", r.Token, l1Token)
+	return nil
+}
+
+// call calls the method of c returning a single value of type T.
+func call[T any](ctx context.Context, c *bind.BoundContract, method string, args ...interface{}) (T, error) {
+	var out []interface{}
+	var zero T
+	if err := c.Call(&bind.CallOpts{Context: ctx}, &out, method, args...); err != nil {
+		return zero, err
+	}
+	if len(out) != 1 {
+		return zero, fmt.Errorf("unexpected result of %s", method)
+	}
+	return *abi.ConvertType(out[0], new(T)).(*T), nil
+}
+
+// List is a token list, in the format of https://tokenlists.org, with the opTokenId extension of the
+// Superchain token list identifying the same token across chains.
+type List struct {
+	Tokens []ListedToken `json:"tokens"`
+}
+
+// ListedToken is a token of a List.
+type ListedToken struct {
+	ChainID    uint64         `json:"chainId"`
+	Address    common.Address `json:"address"`
+	Symbol     string         `json:"symbol"`
+	Extensions struct {
+		OpTokenID string `json:"opTokenId"`
+	} `json:"extensions"`
+}
+
+// FetchList downloads the token list at url, such as DefaultTokenListURL.
+func FetchList(ctx context.Context, client *http.Client, url string) (*List, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading token list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("token list responded with status %s", resp.Status)
+	}
+	var list List
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("error decoding token list: %w", err)
+	}
+	return &list, nil
+}
+
+// CheckList warns if the token pair of r isn't the one of list, for the given chains.
+func (r *Report) CheckList(list *List, l1ChainID, l2ChainID uint64) {
+	var listed *ListedToken
+	for i, t := range list.Tokens {
+		if t.ChainID == l2ChainID && t.Address == r.Token {
+			listed = &list.Tokens[i]
+		}
+	}
+	if listed == nil {
+		r.warn("%s is not in the token list", r.Token)
+		return
+	}
+	var l1Tokens []string
+	for _, t := range list.Tokens {
+		if t.ChainID != l1ChainID || !sameToken(t, *listed) {
+			continue
+		}
+		if t.Address == r.L1Token {
+			return
+		}
+		l1Tokens = append(l1Tokens, t.Address.Hex())
+	}
+	if len(l1Tokens) == 0 {
+		r.warn("the token list has no L1 token for %s (%s)", r.Token, listed.Symbol)
+		return
+	}
+	r.warn("the token list maps %s (%s) to %s on L1, not %s", r.Token, listed.Symbol, strings.Join(l1Tokens, ", "), r.L1Token)
+}
+
+// sameToken returns whether a and b are the same token on different chains, by their opTokenId, or their
+// symbol for lists without it.
+func sameToken(a, b ListedToken) bool {
+	if a.Extensions.OpTokenID != "" || b.Extensions.OpTokenID != "" {
+		return a.Extensions.OpTokenID == b.Extensions.OpTokenID
+	}
+	return a.Symbol == b.Symbol
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/term"
+
+	"github.com/base-org/withdrawer/signer"
+	"github.com/base-org/withdrawer/store"
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// tuiRow is a withdrawal shown by the tui subcommand, with its state as last queried from the chain.
+type tuiRow struct {
+	withdrawal *store.Withdrawal
+	// state describes what the withdrawal is ready for or waiting for, or why it couldn't be queried.
+	state string
+	// action is the action that can be taken now, if any: prove or finalize.
+	action    string
+	nextRunAt *time.Time
+}
+
+// tui is the state of the terminal UI, shared by the input, refresh and action goroutines.
+type tui struct {
+	f  *flags
+	n  network
+	s  signer.Signer
+	st store.Store
+	hc helperConfig
+	// clients are shared by the helpers of all withdrawals and actions.
+	clients *helperClients
+
+	mu          sync.Mutex
+	rows        []tuiRow
+	selected    int
+	message     string
+	busy        bool
+	refreshing  bool
+	refreshedAt time.Time
+	// helpers are the read-only helpers of the withdrawals, reused across refreshes.
+	helpers map[common.Hash]withdraw.WithdrawHelper
+}
+
+// runTUI implements the tui subcommand, which shows the withdrawals tracked in a state store as a live
+// table, with countdowns to when they can progress, and proves or finalizes the selected one on a key
+// press. Without a signer, the table is read-only.
+func runTUI(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	f := registerFlags(fs)
+	sf := registerStoreFlags(fs)
+	var refresh time.Duration
+	var prover string
+	fs.DurationVar(&refresh, "refresh", 30*time.Second, "Interval between queries of the state of the withdrawals")
+	fs.StringVar(&prover, "prover", "", "L1 address whose fault proofs are shown in the read-only table without a signer (default: the signer's address)")
+	_ = fs.Parse(args)
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		log.Crit("The tui subcommand must be run in a terminal, use status for scripts")
+	}
+
+	n := f.resolveNetwork()
+	t := &tui{f: f, n: n, st: sf.open(), helpers: make(map[common.Hash]withdraw.WithdrawHelper)}
+	defer t.st.Close()
+	if f.privateKey != "" || f.ledger || f.mnemonic != "" {
+		t.s = f.createSigner()
+	}
+	journal := f.openJournal()
+	defer journal.Store.Close()
+	t.hc = f.helperConfig(journal)
+	// with Fault Proofs, proofs are recorded per submitter, so the table shows those of the signer or --prover
+	if t.s != nil {
+		if prover != "" {
+			log.Crit("--prover can't be used with a signer, whose proofs are shown")
+		}
+		t.hc.from = t.s.Address()
+	} else if prover != "" {
+		if !common.IsHexAddress(prover) {
+			log.Crit("Invalid --prover address", "address", prover)
+		}
+		t.hc.from = common.HexToAddress(prover)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clients, err := dialHelperClients(ctx, f.rpc, n, t.s, t.hc)
+	if err != nil {
+		log.Crit("Error dialing clients", "error", err)
+	}
+	defer clients.Close()
+	t.clients = clients
+	// progress messages end up in the status line, and logs would garble the screen
+	t.hc.logger = t
+	log.SetDefault(log.NewLogger(log.DiscardHandler()))
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		log.Crit("Error setting up terminal", "error", err)
+	}
+	// the alternate screen keeps the user's scrollback intact
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		fmt.Print("\x1b[?25h\x1b[?1049l")
+		_ = term.Restore(fd, state)
+	}()
+
+	keys := make(chan string)
+	go readKeys(keys)
+	go t.refresh(ctx)
+
+	render := time.NewTicker(time.Second)
+	defer render.Stop()
+	refreshTicker := time.NewTicker(refresh)
+	defer refreshTicker.Stop()
+	for {
+		t.render()
+		select {
+		case key := <-keys:
+			if !t.handleKey(ctx, key) {
+				return
+			}
+		case <-refreshTicker.C:
+			go t.refresh(ctx)
+		case <-render.C:
+		}
+	}
+}
+
+// readKeys sends the keys pressed to keys, with arrow keys named up and down.
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 8)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			keys <- "q"
+			return
+		}
+		switch key := string(buf[:n]); key {
+		case "\x1b[A":
+			keys <- "up"
+		case "\x1b[B":
+			keys <- "down"
+		case "\x03":
+			keys <- "q"
+		default:
+			keys <- key
+		}
+	}
+}
+
+// handleKey handles a key press, returning false to quit.
+func (t *tui) handleKey(ctx context.Context, key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch key {
+	case "q":
+		return false
+	case "up", "k":
+		if t.selected > 0 {
+			t.selected--
+		}
+	case "down", "j":
+		if t.selected < len(t.rows)-1 {
+			t.selected++
+		}
+	case "r":
+		go t.refresh(ctx)
+	case "p", "f":
+		action := map[string]string{"p": "prove", "f": "finalize"}[key]
+		switch {
+		case t.s == nil:
+			t.message = "No signer, pass --private-key, --ledger or --mnemonic to " + action
+		case t.busy:
+			t.message = "Wait for the running action to complete"
+		case t.selected >= len(t.rows):
+		case t.rows[t.selected].action != action:
+			t.message = fmt.Sprintf("%s can't be %s now", shortHash(t.rows[t.selected].withdrawal.TxHash), pastTense(action))
+		default:
+			t.busy = true
+			go t.act(ctx, t.rows[t.selected].withdrawal)
+		}
+	}
+	return true
+}
+
+// Printf implements withdraw.Logger, showing the progress messages of the actions in the status line.
+func (t *tui) Printf(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.message = strings.TrimSpace(fmt.Sprintf(format, args...))
+}
+
+// refresh queries the state of the tracked withdrawals.
+func (t *tui) refresh(ctx context.Context) {
+	t.mu.Lock()
+	if t.refreshing {
+		t.mu.Unlock()
+		return
+	}
+	t.refreshing = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.refreshing = false
+		t.mu.Unlock()
+	}()
+
+	withdrawals, err := t.st.List()
+	if err != nil {
+		t.Printf("Error listing withdrawals: %v", err)
+		return
+	}
+	rows := make([]tuiRow, 0, len(withdrawals))
+	for _, w := range withdrawals {
+		row := tuiRow{withdrawal: w}
+		if w.Status == store.StatusFinalized {
+			row.state = "finalized"
+		} else if helper, err := t.helper(ctx, w.TxHash); err != nil {
+			row.state = err.Error()
+		} else {
+			row.action, row.state, row.nextRunAt = inspectWithdrawal(ctx, helper)
+		}
+		rows = append(rows, row)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows, t.refreshedAt = rows, time.Now()
+	if t.selected >= len(rows) {
+		t.selected = max(len(rows)-1, 0)
+	}
+}
+
+// helper returns the read-only helper of the withdrawal, creating it on first use.
+func (t *tui) helper(ctx context.Context, withdrawal common.Hash) (withdraw.WithdrawHelper, error) {
+	t.mu.Lock()
+	helper, ok := t.helpers[withdrawal]
+	t.mu.Unlock()
+	if ok {
+		return helper, nil
+	}
+	helper, err := newWithdrawHelper(ctx, t.clients, withdrawal, t.n, nil, t.hc)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.helpers[withdrawal] = helper
+	t.mu.Unlock()
+	return helper, nil
+}
+
+// inspectWithdrawal returns the action that can be taken on the withdrawal now, if any, and otherwise
+// what it is waiting for and when it may progress.
+func inspectWithdrawal(ctx context.Context, helper withdraw.WithdrawHelper) (string, string, *time.Time) {
+	finalized, err := helper.IsProofFinalized(ctx)
+	if err != nil {
+		return "", err.Error(), nil
+	}
+	if finalized {
+		return "", "finalized", nil
+	}
+	proofTime, err := helper.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		return "", err.Error(), nil
+	}
+	scheduler, _ := helper.(withdraw.Scheduler)
+	if proofTime == 0 {
+		if err := helper.CheckIfProvable(ctx); err != nil {
+			var next *time.Time
+			if scheduler != nil {
+				next = nextRunAt(ctx, scheduler.EarliestProveTime)
+			}
+			return "", "waiting for a proposal", next
+		}
+		return "prove", "provable", nil
+	}
+	if err := helper.CheckIfFinalizable(ctx); err != nil {
+		var next *time.Time
+		if scheduler != nil {
+			next = nextRunAt(ctx, scheduler.EarliestFinalizeTime)
+		}
+		return "", "proven, maturing", next
+	}
+	return "finalize", "finalizable", nil
+}
+
+// act performs the next step of the withdrawal with a signing helper, and records its outcome in the store.
+func (t *tui) act(ctx context.Context, w *store.Withdrawal) {
+	defer func() {
+		t.mu.Lock()
+		t.busy = false
+		t.mu.Unlock()
+		t.refresh(ctx)
+	}()
+	helper, err := newWithdrawHelper(ctx, t.clients, w.TxHash, t.n, t.s, t.hc)
+	if err != nil {
+		t.Printf("Error creating withdrawer: %v", err)
+		return
+	}
+	res, err := step(ctx, helper, w.TxHash, false, t.n.explorers())
+	if err != nil {
+		t.Printf("%v", err)
+		return
+	}
+	if res.Tx == nil {
+		t.Printf("Nothing to do for %s: %s", shortHash(w.TxHash), res.Reason)
+		return
+	}
+
+	now := time.Now()
+	updated := *w
+	updated.UpdatedAt, updated.LastError, updated.Attempts = now, "", 0
+	if res.Action == "prove" {
+		updated.Status, updated.ProveTx, updated.ProvenAt = store.StatusProven, *res.Tx, now
+	} else {
+		updated.Status, updated.FinalizeTx, updated.FinalizedAt = store.StatusFinalized, *res.Tx, now
+	}
+	if err := t.st.Put(&updated); err != nil {
+		t.Printf("Error recording %s tx %s: %v", res.Action, res.Tx, err)
+		return
+	}
+	t.Printf("%s %s: %s", strings.ToUpper(pastTense(res.Action)[:1])+pastTense(res.Action)[1:], shortHash(w.TxHash), res.Tx)
+}
+
+// render draws the table of withdrawals, the keys and the status line.
+func (t *tui) render() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		width = 120
+	}
+
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	refreshed := "refreshing..."
+	if !t.refreshedAt.IsZero() {
+		refreshed = "refreshed " + t.refreshedAt.Format("15:04:05")
+	}
+	line(&b, width, fmt.Sprintf("Withdrawals on %s (%d), %s", t.f.network, len(t.rows), refreshed))
+	line(&b, width, "")
+	line(&b, width, fmt.Sprintf("  %-23s %-11s %-25s %-14s %s", "WITHDRAWAL", "STATUS", "STATE", "NEXT", "ACTION"))
+	for i, row := range t.rows {
+		next := ""
+		if row.nextRunAt != nil {
+			next = countdown(time.Until(*row.nextRunAt))
+		}
+		text := fmt.Sprintf("  %-23s %-11s %-25s %-14s %s", shortHash(row.withdrawal.TxHash), row.withdrawal.Status, truncate(row.state, 25), next, row.action)
+		if i == t.selected {
+			b.WriteString("\x1b[7m")
+			line(&b, width, text)
+			b.WriteString("\x1b[0m")
+		} else {
+			line(&b, width, text)
+		}
+	}
+	if len(t.rows) == 0 && !t.refreshedAt.IsZero() {
+		line(&b, width, "  No tracked withdrawals, add them with the daemon")
+	}
+	line(&b, width, "")
+	keys := "up/down select  p prove  f finalize  r refresh  q quit"
+	if t.s == nil {
+		keys = "up/down select  r refresh  q quit  (read-only, no signer)"
+	}
+	line(&b, width, keys)
+	if t.busy {
+		line(&b, width, "Working... "+t.message)
+	} else {
+		line(&b, width, t.message)
+	}
+	fmt.Print(b.String())
+}
+
+// line writes s, cut to the terminal width, and a raw mode line break.
+func line(b *strings.Builder, width int, s string) {
+	b.WriteString(truncate(s, width))
+	b.WriteString("\r\n")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "~"
+}
+
+// shortHash abbreviates a hash for the table, e.g. 0x1234abcd...89abcdef.
+func shortHash(hash common.Hash) string {
+	h := hash.Hex()
+	return h[:10] + "..." + h[len(h)-8:]
+}
+
+// countdown formats the time left until a withdrawal may progress.
+func countdown(d time.Duration) string {
+	if d <= 0 {
+		return "now"
+	}
+	d = d.Round(time.Second)
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd%s", d/(24*time.Hour), (d % (24 * time.Hour)).Truncate(time.Minute))
+	}
+	return d.String()
+}
+
+func pastTense(action string) string {
+	if action == "prove" {
+		return "proven"
+	}
+	return "finalized"
+}
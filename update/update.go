@@ -0,0 +1,175 @@
+// Package update finds, verifies and installs releases of the withdrawer published on GitHub.
+//
+// Every release is expected to have one binary per platform, named as by AssetName, a ChecksumsAsset
+// listing their SHA-256 checksums in the format of sha256sum, and a SignatureAsset holding the ed25519
+// signature by the release key of the SignedMessage of the release tag and checksums file, so that the
+// checksums of one release can't be passed off as those of another.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LatestURL is the GitHub API endpoint of the latest release of the withdrawer.
+const LatestURL = "https://api.github.com/repos/base-org/withdrawer/releases/latest"
+
+const (
+	ChecksumsAsset = "checksums.txt"
+	SignatureAsset = "checksums.txt.sig"
+)
+
+// maxAssetSize bounds the size of downloaded assets.
+const maxAssetSize = 256 << 20
+
+var (
+	// ErrChecksumMismatch is returned if a downloaded binary doesn't match its checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrInvalidSignature is returned if the checksums file isn't signed by the release key.
+	ErrInvalidSignature = errors.New("invalid signature of the checksums")
+)
+
+// Release is a GitHub release.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a GitHub release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// AssetName returns the name of the binary for the given platform, e.g. withdrawer_linux_amd64.
+func AssetName(goos, goarch string) string {
+	name := "withdrawer_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest fetches the release described by the GitHub API endpoint url, such as LatestURL.
+func Latest(ctx context.Context, client *http.Client, url string) (*Release, error) {
+	body, err := get(ctx, client, url, "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("error querying release: %w", err)
+	}
+	var r Release
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("error decoding release: %w", err)
+	}
+	return &r, nil
+}
+
+// Download downloads the asset of the release with the given name.
+func (r *Release) Download(ctx context.Context, client *http.Client, name string) ([]byte, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			data, err := get(ctx, client, a.URL, "application/octet-stream")
+			if err != nil {
+				return nil, fmt.Errorf("error downloading %s: %w", name, err)
+			}
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no %s asset", r.Tag, name)
+}
+
+// SignedMessage returns the message signed for the release tagged tag: the tag on its own line, followed
+// by the checksums file.
+func SignedMessage(tag string, checksums []byte) []byte {
+	return append([]byte(tag+"\n"), checksums...)
+}
+
+// VerifySignature checks signature to be the ed25519 signature by publicKey of the SignedMessage of tag
+// and checksums.
+func VerifySignature(publicKey ed25519.PublicKey, tag string, checksums, signature []byte) error {
+	// signatures may be published hex encoded or raw
+	if decoded, err := hex.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		signature = decoded
+	}
+	if len(publicKey) != ed25519.PublicKeySize || !ed25519.Verify(publicKey, SignedMessage(tag, checksums), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifyChecksum checks the SHA-256 checksum of data to be the one listed for name in checksums.
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sha256sum prefixes the names of files hashed in binary mode with a *
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("%w: %s has checksum %x, not %s", ErrChecksumMismatch, name, sum, fields[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum for %s", name)
+}
+
+// Replace atomically replaces the executable at path with data, keeping its permissions.
+func Replace(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func get(ctx context.Context, client *http.Client, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxAssetSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/base-org/withdrawer/withdraw"
+)
+
+// runExportViem implements the export-viem subcommand, which writes the withdrawals initiated by
+// an L2 transaction in the JSON shape of viem's getWithdrawals, to hand them to a frontend built with viem.
+// The other way around, --withdrawal-json takes such a withdrawal instead of --withdrawal.
+func runExportViem(args []string) {
+	fs := flag.NewFlagSet("export-viem", flag.ExitOnError)
+	f := registerFlags(fs)
+	var out string
+	fs.StringVar(&out, "out", "", "File to write the withdrawals to (default: stdout)")
+	_ = fs.Parse(args)
+
+	n := f.resolveNetwork()
+	withdrawal := f.withdrawalHash()
+	ctx := interruptContext()
+
+	l2RPC, err := n.dialL2(ctx)
+	if err != nil {
+		log.Crit("Error dialing L2 client", "error", err)
+	}
+	exported, err := withdraw.GetViemWithdrawals(ctx, ethclient.NewClient(l2RPC), withdrawal)
+	if err != nil {
+		log.Crit("Error querying withdrawals", "error", err)
+	}
+	if out == "" {
+		printJSON(exported)
+		return
+	}
+	data, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		log.Crit("Error encoding withdrawals", "error", err)
+	}
+	if err := os.WriteFile(out, append(data, '\n'), 0o644); err != nil {
+		log.Crit("Error writing withdrawals", "error", err)
+	}
+	fmt.Printf("Wrote %d withdrawal(s) initiated by %s to %s\n", len(exported), withdrawal, out)
+}
@@ -0,0 +1,171 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// PortalEvent is a WithdrawalProven or WithdrawalFinalized event of a portal, with the withdrawal decoded
+// from the calldata of the transaction that emitted it.
+type PortalEvent struct {
+	Action         string
+	BlockNumber    uint64
+	Time           time.Time
+	TxHash         common.Hash
+	LogIndex       uint
+	WithdrawalHash common.Hash
+	Submitter      common.Address
+	// Success is whether the call to the L1 target succeeded, and is only meaningful for finalizations.
+	Success bool
+	// Withdrawal is nil if the portal wasn't called directly by the transaction, e.g. by a multisig.
+	Withdrawal *bindings.TypesWithdrawalTransaction
+	// Route is set with Withdrawal.
+	Route *Route
+}
+
+// PortalActivity scans the portal's WithdrawalProven and WithdrawalFinalized events between fromBlock and
+// toBlock (inclusive), in chunks of chunkSize blocks, and passes them to fn in block order, so that large
+// ranges can be exported as they are scanned.
+func PortalActivity(ctx context.Context, l1 *ethclient.Client, portalAddress common.Address, fromBlock, toBlock, chunkSize uint64, fn func(PortalEvent) error) error {
+	if chunkSize == 0 {
+		return errZeroChunkSize
+	}
+	// the events are identical in OptimismPortal and OptimismPortal2
+	portal, err := bindings.NewOptimismPortalFilterer(portalAddress, l1)
+	if err != nil {
+		return err
+	}
+	for start := fromBlock; start <= toBlock; start += chunkSize {
+		end := start + chunkSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+		opts := &bind.FilterOpts{Start: start, End: &end, Context: ctx}
+
+		var events []PortalEvent
+		proven, err := portal.FilterWithdrawalProven(opts, nil, nil, nil)
+		if err != nil {
+			return fmt.Errorf("error filtering WithdrawalProven events in blocks %d-%d: %w", start, end, err)
+		}
+		for proven.Next() {
+			events = append(events, portalEvent("prove", proven.Event.Raw, proven.Event.WithdrawalHash, true))
+		}
+		if err := proven.Error(); err != nil {
+			return err
+		}
+		proven.Close()
+
+		finalized, err := portal.FilterWithdrawalFinalized(opts, nil)
+		if err != nil {
+			return fmt.Errorf("error filtering WithdrawalFinalized events in blocks %d-%d: %w", start, end, err)
+		}
+		for finalized.Next() {
+			events = append(events, portalEvent("finalize", finalized.Event.Raw, finalized.Event.WithdrawalHash, finalized.Event.Success))
+		}
+		if err := finalized.Error(); err != nil {
+			return err
+		}
+		finalized.Close()
+
+		sort.Slice(events, func(i, j int) bool {
+			if events[i].BlockNumber != events[j].BlockNumber {
+				return events[i].BlockNumber < events[j].BlockNumber
+			}
+			return events[i].LogIndex < events[j].LogIndex
+		})
+		times := make(map[uint64]time.Time)
+		for i := range events {
+			e := &events[i]
+			if err := e.decode(ctx, l1, portalAddress); err != nil {
+				return err
+			}
+			if _, ok := times[e.BlockNumber]; !ok {
+				header, err := l1.HeaderByNumber(ctx, new(big.Int).SetUint64(e.BlockNumber))
+				if err != nil {
+					return fmt.Errorf("error querying block %d: %w", e.BlockNumber, err)
+				}
+				times[e.BlockNumber] = time.Unix(int64(header.Time), 0).UTC()
+			}
+			e.Time = times[e.BlockNumber]
+			if err := fn(*e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func portalEvent(action string, raw types.Log, withdrawalHash [32]byte, success bool) PortalEvent {
+	return PortalEvent{
+		Action:         action,
+		BlockNumber:    raw.BlockNumber,
+		TxHash:         raw.TxHash,
+		LogIndex:       raw.Index,
+		WithdrawalHash: withdrawalHash,
+		Success:        success,
+	}
+}
+
+// decode sets the submitter of the event's transaction, and the withdrawal and its route from its calldata
+// if it called the portal directly.
+func (e *PortalEvent) decode(ctx context.Context, l1 *ethclient.Client, portalAddress common.Address) error {
+	tx, _, err := l1.TransactionByHash(ctx, e.TxHash)
+	if err != nil {
+		return fmt.Errorf("error querying tx %s: %w", e.TxHash, err)
+	}
+	e.Submitter, err = types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+	if err != nil {
+		return fmt.Errorf("error recovering sender of tx %s: %w", e.TxHash, err)
+	}
+	if tx.To() == nil || *tx.To() != portalAddress {
+		return nil
+	}
+	e.Withdrawal = decodeWithdrawalTransaction(tx.Data())
+	if e.Withdrawal != nil {
+		route := DecodeRoute(e.Withdrawal.Sender, e.Withdrawal.Target, e.Withdrawal.Data)
+		e.Route = &route
+	}
+	return nil
+}
+
+// decodeWithdrawalTransaction decodes the withdrawal passed to a prove or finalize method of a portal,
+// its first argument, or returns nil if data isn't such a call.
+func decodeWithdrawalTransaction(data []byte) *bindings.TypesWithdrawalTransaction {
+	_, wtx := decodePortalCall(data)
+	return wtx
+}
+
+// decodePortalCall returns the name of the prove or finalize method of a portal called with data, and the
+// withdrawal passed to it, or nil if data isn't such a call.
+func decodePortalCall(data []byte) (string, *bindings.TypesWithdrawalTransaction) {
+	if len(data) < 4 {
+		return "", nil
+	}
+	abis := loadRevertABIs()
+	if parsed, err := abi.JSON(strings.NewReader(superRootPortalABI)); err == nil {
+		abis = append(abis[:len(abis):len(abis)], &parsed)
+	}
+	for _, parsed := range abis {
+		method, err := parsed.MethodById(data[:4])
+		if err != nil || len(method.Inputs) == 0 || method.Inputs[0].Name != "_tx" {
+			continue
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			return "", nil
+		}
+		return method.Name, abi.ConvertType(args[0], new(bindings.TypesWithdrawalTransaction)).(*bindings.TypesWithdrawalTransaction)
+	}
+	return "", nil
+}
@@ -0,0 +1,232 @@
+package withdraw
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// OptimismPortalAdapter is the adapter for the pre-fault-proofs OptimismPortal.
+	OptimismPortalAdapter = "optimism-portal"
+	// OptimismPortal2Adapter is the adapter for the fault proofs OptimismPortal2.
+	OptimismPortal2Adapter = "optimism-portal2"
+)
+
+// PortalAdapter abstracts the portal calls needed to prove and finalize a withdrawal, so that OP Stack
+// forks with modified method signatures or proof formats can be supported without changing the withdrawers.
+type PortalAdapter interface {
+	// ProveWithdrawalTransaction submits a withdrawal proof. The meaning of params.L2OutputIndex
+	// depends on the portal (output index for OptimismPortal, dispute game index for OptimismPortal2).
+	ProveWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error)
+	// FinalizeWithdrawalTransaction submits a finalization for a proven withdrawal.
+	FinalizeWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error)
+	// ProvenWithdrawal returns the proof of the withdrawal submitted by submitter, whose timestamp is 0 if unproven.
+	ProvenWithdrawal(opts *bind.CallOpts, withdrawalHash common.Hash, submitter common.Address) (ProvenWithdrawal, error)
+	// IsWithdrawalFinalized returns whether the withdrawal has been finalized.
+	IsWithdrawalFinalized(opts *bind.CallOpts, withdrawalHash common.Hash) (bool, error)
+	// CheckWithdrawal returns the revert error of the portal if the withdrawal proven by submitter can't
+	// be finalized yet. It is only supported by fault proofs portals.
+	CheckWithdrawal(opts *bind.CallOpts, withdrawalHash common.Hash, submitter common.Address) error
+	// RespectedGameType returns the type of the dispute games withdrawals are proven against. It is only
+	// supported by fault proofs portals.
+	RespectedGameType(opts *bind.CallOpts) (uint32, error)
+}
+
+// ProvenWithdrawal is the proof of a withdrawal recorded by the portal.
+type ProvenWithdrawal struct {
+	// DisputeGame is the dispute game the withdrawal was proven against, which is unset before fault proofs.
+	DisputeGame common.Address
+	// Timestamp is the time the withdrawal was proven at, or 0 if it is unproven.
+	Timestamp uint64
+}
+
+// errNotFaultProofs is returned by the OptimismPortal adapter for the calls only fault proofs portals support.
+var errNotFaultProofs = errors.New("not supported by the pre-fault-proofs OptimismPortal")
+
+// PortalAdapterFactory binds a PortalAdapter to the portal deployed at address.
+type PortalAdapterFactory func(address common.Address, backend bind.ContractBackend) (PortalAdapter, error)
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[string]PortalAdapterFactory{
+		OptimismPortalAdapter:  newOptimismPortalAdapter,
+		OptimismPortal2Adapter: newOptimismPortal2Adapter,
+	}
+)
+
+// RegisterPortalAdapter makes a custom portal adapter available under name. It is intended to be
+// called from an init function by packages embedding the withdrawer.
+func RegisterPortalAdapter(name string, factory PortalAdapterFactory) error {
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	if _, ok := adapters[name]; ok {
+		return fmt.Errorf("portal adapter %q is already registered", name)
+	}
+	adapters[name] = factory
+	return nil
+}
+
+// PortalAdapters returns the names of all registered portal adapters.
+func PortalAdapters() []string {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	var names []string
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPortalAdapter binds the registered adapter called name to the portal at address.
+func NewPortalAdapter(name string, address common.Address, backend bind.ContractBackend) (PortalAdapter, error) {
+	adaptersMu.RLock()
+	factory, ok := adapters[name]
+	adaptersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown portal adapter %q", name)
+	}
+	return factory(address, backend)
+}
+
+type optimismPortalAdapter struct {
+	portal *bindings.OptimismPortal
+}
+
+func newOptimismPortalAdapter(address common.Address, backend bind.ContractBackend) (PortalAdapter, error) {
+	portal, err := bindings.NewOptimismPortal(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &optimismPortalAdapter{portal: portal}, nil
+}
+
+func (a *optimismPortalAdapter) ProveWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	return a.portal.ProveWithdrawalTransaction(
+		opts,
+		bindings.TypesWithdrawalTransaction{
+			Nonce:    params.Nonce,
+			Sender:   params.Sender,
+			Target:   params.Target,
+			Value:    params.Value,
+			GasLimit: params.GasLimit,
+			Data:     params.Data,
+		},
+		params.L2OutputIndex,
+		params.OutputRootProof,
+		params.WithdrawalProof,
+	)
+}
+
+func (a *optimismPortalAdapter) FinalizeWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	return a.portal.FinalizeWithdrawalTransaction(
+		opts,
+		bindings.TypesWithdrawalTransaction{
+			Nonce:    params.Nonce,
+			Sender:   params.Sender,
+			Target:   params.Target,
+			Value:    params.Value,
+			GasLimit: params.GasLimit,
+			Data:     params.Data,
+		},
+	)
+}
+
+func (a *optimismPortalAdapter) ProvenWithdrawal(opts *bind.CallOpts, withdrawalHash common.Hash, _ common.Address) (ProvenWithdrawal, error) {
+	// proofs are not tracked per submitter before fault proofs
+	provenWithdrawal, err := a.portal.ProvenWithdrawals(opts, withdrawalHash)
+	if err != nil {
+		return ProvenWithdrawal{}, err
+	}
+	return ProvenWithdrawal{Timestamp: provenWithdrawal.Timestamp.Uint64()}, nil
+}
+
+func (a *optimismPortalAdapter) IsWithdrawalFinalized(opts *bind.CallOpts, withdrawalHash common.Hash) (bool, error) {
+	return a.portal.FinalizedWithdrawals(opts, withdrawalHash)
+}
+
+func (a *optimismPortalAdapter) CheckWithdrawal(*bind.CallOpts, common.Hash, common.Address) error {
+	return fmt.Errorf("checkWithdrawal is %w", errNotFaultProofs)
+}
+
+func (a *optimismPortalAdapter) RespectedGameType(*bind.CallOpts) (uint32, error) {
+	return 0, fmt.Errorf("respectedGameType is %w", errNotFaultProofs)
+}
+
+type optimismPortal2Adapter struct {
+	portal *bindingspreview.OptimismPortal2
+}
+
+func newOptimismPortal2Adapter(address common.Address, backend bind.ContractBackend) (PortalAdapter, error) {
+	portal, err := bindingspreview.NewOptimismPortal2(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &optimismPortal2Adapter{portal: portal}, nil
+}
+
+func (a *optimismPortal2Adapter) ProveWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	return a.portal.ProveWithdrawalTransaction(
+		opts,
+		bindingspreview.TypesWithdrawalTransaction{
+			Nonce:    params.Nonce,
+			Sender:   params.Sender,
+			Target:   params.Target,
+			Value:    params.Value,
+			GasLimit: params.GasLimit,
+			Data:     params.Data,
+		},
+		params.L2OutputIndex, // this is overloaded and is the DisputeGame index in this context
+		bindingspreview.TypesOutputRootProof{
+			Version:                  params.OutputRootProof.Version,
+			StateRoot:                params.OutputRootProof.StateRoot,
+			MessagePasserStorageRoot: params.OutputRootProof.MessagePasserStorageRoot,
+			LatestBlockhash:          params.OutputRootProof.LatestBlockhash,
+		},
+		params.WithdrawalProof,
+	)
+}
+
+func (a *optimismPortal2Adapter) FinalizeWithdrawalTransaction(opts *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	return a.portal.FinalizeWithdrawalTransaction(
+		opts,
+		bindingspreview.TypesWithdrawalTransaction{
+			Nonce:    params.Nonce,
+			Sender:   params.Sender,
+			Target:   params.Target,
+			Value:    params.Value,
+			GasLimit: params.GasLimit,
+			Data:     params.Data,
+		},
+	)
+}
+
+func (a *optimismPortal2Adapter) ProvenWithdrawal(opts *bind.CallOpts, withdrawalHash common.Hash, submitter common.Address) (ProvenWithdrawal, error) {
+	// the proven withdrawal structure now contains an additional mapping, as withdrawal proofs are now stored per submitter address
+	provenWithdrawal, err := a.portal.ProvenWithdrawals(opts, withdrawalHash, submitter)
+	if err != nil {
+		return ProvenWithdrawal{}, err
+	}
+	return ProvenWithdrawal{DisputeGame: provenWithdrawal.DisputeGameProxy, Timestamp: provenWithdrawal.Timestamp}, nil
+}
+
+func (a *optimismPortal2Adapter) IsWithdrawalFinalized(opts *bind.CallOpts, withdrawalHash common.Hash) (bool, error) {
+	return a.portal.FinalizedWithdrawals(opts, withdrawalHash)
+}
+
+func (a *optimismPortal2Adapter) CheckWithdrawal(opts *bind.CallOpts, withdrawalHash common.Hash, submitter common.Address) error {
+	return a.portal.CheckWithdrawal(opts, withdrawalHash, submitter)
+}
+
+func (a *optimismPortal2Adapter) RespectedGameType(opts *bind.CallOpts) (uint32, error) {
+	return a.portal.RespectedGameType(opts)
+}
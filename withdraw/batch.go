@@ -0,0 +1,126 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// batchCall is a view call to a contract, made as part of a batch by callBatch.
+type batchCall struct {
+	abi    *abi.ABI
+	to     common.Address
+	method string
+	args   []interface{}
+	// out points to the variable the single return value of method is stored in.
+	out interface{}
+	// constant marks calls whose result can't change, such as contract constants, which are only
+	// queried once per run.
+	constant bool
+}
+
+type constantKey struct {
+	chainID uint64
+	to      common.Address
+	method  string
+}
+
+// constants caches the results of constant calls, keyed by chain, contract and method.
+var constants sync.Map
+
+// chainIDs caches the chain IDs of the JSON-RPC clients constant calls are made with.
+var chainIDs sync.Map
+
+// chainID returns the chain ID of client, which is only queried once per JSON-RPC client.
+func chainID(ctx context.Context, client L1Client) (uint64, error) {
+	rc, ok := client.(interface{ Client() *rpc.Client })
+	if ok {
+		if id, ok := chainIDs.Load(rc.Client()); ok {
+			return id.(uint64), nil
+		}
+	}
+	id, err := client.ChainID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error querying chain ID: %w", err)
+	}
+	if ok {
+		chainIDs.Store(rc.Client(), id.Uint64())
+	}
+	return id.Uint64(), nil
+}
+
+// callBatch makes the calls at the latest block in a single batched JSON-RPC request, saving the round trips
+// of calling them one by one. Clients without access to the underlying JSON-RPC client, such as mocks, are
+// called one by one instead.
+func callBatch(ctx context.Context, client L1Client, calls ...batchCall) error {
+	results := make([]hexutil.Bytes, len(calls))
+	queried := make([]int, 0, len(calls))
+	data := make([][]byte, len(calls))
+	var chain uint64
+	for _, c := range calls {
+		if c.constant {
+			var err error
+			if chain, err = chainID(ctx, client); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	for i, c := range calls {
+		if c.constant {
+			if cached, ok := constants.Load(constantKey{chain, c.to, c.method}); ok {
+				results[i] = cached.(hexutil.Bytes)
+				continue
+			}
+		}
+		packed, err := c.abi.Pack(c.method, c.args...)
+		if err != nil {
+			return fmt.Errorf("error packing %s call: %w", c.method, err)
+		}
+		data[i] = packed
+		queried = append(queried, i)
+	}
+	if rc, ok := client.(interface{ Client() *rpc.Client }); ok && len(queried) > 0 {
+		elems := make([]rpc.BatchElem, len(queried))
+		for j, i := range queried {
+			elems[j] = rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{map[string]interface{}{"to": calls[i].to, "data": hexutil.Bytes(data[i])}, "latest"},
+				Result: &results[i],
+			}
+		}
+		if err := rc.Client().BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		for j, i := range queried {
+			if elems[j].Error != nil {
+				return fmt.Errorf("error calling %s: %w", calls[i].method, elems[j].Error)
+			}
+		}
+	} else {
+		for _, i := range queried {
+			out, err := client.CallContract(ctx, ethereum.CallMsg{To: &calls[i].to, Data: data[i]}, nil)
+			if err != nil {
+				return fmt.Errorf("error calling %s: %w", calls[i].method, err)
+			}
+			results[i] = out
+		}
+	}
+	for _, i := range queried {
+		if calls[i].constant {
+			constants.Store(constantKey{chain, calls[i].to, calls[i].method}, results[i])
+		}
+	}
+	for i, c := range calls {
+		if err := c.abi.UnpackIntoInterface(c.out, c.method, results[i]); err != nil {
+			return fmt.Errorf("error unpacking %s result: %w", c.method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package withdraw
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// MethodSignature returns the signature of the portal method called with data, e.g. to decode the
+// calldata of a transaction built with BuildOnly in other tooling, or an empty string if it isn't known.
+func MethodSignature(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	abis := loadRevertABIs()
+	if parsed, err := abi.JSON(strings.NewReader(superRootPortalABI)); err == nil {
+		abis = append(abis[:len(abis):len(abis)], &parsed)
+	}
+	for _, parsed := range abis {
+		if method, err := parsed.MethodById(data[:4]); err == nil {
+			return method.Sig
+		}
+	}
+	return ""
+}
@@ -0,0 +1,178 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CheckNetworkContracts checks the contracts of a custom network on L1, to report mistyped addresses
+// before they cause confusing failures: code must exist at the portal and at the L2OutputOracle, or the
+// DisputeGameFactory on fault proofs chains, the portal's version must match faultProofs, and the portal
+// must reference that L2OutputOracle or DisputeGameFactory.
+func CheckNetworkContracts(ctx context.Context, l1 L1Client, portal, oracle, factory common.Address, faultProofs bool) error {
+	contracts := []struct {
+		name    string
+		address common.Address
+	}{{"OptimismPortal", portal}, {"L2OutputOracle", oracle}}
+	if faultProofs {
+		contracts[1].name, contracts[1].address = "DisputeGameFactory", factory
+	}
+	for _, c := range contracts {
+		code, err := l1.CodeAt(ctx, c.address, nil)
+		if err != nil {
+			return fmt.Errorf("error querying code of %s %s: %w", c.name, c.address, err)
+		}
+		if len(code) == 0 {
+			return fmt.Errorf("%w: there is no contract at %s address %s on this L1 chain", ErrInvalidNetwork, c.name, c.address)
+		}
+	}
+
+	opts := &bind.CallOpts{Context: ctx}
+	p, err := bindings.NewOptimismPortalCaller(portal, l1)
+	if err != nil {
+		return err
+	}
+	version, err := p.Version(opts)
+	if err != nil {
+		return fmt.Errorf("%w: %s doesn't report a version, so it isn't an OptimismPortal: %w", ErrInvalidNetwork, portal, err)
+	}
+	// OptimismPortal2, which proves withdrawals against dispute games, starts at version 3
+	major, _, _ := strings.Cut(version, ".")
+	if m, err := strconv.Atoi(major); err != nil {
+		return fmt.Errorf("%w: OptimismPortal %s has unexpected version %q", ErrInvalidNetwork, portal, version)
+	} else if faultProofs && m < 3 {
+		return fmt.Errorf("%w: OptimismPortal %s is version %s, which predates fault proofs, so withdrawals must be proven against its L2OutputOracle", ErrInvalidNetwork, portal, version)
+	} else if !faultProofs && m >= 3 {
+		return fmt.Errorf("%w: OptimismPortal %s is version %s, which uses fault proofs, so withdrawals must be proven against its DisputeGameFactory", ErrInvalidNetwork, portal, version)
+	}
+
+	if faultProofs {
+		p2, err := bindingspreview.NewOptimismPortal2Caller(portal, l1)
+		if err != nil {
+			return err
+		}
+		referenced, err := p2.DisputeGameFactory(opts)
+		if err != nil {
+			return fmt.Errorf("error querying DisputeGameFactory of portal %s: %w", portal, err)
+		}
+		if referenced != factory {
+			return fmt.Errorf("%w: OptimismPortal %s uses DisputeGameFactory %s, not %s", ErrInvalidNetwork, portal, referenced, factory)
+		}
+		return nil
+	}
+	referenced, err := p.L2Oracle(opts)
+	if err != nil {
+		return fmt.Errorf("error querying L2OutputOracle of portal %s: %w", portal, err)
+	}
+	if referenced != oracle {
+		return fmt.Errorf("%w: OptimismPortal %s uses L2OutputOracle %s, not %s", ErrInvalidNetwork, portal, referenced, oracle)
+	}
+	return nil
+}
+
+// CheckPortalChain checks the portal to belong to the L2 chain of l2, to catch mistyped addresses of
+// custom networks before signing. On fault proofs chains, the latest dispute game of the portal's
+// DisputeGameFactory must be for the chain ID of l2. Otherwise, the latest output of the portal's
+// L2OutputOracle must match the output root computed from l2.
+func CheckPortalChain(ctx context.Context, l1 L1Client, l2 L2Client, portal common.Address, faultProofs bool) error {
+	opts := &bind.CallOpts{Context: ctx}
+	if !faultProofs {
+		p, err := bindings.NewOptimismPortalCaller(portal, l1)
+		if err != nil {
+			return err
+		}
+		oracleAddress, err := p.L2Oracle(opts)
+		if err != nil {
+			return fmt.Errorf("error querying L2OutputOracle of portal %s: %w", portal, err)
+		}
+		return checkOracleChain(ctx, l1, l2, oracleAddress)
+	}
+
+	p, err := bindingspreview.NewOptimismPortal2Caller(portal, l1)
+	if err != nil {
+		return err
+	}
+	factoryAddress, err := p.DisputeGameFactory(opts)
+	if err != nil {
+		return fmt.Errorf("error querying DisputeGameFactory of portal %s: %w", portal, err)
+	}
+	factory, err := bindings.NewDisputeGameFactoryCaller(factoryAddress, l1)
+	if err != nil {
+		return err
+	}
+	count, err := factory.GameCount(opts)
+	if err != nil {
+		return fmt.Errorf("error querying dispute games: %w", err)
+	}
+	if count.Sign() == 0 {
+		return errors.New("the DisputeGameFactory of the portal has no dispute games")
+	}
+	game, err := factory.GameAtIndex(opts, new(big.Int).Sub(count, common.Big1))
+	if err != nil {
+		return fmt.Errorf("error querying latest dispute game: %w", err)
+	}
+	var out []interface{}
+	proxy := bind.NewBoundContract(game.Proxy, *snapshots.LoadFaultDisputeGameABI(), l1, nil, nil)
+	if err := proxy.Call(opts, &out, "l2ChainId"); err != nil {
+		return fmt.Errorf("error querying L2 chain ID of dispute game %s: %w", game.Proxy, err)
+	}
+	gameChainID := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+	chainID, err := l2.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("error querying L2 chain ID: %w", err)
+	}
+	if gameChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("%w: the dispute games of portal %s are for L2 chain %d, but the L2 RPC is for chain %d", ErrWrongChain, portal, gameChainID, chainID)
+	}
+	return nil
+}
+
+// checkOracleChain checks the latest output of the L2OutputOracle to match the output root of its L2
+// block computed from l2.
+func checkOracleChain(ctx context.Context, l1 L1Client, l2 L2Client, oracleAddress common.Address) error {
+	oracle, err := bindings.NewL2OutputOracleCaller(oracleAddress, l1)
+	if err != nil {
+		return err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	index, err := oracle.LatestOutputIndex(opts)
+	if err != nil {
+		return fmt.Errorf("error querying latest output: %w", err)
+	}
+	output, err := oracle.GetL2Output(opts, index)
+	if err != nil {
+		return fmt.Errorf("error querying output %d: %w", index, err)
+	}
+
+	header, err := l2.HeaderByNumber(ctx, output.L2BlockNumber)
+	if err != nil {
+		return fmt.Errorf("%w: L2 block %d of the latest output can't be queried from the L2 RPC: %w", ErrWrongChain, output.L2BlockNumber, err)
+	}
+	proof, err := l2.GetProof(ctx, predeploys.L2ToL1MessagePasserAddr, nil, output.L2BlockNumber)
+	if err != nil {
+		return fmt.Errorf("error querying MessagePasser storage root: %w", err)
+	}
+	root := outputRoot(bindings.TypesOutputRootProof{
+		StateRoot:                header.Root,
+		MessagePasserStorageRoot: proof.StorageHash,
+		LatestBlockhash:          header.Hash(),
+	})
+	if root != output.OutputRoot {
+		return fmt.Errorf("%w: output %d of L2OutputOracle %s has root %s, but the L2 RPC computes %s for L2 block %d",
+			ErrWrongChain, index, oracleAddress, common.Hash(output.OutputRoot), root, output.L2BlockNumber)
+	}
+	return nil
+}
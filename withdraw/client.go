@@ -0,0 +1,62 @@
+package withdraw
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+//go:generate go run go.uber.org/mock/mockgen -destination mocks/clients.go -package mocks . L1Client,L2Client,SupervisorClient,RollupClient
+
+// L1Client is the part of *ethclient.Client the helpers use, so that they can be run against mocks.
+// Contract calls are batched if the client also has a Client() *rpc.Client method, as *ethclient.Client does.
+type L1Client interface {
+	bind.ContractBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// L2Client is the part of the L2 JSON-RPC API the helpers use, to query the withdrawal and prove it.
+type L2Client interface {
+	withdrawals.ProofClient
+	withdrawals.ReceiptClient
+	withdrawals.BlockClient
+	ChainID(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+}
+
+// SupervisorClient is the op-supervisor JSON-RPC client super roots are queried from.
+type SupervisorClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// RollupClient is the op-node JSON-RPC client output roots are checked against.
+type RollupClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// NewL2Client returns the L2Client of the JSON-RPC client c.
+func NewL2Client(c *rpc.Client) L2Client {
+	return l2Client{Client: ethclient.NewClient(c), proofs: gethclient.New(c)}
+}
+
+type l2Client struct {
+	*ethclient.Client
+	proofs *gethclient.Client
+}
+
+func (c l2Client) GetProof(ctx context.Context, account common.Address, keys []string, blockNumber *big.Int) (*gethclient.AccountResult, error) {
+	return c.proofs.GetProof(ctx, account, keys, blockNumber)
+}
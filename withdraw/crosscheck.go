@@ -0,0 +1,134 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CrossChecker re-reads the L1 data a prove or finalize transaction relies on from a second, independent
+// provider before it is sent, so that a compromised or faulty primary provider can't get a withdrawal
+// proven against a bogus output or finalized early. Its methods do nothing on a nil CrossChecker.
+type CrossChecker struct {
+	Client L1Client
+	Portal common.Address
+	// Oracle is set on chains without fault proofs, and Factory on fault proofs chains.
+	Oracle  common.Address
+	Factory common.Address
+}
+
+// outputRoot computes the output root committed to by proof.
+func outputRoot(proof bindings.TypesOutputRootProof) common.Hash {
+	return crypto.Keccak256Hash(proof.Version[:], proof.StateRoot[:], proof.MessagePasserStorageRoot[:], proof.LatestBlockhash[:])
+}
+
+// checkOutput checks the L2OutputOracle output at index to have root.
+func (v *CrossChecker) checkOutput(ctx context.Context, index *big.Int, root common.Hash) error {
+	if v == nil {
+		return nil
+	}
+	oracle, err := bindings.NewL2OutputOracleCaller(v.Oracle, v.Client)
+	if err != nil {
+		return err
+	}
+	output, err := oracle.GetL2Output(&bind.CallOpts{Context: ctx}, index)
+	if err != nil {
+		return fmt.Errorf("error querying output %d from the verification RPC: %w", index, err)
+	}
+	if output.OutputRoot != root {
+		return fmt.Errorf("%w: output %d has root %s, not %s", ErrRPCDivergence, index, common.Hash(output.OutputRoot), root)
+	}
+	return nil
+}
+
+// checkGame checks the dispute game at index to have the root claim root.
+func (v *CrossChecker) checkGame(ctx context.Context, index *big.Int, root common.Hash) error {
+	if v == nil {
+		return nil
+	}
+	factory, err := bindings.NewDisputeGameFactoryCaller(v.Factory, v.Client)
+	if err != nil {
+		return err
+	}
+	_, claim, err := gameClaim(ctx, v.Client, factory, index)
+	if err != nil {
+		return fmt.Errorf("error querying the verification RPC: %w", err)
+	}
+	if claim != root {
+		return fmt.Errorf("%w: dispute game %d has root claim %s, not %s", ErrRPCDivergence, index, claim, root)
+	}
+	return nil
+}
+
+// checkProven checks the proof of the withdrawal with the given hash submitted by from to be recorded
+// identically by both providers and, on fault proofs chains, to be finalizable according to the
+// verification provider.
+func (v *CrossChecker) checkProven(ctx context.Context, primary L1Client, hash common.Hash, from common.Address) error {
+	if v == nil {
+		return nil
+	}
+	if v.Factory == (common.Address{}) {
+		return v.checkProvenOutput(ctx, primary, hash)
+	}
+
+	var records [2]struct {
+		DisputeGameProxy common.Address
+		Timestamp        uint64
+	}
+	for i, client := range []L1Client{primary, v.Client} {
+		portal, err := bindingspreview.NewOptimismPortal2Caller(v.Portal, client)
+		if err != nil {
+			return err
+		}
+		if records[i], err = portal.ProvenWithdrawals(&bind.CallOpts{Context: ctx}, hash, from); err != nil {
+			return fmt.Errorf("error querying proven withdrawal: %w", err)
+		}
+	}
+	if records[0] != records[1] {
+		return fmt.Errorf("%w: proven withdrawal is against game %s at %d, not game %s at %d",
+			ErrRPCDivergence, records[1].DisputeGameProxy, records[1].Timestamp, records[0].DisputeGameProxy, records[0].Timestamp)
+	}
+
+	portal, err := bindingspreview.NewOptimismPortal2Caller(v.Portal, v.Client)
+	if err != nil {
+		return err
+	}
+	if err := decodeRevert(portal.CheckWithdrawal(&bind.CallOpts{Context: ctx}, hash, from)); err != nil {
+		return fmt.Errorf("%w: withdrawal is not finalizable: %w", ErrRPCDivergence, err)
+	}
+	return nil
+}
+
+// checkProvenOutput checks the proof of the withdrawal with the given hash to be recorded identically by
+// both providers, against the output the verification provider has at its index.
+func (v *CrossChecker) checkProvenOutput(ctx context.Context, primary L1Client, hash common.Hash) error {
+	var records [2]struct {
+		OutputRoot    [32]byte
+		Timestamp     *big.Int
+		L2OutputIndex *big.Int
+	}
+	for i, client := range []L1Client{primary, v.Client} {
+		portal, err := bindings.NewOptimismPortalCaller(v.Portal, client)
+		if err != nil {
+			return err
+		}
+		if records[i], err = portal.ProvenWithdrawals(&bind.CallOpts{Context: ctx}, hash); err != nil {
+			return fmt.Errorf("error querying proven withdrawal: %w", err)
+		}
+	}
+	if records[0].OutputRoot != records[1].OutputRoot || records[0].Timestamp.Cmp(records[1].Timestamp) != 0 ||
+		records[0].L2OutputIndex.Cmp(records[1].L2OutputIndex) != 0 {
+		return fmt.Errorf("%w: proven withdrawal is against output %d at %d, not output %d at %d",
+			ErrRPCDivergence, records[1].L2OutputIndex, records[1].Timestamp, records[0].L2OutputIndex, records[0].Timestamp)
+	}
+	if records[1].Timestamp.Sign() == 0 {
+		return fmt.Errorf("%w: %w", ErrRPCDivergence, ErrNotProven)
+	}
+	return v.checkOutput(ctx, records[1].L2OutputIndex, records[1].OutputRoot)
+}
@@ -0,0 +1,61 @@
+package withdraw
+
+import "errors"
+
+// Errors returned by the withdraw helpers, possibly wrapped with details, for callers to branch on with errors.Is.
+var (
+	// ErrNotProvableYet is returned while no output or dispute game covers the L2 block of the withdrawal.
+	ErrNotProvableYet = errors.New("withdrawal is not provable yet")
+	// ErrNotProven is returned for withdrawals that haven't been proven by the sender.
+	ErrNotProven = errors.New("withdrawal is not proven")
+	// ErrChallengePeriodActive is returned while the proof or the output it was proven against hasn't matured.
+	ErrChallengePeriodActive = errors.New("challenge period is active")
+	// ErrGameNotResolved is returned while the dispute game the withdrawal was proven against hasn't resolved
+	// in favor of its root claim.
+	ErrGameNotResolved = errors.New("dispute game has not resolved in favor of the withdrawal")
+	// ErrGameInvalidated is returned when the dispute game the withdrawal was proven against can no longer be
+	// used, e.g. as it was blacklisted or the respected game type changed, so the withdrawal must be proven again.
+	ErrGameInvalidated = errors.New("dispute game was invalidated")
+	// ErrAlreadyFinalized is returned for withdrawals that have already been finalized.
+	ErrAlreadyFinalized = errors.New("withdrawal is already finalized")
+	// ErrNotWithdrawal is returned when the L2 transaction given as the withdrawal doesn't initiate one.
+	ErrNotWithdrawal = errors.New("transaction is not a withdrawal")
+	// ErrWithdrawalFailed is returned when the L2 transaction initiating the withdrawal reverted.
+	ErrWithdrawalFailed = errors.New("unsuccessful withdrawal receipt status")
+	// ErrDeliveryFailed is returned when a withdrawal was finalized, but its call to the L1 target failed.
+	ErrDeliveryFailed = errors.New("withdrawal was not delivered")
+	// ErrRPCDivergence is returned when the verification RPC set with WithVerifyClient disagrees with the
+	// L1 client about the data a transaction relies on, which is then not sent.
+	ErrRPCDivergence = errors.New("verification RPC disagrees with the L1 RPC")
+	// ErrOutputRootMismatch is returned when the output root a withdrawal would be proven against differs
+	// from the one computed by the rollup node set with WithRollupClient.
+	ErrOutputRootMismatch = errors.New("output root does not match the rollup node")
+	// ErrInvalidProof is returned when the storage proof generated for a withdrawal doesn't verify against
+	// the output root it would be proven against, so that the prove transaction would revert.
+	ErrInvalidProof = errors.New("invalid withdrawal proof")
+	// ErrWrongChain is returned by CheckPortalChain when the portal doesn't belong to the L2 chain.
+	ErrWrongChain = errors.New("portal does not belong to the L2 chain")
+	// ErrInvalidNetwork is returned by CheckNetworkContracts when the contracts of a network don't match
+	// its configuration.
+	ErrInvalidNetwork = errors.New("invalid network contracts")
+)
+
+// errZeroChunkSize is returned by the functions scanning logs in chunks of blocks when the chunk size is 0,
+// which would never advance.
+var errZeroChunkSize = errors.New("chunk size must be at least 1")
+
+// revertErrors maps the revert reasons of the OptimismPortal and OptimismPortal2 to the errors above.
+var revertErrors = map[string]error{
+	"OptimismPortal: withdrawal has already been finalized":                          ErrAlreadyFinalized,
+	"OptimismPortal: withdrawal has not been proven yet":                             ErrNotProven,
+	"OptimismPortal: withdrawal has not been proven by proof submitter address yet":  ErrNotProven,
+	"OptimismPortal: proven withdrawal finalization period has not elapsed":          ErrChallengePeriodActive,
+	"OptimismPortal: output proposal finalization period has not elapsed":            ErrChallengePeriodActive,
+	"OptimismPortal: proven withdrawal has not matured yet":                          ErrChallengePeriodActive,
+	"OptimismPortal: output proposal in air-gap":                                     ErrChallengePeriodActive,
+	"OptimismPortal: output proposal has not been validated":                         ErrGameNotResolved,
+	"OptimismPortal: dispute game has been blacklisted":                              ErrGameInvalidated,
+	"OptimismPortal: dispute game created before respected game type was updated":    ErrGameInvalidated,
+	"OptimismPortal: invalid game type":                                              ErrGameInvalidated,
+	"OptimismPortal: withdrawal timestamp less than dispute game creation timestamp": ErrGameInvalidated,
+}
@@ -0,0 +1,90 @@
+package withdraw
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ProofGenerator is implemented by withdrawers that can compute the proof parameters for a
+// withdrawal without submitting them.
+type ProofGenerator interface {
+	ProofParameters(ctx context.Context) (withdrawals.ProvenWithdrawalParameters, error)
+}
+
+// ProofArtifact is the on-disk representation of the parameters to proveWithdrawalTransaction,
+// suitable for audit, air-gapped signing, or multisig workflows.
+type ProofArtifact struct {
+	L2TxHash       common.Hash `json:"l2TxHash"`
+	WithdrawalHash common.Hash `json:"withdrawalHash"`
+	Withdrawal     struct {
+		Nonce    *hexutil.Big   `json:"nonce"`
+		Sender   common.Address `json:"sender"`
+		Target   common.Address `json:"target"`
+		Value    *hexutil.Big   `json:"value"`
+		GasLimit *hexutil.Big   `json:"gasLimit"`
+		Data     hexutil.Bytes  `json:"data"`
+	} `json:"withdrawal"`
+	// L2OutputIndex is the L2OutputOracle output index, or the dispute game index for fault proof portals.
+	L2OutputIndex   *hexutil.Big `json:"l2OutputIndex"`
+	OutputRootProof struct {
+		Version                  common.Hash `json:"version"`
+		StateRoot                common.Hash `json:"stateRoot"`
+		MessagePasserStorageRoot common.Hash `json:"messagePasserStorageRoot"`
+		LatestBlockhash          common.Hash `json:"latestBlockhash"`
+	} `json:"outputRootProof"`
+	WithdrawalProof []hexutil.Bytes `json:"withdrawalProof"`
+}
+
+// NewProofArtifact converts the proof parameters for the withdrawal initiated in l2TxHash to a ProofArtifact.
+func NewProofArtifact(l2TxHash common.Hash, params withdrawals.ProvenWithdrawalParameters) *ProofArtifact {
+	a := &ProofArtifact{L2TxHash: l2TxHash}
+	a.Withdrawal.Nonce = (*hexutil.Big)(params.Nonce)
+	a.Withdrawal.Sender = params.Sender
+	a.Withdrawal.Target = params.Target
+	a.Withdrawal.Value = (*hexutil.Big)(params.Value)
+	a.Withdrawal.GasLimit = (*hexutil.Big)(params.GasLimit)
+	a.Withdrawal.Data = params.Data
+	a.L2OutputIndex = (*hexutil.Big)(params.L2OutputIndex)
+	a.OutputRootProof.Version = params.OutputRootProof.Version
+	a.OutputRootProof.StateRoot = params.OutputRootProof.StateRoot
+	a.OutputRootProof.MessagePasserStorageRoot = params.OutputRootProof.MessagePasserStorageRoot
+	a.OutputRootProof.LatestBlockhash = params.OutputRootProof.LatestBlockhash
+	for _, node := range params.WithdrawalProof {
+		a.WithdrawalProof = append(a.WithdrawalProof, node)
+	}
+	return a
+}
+
+// ExportProof computes the proof parameters using g and writes them as JSON to path.
+func ExportProof(ctx context.Context, g ProofGenerator, l2TxHash common.Hash, path string) error {
+	params, err := g.ProofParameters(ctx)
+	if err != nil {
+		return fmt.Errorf("error generating proof parameters: %w", err)
+	}
+
+	artifact := NewProofArtifact(l2TxHash, params)
+	artifact.WithdrawalHash, err = withdrawals.WithdrawalHash(&bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    params.Nonce,
+		Sender:   params.Sender,
+		Target:   params.Target,
+		Value:    params.Value,
+		GasLimit: params.GasLimit,
+		Data:     params.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
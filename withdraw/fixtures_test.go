@@ -0,0 +1,296 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/triedb"
+	"go.uber.org/mock/gomock"
+
+	"github.com/base-org/withdrawer/withdraw/mocks"
+)
+
+var (
+	testSender = common.HexToAddress("0x5555555555555555555555555555555555555555")
+	testPortal = common.HexToAddress("0x6666666666666666666666666666666666666666")
+)
+
+// testSettings are the settings of the helpers under test, which don't print their progress.
+var testSettings = TxSettings{Logger: NewWriterLogger(io.Discard)}
+
+// testWithdrawal is a withdrawal initiated on L2, with the L2 state proving it.
+type testWithdrawal struct {
+	txHash  common.Hash
+	event   *bindings.L2ToL1MessagePasserMessagePassed
+	hash    common.Hash
+	receipt *types.Receipt
+	// block is the L2 block whose state root commits to the MessagePasser storage, and proof the
+	// eth_getProof result of the withdrawal's storage slot at that block.
+	block *types.Block
+	proof *gethclient.AccountResult
+}
+
+// proofList collects the nodes of a Merkle proof as hex strings, as eth_getProof returns them.
+type proofList []string
+
+func (l *proofList) Put(_ []byte, value []byte) error {
+	*l = append(*l, hexutil.Encode(value))
+	return nil
+}
+
+func (l *proofList) Delete([]byte) error {
+	return nil
+}
+
+// newTestWithdrawal returns a withdrawal included in L2 block includedIn, and proven by the state of L2
+// block provenAt.
+func newTestWithdrawal(t *testing.T, includedIn, provenAt uint64) *testWithdrawal {
+	t.Helper()
+	ev := &bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    new(big.Int).Lsh(big.NewInt(1), 240),
+		Sender:   common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		Target:   common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Value:    big.NewInt(1e18),
+		GasLimit: big.NewInt(100_000),
+		Data:     []byte{},
+	}
+	hash, err := withdrawals.WithdrawalHash(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev.WithdrawalHash = hash
+
+	passerABI, err := bindings.L2ToL1MessagePasserMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := passerABI.Events["MessagePassed"].Inputs.NonIndexed().Pack(ev.Value, ev.GasLimit, ev.Data, ev.WithdrawalHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHash := common.HexToHash("0xabcdef")
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		BlockNumber: new(big.Int).SetUint64(includedIn),
+		Logs: []*types.Log{{
+			Address: predeploys.L2ToL1MessagePasserAddr,
+			Topics: []common.Hash{
+				withdrawals.MessagePassedTopic,
+				common.BigToHash(ev.Nonce),
+				common.BytesToHash(ev.Sender.Bytes()),
+				common.BytesToHash(ev.Target.Bytes()),
+			},
+			Data: data,
+		}},
+	}
+
+	db := triedb.NewDatabase(rawdb.NewMemoryDatabase(), nil)
+	// the sentMessages mapping of the MessagePasser sets the slot of the withdrawal hash to true
+	slot := withdrawals.StorageSlotOfWithdrawalHash(hash)
+	storage := trie.NewEmpty(db)
+	storage.MustUpdate(crypto.Keccak256(slot[:]), []byte{1})
+	var storageProof proofList
+	if err := storage.Prove(crypto.Keccak256(slot[:]), &storageProof); err != nil {
+		t.Fatal(err)
+	}
+
+	account := types.NewEmptyStateAccount()
+	account.Root = storage.Hash()
+	encoded, err := rlp.EncodeToBytes(account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := trie.NewEmpty(db)
+	state.MustUpdate(crypto.Keccak256(predeploys.L2ToL1MessagePasserAddr[:]), encoded)
+	var accountProof proofList
+	if err := state.Prove(crypto.Keccak256(predeploys.L2ToL1MessagePasserAddr[:]), &accountProof); err != nil {
+		t.Fatal(err)
+	}
+
+	return &testWithdrawal{
+		txHash:  txHash,
+		event:   ev,
+		hash:    hash,
+		receipt: receipt,
+		block:   types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(provenAt), Root: state.Hash(), Time: 1_700_000_000 + 2*provenAt}),
+		proof: &gethclient.AccountResult{
+			Address:      predeploys.L2ToL1MessagePasserAddr,
+			AccountProof: accountProof,
+			Balance:      new(big.Int),
+			CodeHash:     types.EmptyCodeHash,
+			StorageHash:  storage.Hash(),
+			StorageProof: []gethclient.StorageResult{{Key: slot.String(), Value: big.NewInt(1), Proof: storageProof}},
+		},
+	}
+}
+
+// params returns the proof parameters of the withdrawal, proven against the output or dispute game index.
+func (w *testWithdrawal) params(index int64) withdrawals.ProvenWithdrawalParameters {
+	nodes := make([][]byte, len(w.proof.StorageProof[0].Proof))
+	for i, node := range w.proof.StorageProof[0].Proof {
+		nodes[i] = common.FromHex(node)
+	}
+	return withdrawals.ProvenWithdrawalParameters{
+		Nonce:         w.event.Nonce,
+		Sender:        w.event.Sender,
+		Target:        w.event.Target,
+		Value:         w.event.Value,
+		GasLimit:      w.event.GasLimit,
+		L2OutputIndex: big.NewInt(index),
+		Data:          w.event.Data,
+		OutputRootProof: bindings.TypesOutputRootProof{
+			StateRoot:                w.block.Root(),
+			MessagePasserStorageRoot: w.proof.StorageHash,
+			LatestBlockhash:          w.block.Hash(),
+		},
+		WithdrawalProof: nodes,
+	}
+}
+
+// l2Header returns the header of L2 block n, which are 2 seconds apart.
+func l2Header(n uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(n), Time: 1_700_000_000 + 2*n}
+}
+
+// expectL2 answers the L2 queries of the helpers through l2, with receipt or receiptErr for the withdrawal
+// transaction, and the state of w.block proving the withdrawal.
+func (w *testWithdrawal) expectL2(l2 *mocks.MockL2Client, receipt *types.Receipt, receiptErr error) {
+	l2.EXPECT().TransactionReceipt(gomock.Any(), w.txHash).Return(receipt, receiptErr).AnyTimes()
+	l2.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *big.Int) (*types.Header, error) {
+		return l2Header(n.Uint64()), nil
+	}).AnyTimes()
+	l2.EXPECT().BlockByNumber(gomock.Any(), gomock.Any()).Return(w.block, nil).AnyTimes()
+	l2.EXPECT().GetProof(gomock.Any(), predeploys.L2ToL1MessagePasserAddr, gomock.Any(), gomock.Any()).Return(w.proof, nil).AnyTimes()
+}
+
+// contract is a fake L1 contract, returning the outputs of its methods, or their errors.
+type contract struct {
+	abi     *abi.ABI
+	outputs map[string][]interface{}
+	errs    map[string]error
+}
+
+// expectCalls answers the calls made through l1 to the contracts at the given addresses, on chain 1.
+func expectCalls(t *testing.T, l1 *mocks.MockL1Client, contracts map[common.Address]contract) {
+	l1.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(1), nil).AnyTimes()
+	l1.EXPECT().CallContract(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+		c, ok := contracts[*msg.To]
+		if !ok {
+			t.Errorf("unexpected call to %s", msg.To)
+			return nil, errors.New("no contract")
+		}
+		method, err := c.abi.MethodById(msg.Data)
+		if err != nil {
+			t.Errorf("unexpected call to %s: %v", msg.To, err)
+			return nil, err
+		}
+		if err, ok := c.errs[method.Name]; ok {
+			return nil, err
+		}
+		out, ok := c.outputs[method.Name]
+		if !ok {
+			t.Errorf("unexpected call of %s on %s", method.Name, msg.To)
+			return nil, errors.New("unexpected call")
+		}
+		return method.Outputs.Pack(out...)
+	}).AnyTimes()
+}
+
+// expectSend makes l1 accept sent transactions with sendErr, and include them in block 100 with status
+// and logs. Head subscriptions fail, as over HTTP.
+func expectSend(l1 *mocks.MockL1Client, sendErr error, status uint64, logs []*types.Log) {
+	l1.EXPECT().SendTransaction(gomock.Any(), gomock.Any()).Return(sendErr).AnyTimes()
+	l1.EXPECT().SubscribeNewHead(gomock.Any(), gomock.Any()).Return(nil, errors.New("notifications not supported")).AnyTimes()
+	l1.EXPECT().TransactionReceipt(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, hash common.Hash) (*types.Receipt, error) {
+		return &types.Receipt{
+			Status:            status,
+			TxHash:            hash,
+			BlockNumber:       big.NewInt(100),
+			GasUsed:           100_000,
+			EffectiveGasPrice: big.NewInt(1e9),
+			Logs:              logs,
+		}, nil
+	}).AnyTimes()
+	l1.EXPECT().BlockNumber(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+}
+
+// finalizedLog returns the WithdrawalFinalized log of the portal for the withdrawal with the given hash.
+func finalizedLog(t *testing.T, hash common.Hash, success bool) *types.Log {
+	t.Helper()
+	portalABI, err := bindings.OptimismPortalMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := portalABI.Events["WithdrawalFinalized"]
+	data, err := ev.Inputs.NonIndexed().Pack(success)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &types.Log{Address: testPortal, Topics: []common.Hash{ev.ID, hash}, Data: data}
+}
+
+// fakePortal is a PortalAdapter recording the withdrawals proven and finalized through it.
+type fakePortal struct {
+	// sendErr is returned when building the prove and finalize transactions.
+	sendErr     error
+	checkErr    error
+	gameType    uint32
+	gameTypeErr error
+
+	proved, finalized *withdrawals.ProvenWithdrawalParameters
+}
+
+// testTx is the transaction built by the fakePortal.
+func testTx() *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{Nonce: 1, Gas: 500_000, GasFeeCap: big.NewInt(2e9), To: &testPortal})
+}
+
+func (p *fakePortal) ProveWithdrawalTransaction(_ *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	if p.sendErr != nil {
+		return nil, p.sendErr
+	}
+	p.proved = &params
+	return testTx(), nil
+}
+
+func (p *fakePortal) FinalizeWithdrawalTransaction(_ *bind.TransactOpts, params withdrawals.ProvenWithdrawalParameters) (*types.Transaction, error) {
+	if p.sendErr != nil {
+		return nil, p.sendErr
+	}
+	p.finalized = &params
+	return testTx(), nil
+}
+
+func (p *fakePortal) ProvenWithdrawal(*bind.CallOpts, common.Hash, common.Address) (ProvenWithdrawal, error) {
+	return ProvenWithdrawal{}, nil
+}
+
+func (p *fakePortal) IsWithdrawalFinalized(*bind.CallOpts, common.Hash) (bool, error) {
+	return false, nil
+}
+
+func (p *fakePortal) CheckWithdrawal(*bind.CallOpts, common.Hash, common.Address) error {
+	return p.checkErr
+}
+
+func (p *fakePortal) RespectedGameType(*bind.CallOpts) (uint32, error) {
+	return p.gameType, p.gameTypeErr
+}
@@ -4,170 +4,165 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/bindings"
-	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
 	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/ethclient/gethclient"
-	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/sync/errgroup"
 )
 
 type FPWithdrawer struct {
-	Ctx      context.Context
-	L1Client *ethclient.Client
-	L2Client *rpc.Client
+	L1Client L1Client
+	L2Client L2Client
 	L2TxHash common.Hash
-	Portal   *bindingspreview.OptimismPortal2
-	Factory  *bindings.DisputeGameFactory
-	Opts     *bind.TransactOpts
+	// PortalAddress is the address of the portal Adapter is bound to, whose constants are queried in batches.
+	PortalAddress common.Address
+	Adapter       PortalAdapter
+	Factory       *bindings.DisputeGameFactory
+	// CrossChecker, if set, checks the data transactions rely on against a second L1 provider.
+	CrossChecker *CrossChecker
+	// Rollup, if set, is the rollup node output roots are checked against before proving.
+	Rollup RollupClient
+	Opts   *bind.TransactOpts
+	TxSettings
+
+	receipt l2Receipt
 }
 
-func (w *FPWithdrawer) CheckIfProvable() error {
-	l2WithdrawalBlock, err := txBlock(w.Ctx, w.L2Client, w.L2TxHash)
-	if err != nil {
-		return fmt.Errorf("error querying withdrawal tx block: %w", err)
+func (w *FPWithdrawer) CheckIfProvable(ctx context.Context) error {
+	// the L1 and L2 queries are independent, so they are made concurrently
+	var l2WithdrawalBlock *big.Int
+	var latestGame *bindings.IDisputeGameFactoryGameSearchResult
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		if l2WithdrawalBlock, err = w.receipt.block(gctx, w.L2Client, w.L2TxHash); err != nil {
+			return fmt.Errorf("error querying withdrawal tx block: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() (err error) {
+		latestGame, err = findLatestGame(gctx, w.Factory, w.Adapter)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return err
 	}
-
-	latestGame, err := withdrawals.FindLatestGame(w.Ctx, &w.Factory.DisputeGameFactoryCaller, &w.Portal.OptimismPortal2Caller)
-	if err != nil {
-		return fmt.Errorf("failed to find latest game: %w", err)
+	if err := w.checkL2BlockTag(ctx, w.L2Client, l2WithdrawalBlock); err != nil {
+		return err
 	}
 	l2BlockNumber := new(big.Int).SetBytes(latestGame.ExtraData[0:32])
 
 	if l2BlockNumber.Uint64() < l2WithdrawalBlock.Uint64() {
-		return fmt.Errorf("the latest L2 block proposed in the DisputeGameFactory is %d and is not past L2 block %d that includes the withdrawal - the withdrawal cannot be proven yet",
-			l2BlockNumber.Uint64(), l2WithdrawalBlock.Uint64())
+		return fmt.Errorf("%w: the latest L2 block proposed in the DisputeGameFactory is %d and is not past L2 block %d that includes the withdrawal",
+			ErrNotProvableYet, l2BlockNumber.Uint64(), l2WithdrawalBlock.Uint64())
 	}
 	return nil
 }
 
-func (w *FPWithdrawer) getWithdrawalHash() (common.Hash, error) {
-	l2 := ethclient.NewClient(w.L2Client)
-	receipt, err := l2.TransactionReceipt(w.Ctx, w.L2TxHash)
-	if err != nil {
-		return common.HexToHash(""), err
-	}
-
-	ev, err := withdrawals.ParseMessagePassed(receipt)
-	if err != nil {
-		return common.HexToHash(""), err
-	}
-
-	hash, err := withdrawals.WithdrawalHash(ev)
-	if err != nil {
-		return common.HexToHash(""), err
-	}
-
-	return hash, nil
+func (w *FPWithdrawer) WithdrawalHash(ctx context.Context) (common.Hash, error) {
+	return w.receipt.withdrawalHash(ctx, w.L2Client, w.L2TxHash)
 }
 
-func (w *FPWithdrawer) GetProvenWithdrawalTime() (uint64, error) {
-	hash, err := w.getWithdrawalHash()
+func (w *FPWithdrawer) GetProvenWithdrawalTime(ctx context.Context) (uint64, error) {
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	// the proven withdrawal structure now contains an additional mapping, as withdrawal proofs are now stored per submitter address
-	provenWithdrawal, err := w.Portal.ProvenWithdrawals(&bind.CallOpts{}, hash, w.Opts.From)
+	proven, err := w.Adapter.ProvenWithdrawal(&bind.CallOpts{Context: ctx}, hash, w.Opts.From)
 	if err != nil {
 		return 0, err
 	}
-
-	return provenWithdrawal.Timestamp, nil
+	return proven.Timestamp, nil
 }
 
-func (w *FPWithdrawer) ProveWithdrawal() error {
-	l2 := ethclient.NewClient(w.L2Client)
-	l2g := gethclient.New(w.L2Client)
+func (w *FPWithdrawer) ProofParameters(ctx context.Context) (withdrawals.ProvenWithdrawalParameters, error) {
+	l2 := w.L2Client
 
-	params, err := withdrawals.ProveWithdrawalParametersFaultProofs(w.Ctx, l2g, l2, l2, w.L2TxHash, &w.Factory.DisputeGameFactoryCaller, &w.Portal.OptimismPortal2Caller)
+	latestGame, err := findLatestGame(ctx, w.Factory, w.Adapter)
 	if err != nil {
-		return err
+		return withdrawals.ProvenWithdrawalParameters{}, err
 	}
+	l2BlockNumber := new(big.Int).SetBytes(latestGame.ExtraData[0:32])
+	return withdrawals.ProveWithdrawalParametersForBlock(ctx, l2, w.receipt.client(w.L2Client, w.L2TxHash), l2, w.L2TxHash, l2BlockNumber, latestGame.Index)
+}
 
-	// create the proof
-	tx, err := w.Portal.ProveWithdrawalTransaction(
-		w.Opts,
-		bindingspreview.TypesWithdrawalTransaction{
-			Nonce:    params.Nonce,
-			Sender:   params.Sender,
-			Target:   params.Target,
-			Value:    params.Value,
-			GasLimit: params.GasLimit,
-			Data:     params.Data,
-		},
-		params.L2OutputIndex, // this is overloaded and is the DisputeGame index in this context
-		bindingspreview.TypesOutputRootProof{
-			Version:                  params.OutputRootProof.Version,
-			StateRoot:                params.OutputRootProof.StateRoot,
-			MessagePasserStorageRoot: params.OutputRootProof.MessagePasserStorageRoot,
-			LatestBlockhash:          params.OutputRootProof.LatestBlockhash,
-		},
-		params.WithdrawalProof,
-	)
+func (w *FPWithdrawer) ProveWithdrawal(ctx context.Context) (common.Hash, error) {
+	w.report(ProgressEvent{L2TxHash: w.L2TxHash, Action: "prove", Stage: StageGeneratingProof}, "Generating proof for %s\n", w.L2TxHash.String())
+	params, err := w.ProofParameters(ctx)
 	if err != nil {
-		return err
+		return common.Hash{}, err
+	}
+	if err := verifyWithdrawalProof(params); err != nil {
+		return common.Hash{}, err
+	}
+	if err := w.CrossChecker.checkGame(ctx, params.L2OutputIndex, outputRoot(params.OutputRootProof)); err != nil {
+		return common.Hash{}, err
+	}
+	if w.Rollup != nil {
+		block, claim, err := gameClaim(ctx, w.L1Client, &w.Factory.DisputeGameFactoryCaller, params.L2OutputIndex)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		source := fmt.Sprintf("dispute game %d", params.L2OutputIndex)
+		if err := checkRollupOutput(ctx, w.Rollup, block, claim, source); err != nil {
+			return common.Hash{}, err
+		}
 	}
 
-	fmt.Printf("Proved withdrawal for %s: %s\n", w.L2TxHash.String(), tx.Hash().String())
-
-	// Wait 5 mins max for confirmation
-	ctxWithTimeout, cancel := context.WithTimeout(w.Ctx, 5*time.Minute)
-	defer cancel()
-	return waitForConfirmation(ctxWithTimeout, w.L1Client, tx.Hash())
+	// create the proof
+	txHash, _, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "prove", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Adapter.ProveWithdrawalTransaction(opts, params)
+	})
+	return txHash, err
 }
 
-func (w *FPWithdrawer) IsProofFinalized() (bool, error) {
-	return w.Portal.FinalizedWithdrawals(&bind.CallOpts{}, w.L2TxHash)
+func (w *FPWithdrawer) IsProofFinalized(ctx context.Context) (bool, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return w.Adapter.IsWithdrawalFinalized(&bind.CallOpts{Context: ctx}, hash)
 }
 
-func (w *FPWithdrawer) FinalizeWithdrawal() error {
-	// get the withdrawal hash
-	hash, err := w.getWithdrawalHash()
+func (w *FPWithdrawer) CheckIfFinalizable(ctx context.Context) error {
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
 		return err
 	}
 
 	// check if the withdrawal can be finalized using the calculated withdrawal hash
-	err = w.Portal.CheckWithdrawal(&bind.CallOpts{}, hash, w.Opts.From)
+	return decodeRevert(w.Adapter.CheckWithdrawal(&bind.CallOpts{Context: ctx}, hash, w.Opts.From))
+}
+
+func (w *FPWithdrawer) FinalizeWithdrawal(ctx context.Context) (common.Hash, error) {
+	if err := w.CheckIfFinalizable(ctx); err != nil {
+		return common.Hash{}, err
+	}
+
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
-		return err
+		return common.Hash{}, err
+	}
+	if err := w.CrossChecker.checkProven(ctx, w.L1Client, hash, w.Opts.From); err != nil {
+		return common.Hash{}, err
 	}
 
 	// get the WithdrawalTransaction info needed to finalize the withdrawal
-	l2 := ethclient.NewClient(w.L2Client)
-	l2g := gethclient.New(w.L2Client)
-
-	// we only use info from this call that isn't block-specific, so it's safe to call this again
-	params, err := withdrawals.ProveWithdrawalParametersFaultProofs(w.Ctx, l2g, l2, l2, w.L2TxHash, &w.Factory.DisputeGameFactoryCaller, &w.Portal.OptimismPortal2Caller)
+	params, err := w.receipt.withdrawalParams(ctx, w.L2Client, w.L2TxHash)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
 	// finalize the withdrawal
-	tx, err := w.Portal.FinalizeWithdrawalTransaction(
-		w.Opts,
-		bindingspreview.TypesWithdrawalTransaction{
-			Nonce:    params.Nonce,
-			Sender:   params.Sender,
-			Target:   params.Target,
-			Value:    params.Value,
-			GasLimit: params.GasLimit,
-			Data:     params.Data,
-		},
-	)
+	txHash, l1Receipt, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "finalize", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Adapter.FinalizeWithdrawalTransaction(opts, params)
+	})
 	if err != nil {
-		return err
+		return txHash, err
 	}
-
-	fmt.Printf("Completed withdrawal for %s: %s\n", w.L2TxHash.String(), tx.Hash().String())
-
-	// Wait 5 mins max for confirmation
-	ctxWithTimeout, cancel := context.WithTimeout(w.Ctx, 5*time.Minute)
-	defer cancel()
-	return waitForConfirmation(ctxWithTimeout, w.L1Client, tx.Hash())
+	return txHash, w.verifyFinalization(l1Receipt, hash)
 }
@@ -0,0 +1,290 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/mock/gomock"
+
+	"github.com/base-org/withdrawer/withdraw/mocks"
+)
+
+var testFactory = common.HexToAddress("0x8888888888888888888888888888888888888888")
+
+// gameTest configures the L1 and L2 state an FPWithdrawer is tested against.
+type gameTest struct {
+	// receipt is the receipt of the withdrawal transaction, or receiptErr the error querying it.
+	receipt    *types.Receipt
+	receiptErr error
+	// games is the number of dispute games, the latest one, at index games-1, being at L2 block
+	// gameBlock, or having extraData if set.
+	games     int64
+	gameBlock uint64
+	extraData []byte
+}
+
+// newTestFPWithdrawer returns an FPWithdrawer of w against the DisputeGameFactory state of tt, proving
+// and finalizing through portal.
+func newTestFPWithdrawer(t *testing.T, w *testWithdrawal, tt gameTest, portal PortalAdapter) (*FPWithdrawer, *mocks.MockL1Client) {
+	ctrl := gomock.NewController(t)
+	l1 := mocks.NewMockL1Client(ctrl)
+	l2 := mocks.NewMockL2Client(ctrl)
+	w.expectL2(l2, tt.receipt, tt.receiptErr)
+
+	factoryABI, err := bindings.DisputeGameFactoryMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extraData := tt.extraData
+	if extraData == nil {
+		extraData = common.BigToHash(new(big.Int).SetUint64(tt.gameBlock)).Bytes()
+	}
+	var games []bindings.IDisputeGameFactoryGameSearchResult
+	if tt.games > 0 {
+		games = append(games, bindings.IDisputeGameFactoryGameSearchResult{
+			Index:     big.NewInt(tt.games - 1),
+			Timestamp: 1_700_000_000,
+			ExtraData: extraData,
+		})
+	}
+	expectCalls(t, l1, map[common.Address]contract{testFactory: {
+		abi: factoryABI,
+		outputs: map[string][]interface{}{
+			"gameCount":       {big.NewInt(tt.games)},
+			"findLatestGames": {games},
+		},
+	}})
+
+	factory, err := bindings.NewDisputeGameFactory(testFactory, l1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &FPWithdrawer{
+		L1Client:      l1,
+		L2Client:      l2,
+		L2TxHash:      w.txHash,
+		PortalAddress: testPortal,
+		Adapter:       portal,
+		Factory:       factory,
+		Opts:          &bind.TransactOpts{From: testSender},
+		TxSettings:    testSettings,
+	}, l1
+}
+
+func TestFPWithdrawerCheckIfProvable(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name   string
+		test   gameTest
+		portal *fakePortal
+		// wantErr is the error returned, or wantAnyErr whether one is returned that isn't sentinel.
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{
+			name:   "provable",
+			test:   gameTest{receipt: w.receipt, games: 5, gameBlock: 20},
+			portal: &fakePortal{},
+		},
+		{
+			name:    "no game past the withdrawal",
+			test:    gameTest{receipt: w.receipt, games: 5, gameBlock: 9},
+			portal:  &fakePortal{},
+			wantErr: ErrNotProvableYet,
+		},
+		{
+			name:       "no games",
+			test:       gameTest{receipt: w.receipt},
+			portal:     &fakePortal{},
+			wantAnyErr: true,
+		},
+		{
+			name:       "short extra data",
+			test:       gameTest{receipt: w.receipt, games: 5, extraData: []byte{20}},
+			portal:     &fakePortal{},
+			wantAnyErr: true,
+		},
+		{
+			name:    "respected game type error",
+			test:    gameTest{receipt: w.receipt, games: 5, gameBlock: 20},
+			portal:  &fakePortal{gameTypeErr: errRPC},
+			wantErr: errRPC,
+		},
+		{
+			name:    "receipt error",
+			test:    gameTest{receiptErr: errRPC, games: 5, gameBlock: 20},
+			portal:  &fakePortal{},
+			wantErr: errRPC,
+		},
+		{
+			name:    "failed withdrawal",
+			test:    gameTest{receipt: failedReceipt(w.receipt), games: 5, gameBlock: 20},
+			portal:  &fakePortal{},
+			wantErr: ErrWithdrawalFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, _ := newTestFPWithdrawer(t, w, tt.test, tt.portal)
+			err := withdrawer.CheckIfProvable(context.Background())
+			if tt.wantAnyErr {
+				if err == nil {
+					t.Fatal("CheckIfProvable() error = nil, want an error")
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckIfProvable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFPWithdrawerCheckIfFinalizable(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name     string
+		checkErr error
+		wantErr  error
+	}{
+		{
+			name: "finalizable",
+		},
+		{
+			name:     "not matured",
+			checkErr: dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: proven withdrawal has not matured yet")},
+			wantErr:  ErrChallengePeriodActive,
+		},
+		{
+			name:     "game not resolved",
+			checkErr: dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: output proposal has not been validated")},
+			wantErr:  ErrGameNotResolved,
+		},
+		{
+			name:     "not proven",
+			checkErr: dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: withdrawal has not been proven by proof submitter address yet")},
+			wantErr:  ErrNotProven,
+		},
+		{
+			name:     "call error",
+			checkErr: errRPC,
+			wantErr:  errRPC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, _ := newTestFPWithdrawer(t, w, gameTest{receipt: w.receipt}, &fakePortal{checkErr: tt.checkErr})
+			if err := withdrawer.CheckIfFinalizable(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckIfFinalizable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFPWithdrawerProveWithdrawal(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		portal  *fakePortal
+		sendErr error
+		status  uint64
+		wantErr error
+		// wantAnyErr is whether an error is returned that isn't sentinel.
+		wantAnyErr bool
+	}{
+		{
+			name:   "proven",
+			portal: &fakePortal{},
+			status: types.ReceiptStatusSuccessful,
+		},
+		{
+			name:    "send error",
+			portal:  &fakePortal{},
+			sendErr: errRPC,
+			wantErr: errRPC,
+		},
+		{
+			name:       "reverted",
+			portal:     &fakePortal{},
+			status:     types.ReceiptStatusFailed,
+			wantAnyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, l1 := newTestFPWithdrawer(t, w, gameTest{receipt: w.receipt, games: 5, gameBlock: 20}, tt.portal)
+			expectSend(l1, tt.sendErr, tt.status, nil)
+			txHash, err := withdrawer.ProveWithdrawal(context.Background())
+			if tt.wantAnyErr {
+				if err == nil {
+					t.Fatal("ProveWithdrawal() error = nil, want an error")
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProveWithdrawal() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if txHash != testTx().Hash() {
+				t.Fatalf("ProveWithdrawal() = %s, want %s", txHash, testTx().Hash())
+			}
+			// the withdrawal is proven against the latest game, at index 4
+			want := w.params(4)
+			if got := tt.portal.proved; got == nil || got.L2OutputIndex.Cmp(want.L2OutputIndex) != 0 || got.OutputRootProof != want.OutputRootProof || got.Value.Cmp(want.Value) != 0 {
+				t.Fatalf("proved %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestFPWithdrawerFinalizeWithdrawal(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	tests := []struct {
+		name     string
+		checkErr error
+		logs     []*types.Log
+		wantErr  error
+		// wantFinalized is whether a finalize transaction is sent.
+		wantFinalized bool
+	}{
+		{
+			name:          "finalized",
+			logs:          []*types.Log{finalizedLog(t, w.hash, true)},
+			wantFinalized: true,
+		},
+		{
+			name:          "delivery failed",
+			logs:          []*types.Log{finalizedLog(t, w.hash, false)},
+			wantErr:       ErrDeliveryFailed,
+			wantFinalized: true,
+		},
+		{
+			name:     "challenge period active",
+			checkErr: dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: proven withdrawal has not matured yet")},
+			wantErr:  ErrChallengePeriodActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portal := &fakePortal{checkErr: tt.checkErr}
+			withdrawer, l1 := newTestFPWithdrawer(t, w, gameTest{receipt: w.receipt}, portal)
+			expectSend(l1, nil, types.ReceiptStatusSuccessful, tt.logs)
+			_, err := withdrawer.FinalizeWithdrawal(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinalizeWithdrawal() error = %v, want %v", err, tt.wantErr)
+			}
+			if finalized := portal.finalized != nil; finalized != tt.wantFinalized {
+				t.Fatalf("finalize tx sent = %v, want %v", finalized, tt.wantFinalized)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GameStatus is the resolution status of a dispute game.
+type GameStatus uint8
+
+const (
+	GameInProgress GameStatus = iota
+	GameChallengerWins
+	GameDefenderWins
+)
+
+func (s GameStatus) String() string {
+	switch s {
+	case GameInProgress:
+		return "in progress"
+	case GameChallengerWins:
+		return "challenger wins"
+	case GameDefenderWins:
+		return "defender wins"
+	default:
+		return fmt.Sprintf("unknown (%d)", uint8(s))
+	}
+}
+
+// ProofGame describes the dispute game a withdrawal was proven against.
+type ProofGame struct {
+	Address common.Address
+	Status  GameStatus
+	// Challenged is set once any claim counters the game's root claim.
+	Challenged bool
+}
+
+// ProofGameInspector is implemented by fault proof withdraw helpers, which can look up the dispute
+// game a proven withdrawal depends on.
+type ProofGameInspector interface {
+	// ProofGame returns the dispute game the withdrawal was proven against, or nil if it isn't proven.
+	ProofGame(ctx context.Context) (*ProofGame, error)
+}
+
+// findLatestGame returns the latest dispute game of the type respected by portal, which withdrawals are
+// proven against.
+func findLatestGame(ctx context.Context, factory *bindings.DisputeGameFactory, portal PortalAdapter) (*bindings.IDisputeGameFactoryGameSearchResult, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	gameType, err := portal.RespectedGameType(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error querying respected game type: %w", err)
+	}
+	count, err := factory.GameCount(opts)
+	if err != nil {
+		return nil, fmt.Errorf("error querying game count: %w", err)
+	}
+	if count.Sign() == 0 {
+		return nil, errors.New("no dispute games")
+	}
+	games, err := factory.FindLatestGames(opts, gameType, new(big.Int).Sub(count, common.Big1), common.Big1)
+	if err != nil {
+		return nil, fmt.Errorf("error querying latest games: %w", err)
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no dispute games of type %d", gameType)
+	}
+	// the extra data of output root games is the L2 block number the root claim is at
+	if len(games[0].ExtraData) < 32 {
+		return nil, fmt.Errorf("dispute game %d has %d bytes of extra data, expected at least 32", games[0].Index, len(games[0].ExtraData))
+	}
+	return &games[0], nil
+}
+
+// provenWithdrawalGame looks up the dispute game that submitter proved the withdrawal with the given hash against.
+func provenWithdrawalGame(ctx context.Context, l1 bind.ContractCaller, portal PortalAdapter, hash common.Hash, submitter common.Address) (*ProofGame, error) {
+	proven, err := portal.ProvenWithdrawal(&bind.CallOpts{Context: ctx}, hash, submitter)
+	if err != nil {
+		return nil, fmt.Errorf("error querying proven withdrawal: %w", err)
+	}
+	if proven.Timestamp == 0 {
+		return nil, nil
+	}
+
+	game := bind.NewBoundContract(proven.DisputeGame, *snapshots.LoadFaultDisputeGameABI(), l1, nil, nil)
+	opts := &bind.CallOpts{Context: ctx}
+
+	var out []interface{}
+	if err := game.Call(opts, &out, "status"); err != nil {
+		return nil, fmt.Errorf("error querying game status: %w", err)
+	}
+	status := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	out = nil
+	if err := game.Call(opts, &out, "claimDataLen"); err != nil {
+		return nil, fmt.Errorf("error querying game claims: %w", err)
+	}
+	claims := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+	return &ProofGame{
+		Address:    proven.DisputeGame,
+		Status:     GameStatus(status),
+		Challenged: claims.Cmp(common.Big1) > 0,
+	}, nil
+}
+
+// gameClaim returns the L2 block number and root claim of the dispute game at index of factory.
+func gameClaim(ctx context.Context, l1 bind.ContractCaller, factory *bindings.DisputeGameFactoryCaller, index *big.Int) (uint64, common.Hash, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	proxy, err := factory.GameAtIndex(opts, index)
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("error querying dispute game %d: %w", index, err)
+	}
+	game := bind.NewBoundContract(proxy.Proxy, *snapshots.LoadFaultDisputeGameABI(), l1, nil, nil)
+
+	var out []interface{}
+	if err := game.Call(opts, &out, "l2BlockNumber"); err != nil {
+		return 0, common.Hash{}, fmt.Errorf("error querying L2 block of dispute game %d: %w", index, err)
+	}
+	block := abi.ConvertType(out[0], new(big.Int)).(*big.Int)
+
+	out = nil
+	if err := game.Call(opts, &out, "rootClaim"); err != nil {
+		return 0, common.Hash{}, fmt.Errorf("error querying root claim of dispute game %d: %w", index, err)
+	}
+	return block.Uint64(), common.Hash(*abi.ConvertType(out[0], new([32]byte)).(*[32]byte)), nil
+}
+
+func (w *FPWithdrawer) ProofGame(ctx context.Context) (*ProofGame, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provenWithdrawalGame(ctx, w.L1Client, w.Adapter, hash, w.Opts.From)
+}
+
+func (w *SuperRootWithdrawer) ProofGame(ctx context.Context) (*ProofGame, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provenWithdrawalGame(ctx, w.L1Client, w.adapter(), hash, w.Opts.From)
+}
@@ -0,0 +1,31 @@
+package withdraw
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// gasBufferBackend scales the gas estimates of a contract backend.
+type gasBufferBackend struct {
+	bind.ContractBackend
+	factor float64
+}
+
+func (b gasBufferBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	gas, err := b.ContractBackend.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(float64(gas) * b.factor), nil
+}
+
+// WithGasBuffer returns backend with its gas estimates multiplied by factor, as estimates for proofs
+// with deep Merkle branches occasionally fall short. Factors up to 1 leave backend unchanged.
+func WithGasBuffer(backend bind.ContractBackend, factor float64) bind.ContractBackend {
+	if factor <= 1 {
+		return backend
+	}
+	return gasBufferBackend{ContractBackend: backend, factor: factor}
+}
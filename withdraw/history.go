@@ -0,0 +1,206 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// HistoryEntry is an L1 transaction that proved or finalized a withdrawal, or attempted to.
+type HistoryEntry struct {
+	Action      string
+	TxHash      common.Hash
+	BlockNumber uint64
+	Submitter   common.Address
+	// Success is whether the call to the L1 target succeeded, and is only meaningful for finalizations.
+	Success bool
+	// Reverted is set for attempts whose transaction reverted.
+	Reverted bool
+}
+
+// Status describes the outcome of the attempt: ok, reverted, or call to L1 target failed.
+func (e HistoryEntry) Status() string {
+	switch {
+	case e.Reverted:
+		return "reverted"
+	case !e.Success:
+		return "call to L1 target failed"
+	}
+	return "ok"
+}
+
+// WithdrawalHistory scans the portal's WithdrawalProven and WithdrawalFinalized events between fromBlock
+// and toBlock (inclusive), in chunks of chunkSize blocks, and returns all entries for withdrawalHash in
+// block order. Reverted transactions emit no events, so attempts, such as the transactions recorded in a
+// journal, are also checked, and those that reverted proving or finalizing the withdrawal are included.
+func WithdrawalHistory(ctx context.Context, l1 *ethclient.Client, portalAddress common.Address, withdrawalHash common.Hash, fromBlock, toBlock, chunkSize uint64, attempts []common.Hash) ([]HistoryEntry, error) {
+	if chunkSize == 0 {
+		return nil, errZeroChunkSize
+	}
+	// the events are identical in OptimismPortal and OptimismPortal2
+	portal, err := bindings.NewOptimismPortalFilterer(portalAddress, l1)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	for start := fromBlock; start <= toBlock; start += chunkSize {
+		end := min(start+chunkSize-1, toBlock)
+		chunk, err := historyChunk(portal, withdrawalHash, &bind.FilterOpts{Start: start, End: &end, Context: ctx})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, chunk...)
+	}
+
+	seen := make(map[common.Hash]bool, len(entries))
+	for _, e := range entries {
+		seen[e.TxHash] = true
+	}
+	for _, txHash := range attempts {
+		if seen[txHash] || txHash == (common.Hash{}) {
+			continue
+		}
+		seen[txHash] = true
+		e, err := revertedAttempt(ctx, l1, portalAddress, withdrawalHash, txHash)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			entries = append(entries, *e)
+		}
+	}
+
+	// proofs are stored per submitter with fault proofs, so resolve who sent each transaction
+	for i := range entries {
+		tx, _, err := l1.TransactionByHash(ctx, entries[i].TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("error querying tx %s: %w", entries[i].TxHash, err)
+		}
+		entries[i].Submitter, err = types.LatestSignerForChainID(tx.ChainId()).Sender(tx)
+		if err != nil {
+			return nil, fmt.Errorf("error recovering sender of tx %s: %w", entries[i].TxHash, err)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].BlockNumber < entries[j].BlockNumber
+	})
+	return entries, nil
+}
+
+// historyChunk returns the entries for withdrawalHash of the blocks of opts.
+func historyChunk(portal *bindings.OptimismPortalFilterer, withdrawalHash common.Hash, opts *bind.FilterOpts) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	proven, err := portal.FilterWithdrawalProven(opts, [][32]byte{withdrawalHash}, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering WithdrawalProven events in blocks %d-%d: %w", opts.Start, *opts.End, err)
+	}
+	defer proven.Close()
+	for proven.Next() {
+		entries = append(entries, HistoryEntry{
+			Action:      "prove",
+			TxHash:      proven.Event.Raw.TxHash,
+			BlockNumber: proven.Event.Raw.BlockNumber,
+			Success:     true,
+		})
+	}
+	if err := proven.Error(); err != nil {
+		return nil, err
+	}
+
+	finalized, err := portal.FilterWithdrawalFinalized(opts, [][32]byte{withdrawalHash})
+	if err != nil {
+		return nil, fmt.Errorf("error filtering WithdrawalFinalized events in blocks %d-%d: %w", opts.Start, *opts.End, err)
+	}
+	defer finalized.Close()
+	for finalized.Next() {
+		entries = append(entries, HistoryEntry{
+			Action:      "finalize",
+			TxHash:      finalized.Event.Raw.TxHash,
+			BlockNumber: finalized.Event.Raw.BlockNumber,
+			Success:     finalized.Event.Success,
+		})
+	}
+	if err := finalized.Error(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// revertedAttempt returns the entry of txHash if it is a reverted call to prove or finalize withdrawalHash
+// on the portal, and nil otherwise, including for transactions that aren't mined.
+func revertedAttempt(ctx context.Context, l1 *ethclient.Client, portalAddress common.Address, withdrawalHash, txHash common.Hash) (*HistoryEntry, error) {
+	receipt, err := l1.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying receipt of tx %s: %w", txHash, err)
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return nil, nil
+	}
+	tx, _, err := l1.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tx %s: %w", txHash, err)
+	}
+	if tx.To() == nil || *tx.To() != portalAddress {
+		return nil, nil
+	}
+	method, wtx := decodePortalCall(tx.Data())
+	if wtx == nil {
+		return nil, nil
+	}
+	hash, err := withdrawals.WithdrawalHash(&bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    wtx.Nonce,
+		Sender:   wtx.Sender,
+		Target:   wtx.Target,
+		Value:    wtx.Value,
+		GasLimit: wtx.GasLimit,
+		Data:     wtx.Data,
+	})
+	if err != nil || hash != withdrawalHash {
+		return nil, nil
+	}
+	action := "prove"
+	if strings.HasPrefix(method, "finalize") {
+		action = "finalize"
+	}
+	return &HistoryEntry{Action: action, TxHash: txHash, BlockNumber: receipt.BlockNumber.Uint64(), Reverted: true}, nil
+}
+
+// BlockAtTime returns the number of the first L1 block with a timestamp at or after timestamp.
+func BlockAtTime(ctx context.Context, l1 *ethclient.Client, timestamp uint64) (uint64, error) {
+	head, err := l1.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var searchErr error
+	n := sort.Search(int(head.Number.Uint64())+1, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		header, err := l1.HeaderByNumber(ctx, new(big.Int).SetUint64(uint64(i)))
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return header.Time >= timestamp
+	})
+	if searchErr != nil {
+		return 0, searchErr
+	}
+	return uint64(n), nil
+}
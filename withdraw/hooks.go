@@ -0,0 +1,56 @@
+package withdraw
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Hooks are called as the transactions proving and finalizing a withdrawal progress, so embedders can
+// track them without parsing the output. Hooks are called synchronously and should return quickly,
+// and any of them may be nil. The action is either "prove" or "finalize".
+type Hooks struct {
+	// OnTxSubmitted is called once the transaction for action was sent.
+	OnTxSubmitted func(l2TxHash common.Hash, action string, tx common.Hash)
+	// OnTxConfirmed is called once the transaction for action was confirmed, including transactions
+	// sent by a previous run.
+	OnTxConfirmed func(l2TxHash common.Hash, action string, receipt *types.Receipt)
+	// OnStateChange is called with "proven" or "finalized" once the withdrawal reached that state.
+	OnStateChange func(l2TxHash common.Hash, state string)
+	// OnError is called when the transaction for action could not be sent or confirmed.
+	OnError func(l2TxHash common.Hash, action string, err error)
+}
+
+// actionStates are the states a withdrawal reaches once the transaction for the action is confirmed.
+var actionStates = map[string]string{
+	"prove":    "proven",
+	"finalize": "finalized",
+}
+
+func (h *Hooks) txSubmitted(l2TxHash common.Hash, action string, tx common.Hash) {
+	if h != nil && h.OnTxSubmitted != nil {
+		h.OnTxSubmitted(l2TxHash, action, tx)
+	}
+}
+
+// done calls the hooks for the outcome of submitting the transaction for action. Without a receipt,
+// the transaction was only sent.
+func (h *Hooks) done(l2TxHash common.Hash, action string, receipt *types.Receipt, err error) {
+	if h == nil {
+		return
+	}
+	if err != nil {
+		if h.OnError != nil {
+			h.OnError(l2TxHash, action, err)
+		}
+		return
+	}
+	if receipt == nil {
+		return
+	}
+	if h.OnTxConfirmed != nil {
+		h.OnTxConfirmed(l2TxHash, action, receipt)
+	}
+	if h.OnStateChange != nil {
+		h.OnStateChange(l2TxHash, actionStates[action])
+	}
+}
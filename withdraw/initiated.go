@@ -0,0 +1,115 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// WithdrawalInitiatedTopic is the topic of the L2StandardBridge WithdrawalInitiated event, which is
+// emitted for ETH and token withdrawals through the bridge and indexes the withdrawing account.
+var WithdrawalInitiatedTopic = crypto.Keccak256Hash([]byte("WithdrawalInitiated(address,address,address,address,uint256,bytes)"))
+
+// InitiatedWithdrawal is a withdrawal initiated on L2 by one of the accounts passed to
+// InitiatedWithdrawals.
+type InitiatedWithdrawal struct {
+	TxHash      common.Hash
+	BlockNumber uint64
+	Time        time.Time
+	Account     common.Address
+	// Value is the ETH withdrawn, in wei, and Route its route, which has the amount of bridged tokens.
+	Value *big.Int
+	Route Route
+}
+
+// InitiatedWithdrawals scans L2 blocks fromBlock to toBlock (inclusive), in chunks of chunkSize blocks,
+// for the withdrawals accounts initiated through the L2StandardBridge or directly on the
+// L2ToL1MessagePasser, and returns them in block order.
+func InitiatedWithdrawals(ctx context.Context, l2 *ethclient.Client, accounts []common.Address, fromBlock, toBlock, chunkSize uint64) ([]InitiatedWithdrawal, error) {
+	if chunkSize == 0 {
+		return nil, errZeroChunkSize
+	}
+	accountTopics := make([]common.Hash, len(accounts))
+	for i, a := range accounts {
+		accountTopics[i] = common.BytesToHash(a.Bytes())
+	}
+	// the account is the third topic of WithdrawalInitiated, and the second of MessagePassed
+	queries := []ethereum.FilterQuery{
+		{
+			Addresses: []common.Address{predeploys.L2StandardBridgeAddr},
+			Topics:    [][]common.Hash{{WithdrawalInitiatedTopic}, nil, nil, accountTopics},
+		},
+		{
+			Addresses: []common.Address{predeploys.L2ToL1MessagePasserAddr},
+			Topics:    [][]common.Hash{{withdrawals.MessagePassedTopic}, nil, accountTopics},
+		},
+	}
+
+	var result []InitiatedWithdrawal
+	seen := make(map[common.Hash]bool)
+	for start := fromBlock; start <= toBlock; start += chunkSize {
+		end := min(start+chunkSize-1, toBlock)
+		var found []InitiatedWithdrawal
+		for _, q := range queries {
+			q.FromBlock = new(big.Int).SetUint64(start)
+			q.ToBlock = new(big.Int).SetUint64(end)
+			logs, err := l2.FilterLogs(ctx, q)
+			if err != nil {
+				return nil, fmt.Errorf("error filtering withdrawals in L2 blocks %d-%d: %w", start, end, err)
+			}
+			for _, l := range logs {
+				if seen[l.TxHash] {
+					continue
+				}
+				seen[l.TxHash] = true
+				found = append(found, InitiatedWithdrawal{
+					TxHash:      l.TxHash,
+					BlockNumber: l.BlockNumber,
+					Account:     common.BytesToAddress(l.Topics[len(q.Topics)-1].Bytes()),
+				})
+			}
+		}
+		for i := range found {
+			if err := found[i].load(ctx, l2); err != nil {
+				return nil, err
+			}
+		}
+		sort.SliceStable(found, func(i, j int) bool { return found[i].BlockNumber < found[j].BlockNumber })
+		result = append(result, found...)
+	}
+	return result, nil
+}
+
+// load sets the value, route and time of the withdrawal from its receipt and block. Only the first
+// withdrawal of a transaction is considered, as it is the one proven and finalized from the tx hash.
+func (w *InitiatedWithdrawal) load(ctx context.Context, l2 *ethclient.Client) error {
+	receipt, err := l2.TransactionReceipt(ctx, w.TxHash)
+	if err != nil {
+		return fmt.Errorf("error querying withdrawal receipt %s: %w", w.TxHash, err)
+	}
+	events, err := messagePassedEvents(receipt)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("%w: no MessagePassed event in tx %s", ErrNotWithdrawal, w.TxHash)
+	}
+	ev := events[0]
+	w.Value = ev.Value
+	w.Route = DecodeRoute(ev.Sender, ev.Target, ev.Data)
+	header, err := l2.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return fmt.Errorf("error querying L2 block %d: %w", receipt.BlockNumber, err)
+	}
+	w.Time = time.Unix(int64(header.Time), 0).UTC()
+	return nil
+}
@@ -0,0 +1,13 @@
+package withdraw
+
+import "github.com/ethereum/go-ethereum/common"
+
+// Journal records the transactions sent for a withdrawal as soon as they are sent, so that a run
+// interrupted while waiting for confirmation can be resumed without submitting the transaction again.
+type Journal interface {
+	// PendingTx returns the last transaction recorded for action ("prove" or "finalize") on the
+	// withdrawal initiated in l2TxHash, or the zero hash.
+	PendingTx(l2TxHash common.Hash, action string) (common.Hash, error)
+	// RecordTx records tx as sent for action on the withdrawal initiated in l2TxHash.
+	RecordTx(l2TxHash common.Hash, action string, tx common.Hash) error
+}
@@ -0,0 +1,51 @@
+package withdraw
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Logger receives the progress messages of the helpers, which are formatted as fmt.Printf would and
+// end with a newline.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+type writerLogger struct {
+	w io.Writer
+}
+
+// NewWriterLogger returns a Logger writing the progress messages to w.
+func NewWriterLogger(w io.Writer) Logger {
+	return writerLogger{w: w}
+}
+
+func (l writerLogger) Printf(format string, args ...any) {
+	fmt.Fprintf(l.w, format, args...)
+}
+
+type logLogger struct {
+	l log.Logger
+}
+
+// NewLogLogger returns a Logger passing the progress messages to l at info level, e.g. the op-service
+// logger used by the withdrawer binary.
+func NewLogLogger(l log.Logger) Logger {
+	return logLogger{l: l}
+}
+
+func (l logLogger) Printf(format string, args ...any) {
+	l.l.Info(strings.TrimSuffix(fmt.Sprintf(format, args...), "\n"))
+}
+
+// logger returns the Logger of the settings, printing to stdout if none is set.
+func (s TxSettings) logger() Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return writerLogger{w: os.Stdout}
+}
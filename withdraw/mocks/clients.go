@@ -0,0 +1,482 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/base-org/withdrawer/withdraw (interfaces: L1Client,L2Client,SupervisorClient,RollupClient)
+//
+// Generated by this command:
+//
+//	mockgen -destination mocks/clients.go -package mocks . L1Client,L2Client,SupervisorClient,RollupClient
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	big "math/big"
+	reflect "reflect"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	common "github.com/ethereum/go-ethereum/common"
+	types "github.com/ethereum/go-ethereum/core/types"
+	gethclient "github.com/ethereum/go-ethereum/ethclient/gethclient"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockL1Client is a mock of L1Client interface.
+type MockL1Client struct {
+	ctrl     *gomock.Controller
+	recorder *MockL1ClientMockRecorder
+}
+
+// MockL1ClientMockRecorder is the mock recorder for MockL1Client.
+type MockL1ClientMockRecorder struct {
+	mock *MockL1Client
+}
+
+// NewMockL1Client creates a new mock instance.
+func NewMockL1Client(ctrl *gomock.Controller) *MockL1Client {
+	mock := &MockL1Client{ctrl: ctrl}
+	mock.recorder = &MockL1ClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockL1Client) EXPECT() *MockL1ClientMockRecorder {
+	return m.recorder
+}
+
+// BlockNumber mocks base method.
+func (m *MockL1Client) BlockNumber(arg0 context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockNumber", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockNumber indicates an expected call of BlockNumber.
+func (mr *MockL1ClientMockRecorder) BlockNumber(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockNumber", reflect.TypeOf((*MockL1Client)(nil).BlockNumber), arg0)
+}
+
+// CallContract mocks base method.
+func (m *MockL1Client) CallContract(arg0 context.Context, arg1 ethereum.CallMsg, arg2 *big.Int) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CallContract", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CallContract indicates an expected call of CallContract.
+func (mr *MockL1ClientMockRecorder) CallContract(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContract", reflect.TypeOf((*MockL1Client)(nil).CallContract), arg0, arg1, arg2)
+}
+
+// ChainID mocks base method.
+func (m *MockL1Client) ChainID(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainID", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainID indicates an expected call of ChainID.
+func (mr *MockL1ClientMockRecorder) ChainID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainID", reflect.TypeOf((*MockL1Client)(nil).ChainID), arg0)
+}
+
+// CodeAt mocks base method.
+func (m *MockL1Client) CodeAt(arg0 context.Context, arg1 common.Address, arg2 *big.Int) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CodeAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CodeAt indicates an expected call of CodeAt.
+func (mr *MockL1ClientMockRecorder) CodeAt(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CodeAt", reflect.TypeOf((*MockL1Client)(nil).CodeAt), arg0, arg1, arg2)
+}
+
+// EstimateGas mocks base method.
+func (m *MockL1Client) EstimateGas(arg0 context.Context, arg1 ethereum.CallMsg) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateGas", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateGas indicates an expected call of EstimateGas.
+func (mr *MockL1ClientMockRecorder) EstimateGas(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateGas", reflect.TypeOf((*MockL1Client)(nil).EstimateGas), arg0, arg1)
+}
+
+// FilterLogs mocks base method.
+func (m *MockL1Client) FilterLogs(arg0 context.Context, arg1 ethereum.FilterQuery) ([]types.Log, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FilterLogs", arg0, arg1)
+	ret0, _ := ret[0].([]types.Log)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FilterLogs indicates an expected call of FilterLogs.
+func (mr *MockL1ClientMockRecorder) FilterLogs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterLogs", reflect.TypeOf((*MockL1Client)(nil).FilterLogs), arg0, arg1)
+}
+
+// HeaderByNumber mocks base method.
+func (m *MockL1Client) HeaderByNumber(arg0 context.Context, arg1 *big.Int) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeaderByNumber", arg0, arg1)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeaderByNumber indicates an expected call of HeaderByNumber.
+func (mr *MockL1ClientMockRecorder) HeaderByNumber(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockL1Client)(nil).HeaderByNumber), arg0, arg1)
+}
+
+// PendingCodeAt mocks base method.
+func (m *MockL1Client) PendingCodeAt(arg0 context.Context, arg1 common.Address) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingCodeAt", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingCodeAt indicates an expected call of PendingCodeAt.
+func (mr *MockL1ClientMockRecorder) PendingCodeAt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingCodeAt", reflect.TypeOf((*MockL1Client)(nil).PendingCodeAt), arg0, arg1)
+}
+
+// PendingNonceAt mocks base method.
+func (m *MockL1Client) PendingNonceAt(arg0 context.Context, arg1 common.Address) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingNonceAt", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingNonceAt indicates an expected call of PendingNonceAt.
+func (mr *MockL1ClientMockRecorder) PendingNonceAt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingNonceAt", reflect.TypeOf((*MockL1Client)(nil).PendingNonceAt), arg0, arg1)
+}
+
+// SendTransaction mocks base method.
+func (m *MockL1Client) SendTransaction(arg0 context.Context, arg1 *types.Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTransaction", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendTransaction indicates an expected call of SendTransaction.
+func (mr *MockL1ClientMockRecorder) SendTransaction(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTransaction", reflect.TypeOf((*MockL1Client)(nil).SendTransaction), arg0, arg1)
+}
+
+// SubscribeFilterLogs mocks base method.
+func (m *MockL1Client) SubscribeFilterLogs(arg0 context.Context, arg1 ethereum.FilterQuery, arg2 chan<- types.Log) (ethereum.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeFilterLogs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ethereum.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeFilterLogs indicates an expected call of SubscribeFilterLogs.
+func (mr *MockL1ClientMockRecorder) SubscribeFilterLogs(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeFilterLogs", reflect.TypeOf((*MockL1Client)(nil).SubscribeFilterLogs), arg0, arg1, arg2)
+}
+
+// SubscribeNewHead mocks base method.
+func (m *MockL1Client) SubscribeNewHead(arg0 context.Context, arg1 chan<- *types.Header) (ethereum.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeNewHead", arg0, arg1)
+	ret0, _ := ret[0].(ethereum.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeNewHead indicates an expected call of SubscribeNewHead.
+func (mr *MockL1ClientMockRecorder) SubscribeNewHead(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeNewHead", reflect.TypeOf((*MockL1Client)(nil).SubscribeNewHead), arg0, arg1)
+}
+
+// SuggestGasPrice mocks base method.
+func (m *MockL1Client) SuggestGasPrice(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestGasPrice", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestGasPrice indicates an expected call of SuggestGasPrice.
+func (mr *MockL1ClientMockRecorder) SuggestGasPrice(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestGasPrice", reflect.TypeOf((*MockL1Client)(nil).SuggestGasPrice), arg0)
+}
+
+// SuggestGasTipCap mocks base method.
+func (m *MockL1Client) SuggestGasTipCap(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestGasTipCap", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestGasTipCap indicates an expected call of SuggestGasTipCap.
+func (mr *MockL1ClientMockRecorder) SuggestGasTipCap(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestGasTipCap", reflect.TypeOf((*MockL1Client)(nil).SuggestGasTipCap), arg0)
+}
+
+// TransactionByHash mocks base method.
+func (m *MockL1Client) TransactionByHash(arg0 context.Context, arg1 common.Hash) (*types.Transaction, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionByHash", arg0, arg1)
+	ret0, _ := ret[0].(*types.Transaction)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TransactionByHash indicates an expected call of TransactionByHash.
+func (mr *MockL1ClientMockRecorder) TransactionByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionByHash", reflect.TypeOf((*MockL1Client)(nil).TransactionByHash), arg0, arg1)
+}
+
+// TransactionReceipt mocks base method.
+func (m *MockL1Client) TransactionReceipt(arg0 context.Context, arg1 common.Hash) (*types.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionReceipt", arg0, arg1)
+	ret0, _ := ret[0].(*types.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransactionReceipt indicates an expected call of TransactionReceipt.
+func (mr *MockL1ClientMockRecorder) TransactionReceipt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionReceipt", reflect.TypeOf((*MockL1Client)(nil).TransactionReceipt), arg0, arg1)
+}
+
+// MockL2Client is a mock of L2Client interface.
+type MockL2Client struct {
+	ctrl     *gomock.Controller
+	recorder *MockL2ClientMockRecorder
+}
+
+// MockL2ClientMockRecorder is the mock recorder for MockL2Client.
+type MockL2ClientMockRecorder struct {
+	mock *MockL2Client
+}
+
+// NewMockL2Client creates a new mock instance.
+func NewMockL2Client(ctrl *gomock.Controller) *MockL2Client {
+	mock := &MockL2Client{ctrl: ctrl}
+	mock.recorder = &MockL2ClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockL2Client) EXPECT() *MockL2ClientMockRecorder {
+	return m.recorder
+}
+
+// BlockByNumber mocks base method.
+func (m *MockL2Client) BlockByNumber(arg0 context.Context, arg1 *big.Int) (*types.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockByNumber", arg0, arg1)
+	ret0, _ := ret[0].(*types.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockByNumber indicates an expected call of BlockByNumber.
+func (mr *MockL2ClientMockRecorder) BlockByNumber(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockByNumber", reflect.TypeOf((*MockL2Client)(nil).BlockByNumber), arg0, arg1)
+}
+
+// ChainID mocks base method.
+func (m *MockL2Client) ChainID(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainID", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainID indicates an expected call of ChainID.
+func (mr *MockL2ClientMockRecorder) ChainID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainID", reflect.TypeOf((*MockL2Client)(nil).ChainID), arg0)
+}
+
+// GetProof mocks base method.
+func (m *MockL2Client) GetProof(arg0 context.Context, arg1 common.Address, arg2 []string, arg3 *big.Int) (*gethclient.AccountResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProof", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*gethclient.AccountResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProof indicates an expected call of GetProof.
+func (mr *MockL2ClientMockRecorder) GetProof(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProof", reflect.TypeOf((*MockL2Client)(nil).GetProof), arg0, arg1, arg2, arg3)
+}
+
+// HeaderByHash mocks base method.
+func (m *MockL2Client) HeaderByHash(arg0 context.Context, arg1 common.Hash) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeaderByHash", arg0, arg1)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeaderByHash indicates an expected call of HeaderByHash.
+func (mr *MockL2ClientMockRecorder) HeaderByHash(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByHash", reflect.TypeOf((*MockL2Client)(nil).HeaderByHash), arg0, arg1)
+}
+
+// HeaderByNumber mocks base method.
+func (m *MockL2Client) HeaderByNumber(arg0 context.Context, arg1 *big.Int) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HeaderByNumber", arg0, arg1)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HeaderByNumber indicates an expected call of HeaderByNumber.
+func (mr *MockL2ClientMockRecorder) HeaderByNumber(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockL2Client)(nil).HeaderByNumber), arg0, arg1)
+}
+
+// TransactionReceipt mocks base method.
+func (m *MockL2Client) TransactionReceipt(arg0 context.Context, arg1 common.Hash) (*types.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionReceipt", arg0, arg1)
+	ret0, _ := ret[0].(*types.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransactionReceipt indicates an expected call of TransactionReceipt.
+func (mr *MockL2ClientMockRecorder) TransactionReceipt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionReceipt", reflect.TypeOf((*MockL2Client)(nil).TransactionReceipt), arg0, arg1)
+}
+
+// MockSupervisorClient is a mock of SupervisorClient interface.
+type MockSupervisorClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockSupervisorClientMockRecorder
+}
+
+// MockSupervisorClientMockRecorder is the mock recorder for MockSupervisorClient.
+type MockSupervisorClientMockRecorder struct {
+	mock *MockSupervisorClient
+}
+
+// NewMockSupervisorClient creates a new mock instance.
+func NewMockSupervisorClient(ctrl *gomock.Controller) *MockSupervisorClient {
+	mock := &MockSupervisorClient{ctrl: ctrl}
+	mock.recorder = &MockSupervisorClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSupervisorClient) EXPECT() *MockSupervisorClientMockRecorder {
+	return m.recorder
+}
+
+// CallContext mocks base method.
+func (m *MockSupervisorClient) CallContext(arg0 context.Context, arg1 any, arg2 string, arg3 ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CallContext", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CallContext indicates an expected call of CallContext.
+func (mr *MockSupervisorClientMockRecorder) CallContext(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContext", reflect.TypeOf((*MockSupervisorClient)(nil).CallContext), varargs...)
+}
+
+// MockRollupClient is a mock of RollupClient interface.
+type MockRollupClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockRollupClientMockRecorder
+}
+
+// MockRollupClientMockRecorder is the mock recorder for MockRollupClient.
+type MockRollupClientMockRecorder struct {
+	mock *MockRollupClient
+}
+
+// NewMockRollupClient creates a new mock instance.
+func NewMockRollupClient(ctrl *gomock.Controller) *MockRollupClient {
+	mock := &MockRollupClient{ctrl: ctrl}
+	mock.recorder = &MockRollupClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRollupClient) EXPECT() *MockRollupClientMockRecorder {
+	return m.recorder
+}
+
+// CallContext mocks base method.
+func (m *MockRollupClient) CallContext(arg0 context.Context, arg1 any, arg2 string, arg3 ...any) error {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CallContext", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CallContext indicates an expected call of CallContext.
+func (mr *MockRollupClientMockRecorder) CallContext(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContext", reflect.TypeOf((*MockRollupClient)(nil).CallContext), varargs...)
+}
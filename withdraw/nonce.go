@@ -0,0 +1,43 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceManager coordinates the nonces of transactions sent concurrently from the same account, such as by
+// helpers working on several withdrawals at once, which would otherwise all use the same pending nonce.
+// Transactions are built and sent one at a time, while waiting for their confirmation isn't serialized.
+type NonceManager struct {
+	client L1Client
+	from   common.Address
+
+	mu   sync.Mutex
+	next uint64
+}
+
+func NewNonceManager(client L1Client, from common.Address) *NonceManager {
+	return &NonceManager{client: client, from: from}
+}
+
+// acquire locks the manager and returns the nonce to send the next transaction with. The returned
+// function must be called once the transaction was sent, or failed to be, to unlock it.
+func (m *NonceManager) acquire(ctx context.Context) (uint64, func(sent bool), error) {
+	m.mu.Lock()
+	pending, err := m.client.PendingNonceAt(ctx, m.from)
+	if err != nil {
+		m.mu.Unlock()
+		return 0, nil, fmt.Errorf("error querying nonce: %w", err)
+	}
+	// the node may not have seen the last transaction yet, e.g. behind a load balancer
+	nonce := max(pending, m.next)
+	return nonce, func(sent bool) {
+		if sent {
+			m.next = nonce + 1
+		}
+		m.mu.Unlock()
+	}, nil
+}
@@ -0,0 +1,73 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/mock/gomock"
+
+	"github.com/base-org/withdrawer/withdraw/mocks"
+)
+
+func TestNonceManager(t *testing.T) {
+	type step struct {
+		// pending is the pending nonce returned by the node, or err its error.
+		pending uint64
+		err     error
+		// sent is whether the transaction is sent with the acquired nonce.
+		sent bool
+		want uint64
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "node catches up",
+			steps: []step{{pending: 5, sent: true, want: 5}, {pending: 6, sent: true, want: 6}},
+		},
+		{
+			name:  "node lags behind",
+			steps: []step{{pending: 5, sent: true, want: 5}, {pending: 5, sent: true, want: 6}, {pending: 5, sent: true, want: 7}},
+		},
+		{
+			name:  "unsent nonce is reused",
+			steps: []step{{pending: 5, sent: true, want: 5}, {pending: 5, sent: false, want: 6}, {pending: 5, sent: true, want: 6}},
+		},
+		{
+			name:  "node ahead",
+			steps: []step{{pending: 5, sent: true, want: 5}, {pending: 9, sent: true, want: 9}},
+		},
+		{
+			name:  "query error",
+			steps: []step{{err: errors.New("connection refused")}, {pending: 3, sent: true, want: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			l1 := mocks.NewMockL1Client(ctrl)
+			from := common.HexToAddress("0x1234")
+			m := NewNonceManager(l1, from)
+			for i, s := range tt.steps {
+				l1.EXPECT().PendingNonceAt(gomock.Any(), from).Return(s.pending, s.err)
+				nonce, release, err := m.acquire(context.Background())
+				if s.err != nil {
+					if !errors.Is(err, s.err) {
+						t.Fatalf("step %d: acquire() error = %v, want %v", i, err, s.err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d: acquire() error = %v", i, err)
+				}
+				if nonce != s.want {
+					t.Fatalf("step %d: acquire() = %d, want %d", i, nonce, s.want)
+				}
+				release(s.sent)
+			}
+		})
+	}
+}
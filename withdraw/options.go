@@ -0,0 +1,330 @@
+package withdraw
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/base-org/withdrawer/signer"
+)
+
+// config is assembled by the options passed to the withdrawer constructors.
+type config struct {
+	l1            L1Client
+	l2            L2Client
+	supervisor    SupervisorClient
+	verify        L1Client
+	rollup        RollupClient
+	l2TxHash      common.Hash
+	portal        common.Address
+	oracle        common.Address
+	factory       common.Address
+	adapter       string
+	opts          *bind.TransactOpts
+	signer        signer.Signer
+	signerChainID *big.Int
+	settings      TxSettings
+}
+
+// Option configures a withdrawer created by NewWithdrawer, NewFPWithdrawer or NewSuperRootWithdrawer.
+type Option func(*config)
+
+// WithL1Client sets the client used to query the L1 contracts and send transactions.
+func WithL1Client(client L1Client) Option {
+	return func(c *config) { c.l1 = client }
+}
+
+// WithL2Client sets the client used to query the withdrawal and generate its proof.
+func WithL2Client(client L2Client) Option {
+	return func(c *config) { c.l2 = client }
+}
+
+// WithSupervisor sets the op-supervisor client super roots are queried from, required by NewSuperRootWithdrawer.
+func WithSupervisor(client SupervisorClient) Option {
+	return func(c *config) { c.supervisor = client }
+}
+
+// WithVerifyClient cross-checks the outputs, dispute games and proven withdrawals transactions rely on
+// against client, a second L1 provider independent of the L1 client, before sending them.
+func WithVerifyClient(client L1Client) Option {
+	return func(c *config) { c.verify = client }
+}
+
+// WithRollupClient checks the output roots withdrawals are proven against to match the ones computed by
+// client, an op-node rollup RPC, before sending the proof.
+func WithRollupClient(client RollupClient) Option {
+	return func(c *config) { c.rollup = client }
+}
+
+// WithL2TxHash sets the L2 transaction initiating the withdrawal.
+func WithL2TxHash(hash common.Hash) Option {
+	return func(c *config) { c.l2TxHash = hash }
+}
+
+// WithPortal sets the address of the OptimismPortal.
+func WithPortal(address common.Address) Option {
+	return func(c *config) { c.portal = address }
+}
+
+// WithL2OutputOracle sets the address of the L2OutputOracle, required by NewWithdrawer.
+func WithL2OutputOracle(address common.Address) Option {
+	return func(c *config) { c.oracle = address }
+}
+
+// WithDisputeGameFactory sets the address of the DisputeGameFactory, required by the fault proofs withdrawers.
+func WithDisputeGameFactory(address common.Address) Option {
+	return func(c *config) { c.factory = address }
+}
+
+// WithPortalAdapter sets the name of the registered portal adapter, which defaults to the adapter
+// matching the constructor.
+func WithPortalAdapter(name string) Option {
+	return func(c *config) { c.adapter = name }
+}
+
+// WithTransactOpts sets the options transactions are sent with.
+func WithTransactOpts(opts *bind.TransactOpts) Option {
+	return func(c *config) { c.opts = opts }
+}
+
+// WithSigner signs transactions with s for the L1 chain with chainID, using the pending nonce.
+func WithSigner(s signer.Signer, chainID *big.Int) Option {
+	return func(c *config) { c.signer, c.signerChainID = s, chainID }
+}
+
+// WithTxSettings replaces the settings of sent transactions. Options setting individual fields must
+// come after it.
+func WithTxSettings(settings TxSettings) Option {
+	return func(c *config) { c.settings = settings }
+}
+
+// WithPollInterval sets the initial interval between checks for transaction confirmation.
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *config) { c.settings.PollInterval = interval }
+}
+
+// WithConfirmTimeout sets how long sent transactions are waited for before giving up.
+func WithConfirmTimeout(timeout time.Duration) Option {
+	return func(c *config) { c.settings.ConfirmTimeout = timeout }
+}
+
+// WithConfirmations requires confirmations blocks to follow the block including a transaction and,
+// if tag is rpc.SafeBlockNumber or rpc.FinalizedBlockNumber, that block to be safe or finalized.
+func WithConfirmations(confirmations uint64, tag rpc.BlockNumber) Option {
+	return func(c *config) { c.settings.Confirmations, c.settings.ConfirmTag = confirmations, tag }
+}
+
+// WithReorgCheck checks confirmed transactions to still be canonical after blocks blocks.
+func WithReorgCheck(blocks uint64) Option {
+	return func(c *config) { c.settings.ReorgCheckBlocks = blocks }
+}
+
+// WithL2BlockTag requires the L2 block including the withdrawal to be at or below the L2 block with tag,
+// rpc.SafeBlockNumber or rpc.FinalizedBlockNumber, before it is provable.
+func WithL2BlockTag(tag rpc.BlockNumber) Option {
+	return func(c *config) { c.settings.L2BlockTag = tag }
+}
+
+// WithLogger passes the progress messages to logger instead of printing them to stdout.
+func WithLogger(logger Logger) Option {
+	return func(c *config) { c.settings.Logger = logger }
+}
+
+// WithL1Explorer links the transactions in the progress messages to the L1 block explorer at url,
+// e.g. https://etherscan.io.
+func WithL1Explorer(url string) Option {
+	return func(c *config) { c.settings.L1Explorer = url }
+}
+
+// WithProgress sends the progress of the actions to ch, dropping events while it is full.
+func WithProgress(ch chan<- ProgressEvent) Option {
+	return func(c *config) { c.settings.Progress = ch }
+}
+
+// WithHooks calls hooks as transactions are sent and confirmed.
+func WithHooks(hooks *Hooks) Option {
+	return func(c *config) { c.settings.Hooks = hooks }
+}
+
+// WithNoWait returns right after sending transactions, without waiting for their receipt.
+func WithNoWait() Option {
+	return func(c *config) { c.settings.NoWait = true }
+}
+
+// WithBuildOnly passes the transaction built for each action to fn, unsigned, instead of sending it.
+func WithBuildOnly(fn func(l2TxHash common.Hash, action string, tx *types.Transaction)) Option {
+	return func(c *config) { c.settings.BuildOnly = fn }
+}
+
+// newConfig applies opts and checks the fields required by all withdrawers.
+func newConfig(opts []Option) (*config, error) {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.l1 == nil {
+		return nil, errors.New("an L1 client is required")
+	}
+	if c.l2 == nil {
+		return nil, errors.New("an L2 client is required")
+	}
+	if c.l2TxHash == (common.Hash{}) {
+		return nil, errors.New("the L2 withdrawal tx hash is required")
+	}
+	if c.portal == (common.Address{}) {
+		return nil, errors.New("the portal address is required")
+	}
+	if c.opts != nil && c.signer != nil {
+		return nil, errors.New("transact opts and a signer are mutually exclusive")
+	}
+	switch {
+	case c.signer != nil:
+		if c.signerChainID == nil {
+			return nil, errors.New("the L1 chain ID is required to sign transactions")
+		}
+		// the nonce is left unset, so that the pending nonce is used when sending
+		c.opts = &bind.TransactOpts{From: c.signer.Address(), Signer: c.signer.SignerFn(c.signerChainID)}
+	case c.opts == nil:
+		// without a signer the withdrawer can only query the withdrawal
+		c.opts = &bind.TransactOpts{NoSend: true}
+	}
+	return c, nil
+}
+
+// crossChecker returns the CrossChecker of the verification client, or nil if none was set.
+func (c *config) crossChecker() *CrossChecker {
+	if c.verify == nil {
+		return nil
+	}
+	return &CrossChecker{Client: c.verify, Portal: c.portal, Oracle: c.oracle, Factory: c.factory}
+}
+
+// portalAdapter binds the configured portal adapter, or fallback if none was set.
+func (c *config) portalAdapter(fallback string) (PortalAdapter, error) {
+	name := c.adapter
+	if name == "" {
+		name = fallback
+	}
+	adapter, err := NewPortalAdapter(name, c.portal, WithGasBuffer(c.l1, c.settings.GasBuffer))
+	if err != nil {
+		return nil, fmt.Errorf("error binding portal adapter: %w", err)
+	}
+	return adapter, nil
+}
+
+// NewWithdrawer returns the withdrawer for chains without fault proofs, which requires the L1 and L2
+// clients, the L2 tx hash and the portal and L2OutputOracle addresses.
+func NewWithdrawer(opts ...Option) (*Withdrawer, error) {
+	c, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.oracle == (common.Address{}) {
+		return nil, errors.New("the L2OutputOracle address is required")
+	}
+	adapter, err := c.portalAdapter(OptimismPortalAdapter)
+	if err != nil {
+		return nil, err
+	}
+	oracle, err := bindings.NewL2OutputOracle(c.oracle, c.l1)
+	if err != nil {
+		return nil, fmt.Errorf("error binding L2OutputOracle contract: %w", err)
+	}
+	return &Withdrawer{
+		L1Client:      c.l1,
+		L2Client:      c.l2,
+		L2TxHash:      c.l2TxHash,
+		Portal:        adapter,
+		Oracle:        oracle,
+		OracleAddress: c.oracle,
+		CrossChecker:  c.crossChecker(),
+		Rollup:        c.rollup,
+		Opts:          c.opts,
+		TxSettings:    c.settings,
+	}, nil
+}
+
+// faultProofContracts binds the portal and dispute game factory of fault proofs chains.
+func (c *config) faultProofContracts() (*bindingspreview.OptimismPortal2, *bindings.DisputeGameFactory, error) {
+	if c.factory == (common.Address{}) {
+		return nil, nil, errors.New("the DisputeGameFactory address is required")
+	}
+	portal, err := bindingspreview.NewOptimismPortal2(c.portal, WithGasBuffer(c.l1, c.settings.GasBuffer))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error binding OptimismPortal2 contract: %w", err)
+	}
+	factory, err := bindings.NewDisputeGameFactory(c.factory, c.l1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error binding DisputeGameFactory contract: %w", err)
+	}
+	return portal, factory, nil
+}
+
+// NewFPWithdrawer returns the withdrawer for fault proofs chains, which requires the L1 and L2 clients,
+// the L2 tx hash and the portal and DisputeGameFactory addresses.
+func NewFPWithdrawer(opts ...Option) (*FPWithdrawer, error) {
+	c, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.factory == (common.Address{}) {
+		return nil, errors.New("the DisputeGameFactory address is required")
+	}
+	factory, err := bindings.NewDisputeGameFactory(c.factory, c.l1)
+	if err != nil {
+		return nil, fmt.Errorf("error binding DisputeGameFactory contract: %w", err)
+	}
+	adapter, err := c.portalAdapter(OptimismPortal2Adapter)
+	if err != nil {
+		return nil, err
+	}
+	return &FPWithdrawer{
+		L1Client:      c.l1,
+		L2Client:      c.l2,
+		L2TxHash:      c.l2TxHash,
+		PortalAddress: c.portal,
+		Adapter:       adapter,
+		Factory:       factory,
+		CrossChecker:  c.crossChecker(),
+		Rollup:        c.rollup,
+		Opts:          c.opts,
+		TxSettings:    c.settings,
+	}, nil
+}
+
+// NewSuperRootWithdrawer returns the withdrawer for interop chains, which requires the options of
+// NewFPWithdrawer and a supervisor client.
+func NewSuperRootWithdrawer(opts ...Option) (*SuperRootWithdrawer, error) {
+	c, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	if c.supervisor == nil {
+		return nil, errors.New("interop withdrawals require a supervisor client")
+	}
+	portal, factory, err := c.faultProofContracts()
+	if err != nil {
+		return nil, err
+	}
+	return &SuperRootWithdrawer{
+		L1Client:      c.l1,
+		L2Client:      c.l2,
+		Supervisor:    c.supervisor,
+		L2TxHash:      c.l2TxHash,
+		PortalAddress: c.portal,
+		Portal:        portal,
+		Factory:       factory,
+		CrossChecker:  c.crossChecker(),
+		Rollup:        c.rollup,
+		Opts:          c.opts,
+		TxSettings:    c.settings,
+	}, nil
+}
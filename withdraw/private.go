@@ -0,0 +1,69 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultPrivateFallbackBlocks is the number of blocks to wait for a privately sent transaction to be
+// included before sending it publicly, if none is configured.
+const defaultPrivateFallbackBlocks = 10
+
+// sendPrivate sends tx through the private relay, returning the block after which it is sent publicly
+// instead. If the relay rejects the transaction, it is sent publicly right away.
+func (s TxSettings) sendPrivate(ctx context.Context, client L1Client, tx *types.Transaction) (uint64, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error querying L1 head: %w", err)
+	}
+	blocks := s.PrivateFallbackBlocks
+	if blocks == 0 {
+		blocks = defaultPrivateFallbackBlocks
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	deadline := head + blocks
+	err = s.PrivateRelay.CallContext(ctx, nil, "eth_sendPrivateTransaction", map[string]interface{}{
+		"tx":             hexutil.Bytes(raw),
+		"maxBlockNumber": hexutil.Uint64(deadline),
+	})
+	if err != nil {
+		s.logger().Printf("Private relay rejected tx %s, sending it publicly: %v\n", tx.Hash().String(), err)
+		return 0, client.SendTransaction(ctx, tx)
+	}
+	s.logger().Printf("Sent tx %s to the private relay, sending it publicly if it isn't included by block %d\n", tx.Hash().String(), deadline)
+	return deadline, nil
+}
+
+// awaitPrivateInclusion waits for the privately sent tx to be included, and sends it publicly once
+// the deadline block has passed without that.
+func (s TxSettings) awaitPrivateInclusion(ctx context.Context, client L1Client, tx *types.Transaction, deadline uint64) error {
+	waiter := newHeadWaiter(ctx, client, s.PollInterval)
+	defer waiter.close()
+	for {
+		_, err := client.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		if head > deadline {
+			s.logger().Printf("Tx %s wasn't included by the private relay, sending it publicly\n", tx.Hash().String())
+			return client.SendTransaction(ctx, tx)
+		}
+		if err := waiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
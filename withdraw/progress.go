@@ -0,0 +1,55 @@
+package withdraw
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Stage is the step a prove or finalize action is at.
+type Stage string
+
+const (
+	// StageGeneratingProof is reported while the withdrawal proof is generated from L2 state.
+	StageGeneratingProof Stage = "generating-proof"
+	// StageSubmitting is reported before a transaction is handed to the tx manager.
+	StageSubmitting Stage = "submitting"
+	// StageSubmitted is reported once a transaction was sent.
+	StageSubmitted Stage = "submitted"
+	// StageWaitingForInclusion is reported while a sent transaction isn't mined yet.
+	StageWaitingForInclusion Stage = "waiting-for-inclusion"
+	// StageWaitingForConfirmation is reported while a mined transaction awaits further blocks or its
+	// block to become safe or finalized.
+	StageWaitingForConfirmation Stage = "waiting-for-confirmation"
+	// StageConfirmed is reported once a transaction is confirmed.
+	StageConfirmed Stage = "confirmed"
+)
+
+// ProgressEvent describes the progress of a prove or finalize action, for UIs showing live progress.
+type ProgressEvent struct {
+	L2TxHash common.Hash
+	// Action is either "prove" or "finalize".
+	Action string
+	Stage  Stage
+	// Tx is the L1 transaction of the action, once sent.
+	Tx common.Hash
+	// Block is the L1 block including Tx, once mined.
+	Block uint64
+	// Message is the progress message, as printed by the Logger.
+	Message string
+}
+
+// report prints the progress message and sends ev with it to the Progress channel, unless the channel
+// is full.
+func (s TxSettings) report(ev ProgressEvent, format string, args ...any) {
+	s.logger().Printf(format, args...)
+	if s.Progress == nil {
+		return
+	}
+	ev.Message = strings.TrimSuffix(fmt.Sprintf(format, args...), "\n")
+	select {
+	case s.Progress <- ev:
+	default:
+	}
+}
@@ -0,0 +1,47 @@
+package withdraw
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// verifyWithdrawalProof verifies the storage proof of params against the MessagePasser storage root of
+// its output root proof, as the portal does, so that a proof that would revert on-chain isn't sent.
+func verifyWithdrawalProof(params withdrawals.ProvenWithdrawalParameters) error {
+	hash, err := withdrawals.WithdrawalHash(&bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    params.Nonce,
+		Sender:   params.Sender,
+		Target:   params.Target,
+		Value:    params.Value,
+		GasLimit: params.GasLimit,
+		Data:     params.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	nodes := memorydb.New()
+	for _, node := range params.WithdrawalProof {
+		if err := nodes.Put(crypto.Keccak256(node), node); err != nil {
+			return err
+		}
+	}
+	// the sentMessages mapping of the MessagePasser sets the slot of the withdrawal hash to true
+	slot := withdrawals.StorageSlotOfWithdrawalHash(hash)
+	root := params.OutputRootProof.MessagePasserStorageRoot
+	value, err := trie.VerifyProof(root, crypto.Keccak256(slot[:]), nodes)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	if !bytes.Equal(value, []byte{1}) {
+		return fmt.Errorf("%w: withdrawal %s is not sent in MessagePasser storage root %s", ErrInvalidProof, hash, common.Hash(root))
+	}
+	return nil
+}
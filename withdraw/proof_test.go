@@ -0,0 +1,58 @@
+package withdraw
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestVerifyWithdrawalProof(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	tests := []struct {
+		name    string
+		modify  func(p *withdrawals.ProvenWithdrawalParameters)
+		wantErr bool
+	}{
+		{
+			name:   "valid proof",
+			modify: func(*withdrawals.ProvenWithdrawalParameters) {},
+		},
+		{
+			name: "other withdrawal",
+			modify: func(p *withdrawals.ProvenWithdrawalParameters) {
+				p.Value = big.NewInt(2e18)
+			},
+			wantErr: true,
+		},
+		{
+			name: "other storage root",
+			modify: func(p *withdrawals.ProvenWithdrawalParameters) {
+				p.OutputRootProof.MessagePasserStorageRoot = common.HexToHash("0x1234")
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing proof nodes",
+			modify: func(p *withdrawals.ProvenWithdrawalParameters) {
+				p.WithdrawalProof = p.WithdrawalProof[:0]
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := w.params(0)
+			tt.modify(&params)
+			err := verifyWithdrawalProof(params)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("verifyWithdrawalProof() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidProof) {
+				t.Fatalf("verifyWithdrawalProof() error = %v, want ErrInvalidProof", err)
+			}
+		})
+	}
+}
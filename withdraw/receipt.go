@@ -0,0 +1,110 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// l2Receipt holds the receipt of a withdrawal's L2 transaction and its parsed MessagePassed event, which
+// most steps need, so that a helper fetches and parses them only once.
+type l2Receipt struct {
+	mu      sync.Mutex
+	receipt *types.Receipt
+	event   *bindings.L2ToL1MessagePasserMessagePassed
+}
+
+// get returns the receipt of l2TxHash, fetching it on first use.
+func (r *l2Receipt) get(ctx context.Context, l2c L2Client, l2TxHash common.Hash) (*types.Receipt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.receipt == nil {
+		receipt, err := l2c.TransactionReceipt(ctx, l2TxHash)
+		if err != nil {
+			return nil, err
+		}
+		r.receipt = receipt
+	}
+	return r.receipt, nil
+}
+
+// block returns the number of the L2 block that includes the successful withdrawal transaction l2TxHash.
+func (r *l2Receipt) block(ctx context.Context, l2c L2Client, l2TxHash common.Hash) (*big.Int, error) {
+	receipt, err := r.get(ctx, l2c, l2TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, ErrWithdrawalFailed
+	}
+	return receipt.BlockNumber, nil
+}
+
+// messagePassed returns the MessagePassed event initiating the withdrawal in l2TxHash.
+func (r *l2Receipt) messagePassed(ctx context.Context, l2c L2Client, l2TxHash common.Hash) (*bindings.L2ToL1MessagePasserMessagePassed, error) {
+	receipt, err := r.get(ctx, l2c, l2TxHash)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.event == nil {
+		ev, err := withdrawals.ParseMessagePassed(receipt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s emits no MessagePassed event", ErrNotWithdrawal, l2TxHash)
+		}
+		r.event = ev
+	}
+	return r.event, nil
+}
+
+// withdrawalHash returns the hash of the withdrawal initiated in l2TxHash.
+func (r *l2Receipt) withdrawalHash(ctx context.Context, l2c L2Client, l2TxHash common.Hash) (common.Hash, error) {
+	ev, err := r.messagePassed(ctx, l2c, l2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return withdrawals.WithdrawalHash(ev)
+}
+
+// withdrawalParams returns the parameters of the withdrawal initiated in l2TxHash, without the proof, which
+// finalization doesn't need.
+func (r *l2Receipt) withdrawalParams(ctx context.Context, l2c L2Client, l2TxHash common.Hash) (withdrawals.ProvenWithdrawalParameters, error) {
+	ev, err := r.messagePassed(ctx, l2c, l2TxHash)
+	if err != nil {
+		return withdrawals.ProvenWithdrawalParameters{}, err
+	}
+	return withdrawals.ProvenWithdrawalParameters{
+		Nonce:    ev.Nonce,
+		Sender:   ev.Sender,
+		Target:   ev.Target,
+		Value:    ev.Value,
+		GasLimit: ev.GasLimit,
+		Data:     ev.Data,
+	}, nil
+}
+
+// client returns a receipt client for the op-node withdrawals package, which serves the receipt of
+// l2TxHash from r.
+func (r *l2Receipt) client(l2c L2Client, l2TxHash common.Hash) withdrawals.ReceiptClient {
+	return receiptClient{r: r, l2c: l2c, l2TxHash: l2TxHash}
+}
+
+type receiptClient struct {
+	r        *l2Receipt
+	l2c      L2Client
+	l2TxHash common.Hash
+}
+
+func (c receiptClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if txHash == c.l2TxHash {
+		return c.r.get(ctx, c.l2c, txHash)
+	}
+	return c.l2c.TransactionReceipt(ctx, txHash)
+}
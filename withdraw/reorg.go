@@ -0,0 +1,66 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// awaitCanonical waits until the confirmed receipt of txHash is ReorgCheckBlocks blocks deep and checks that
+// it is still canonical. If the transaction was reorged out, it is waited for again, after rebroadcasting tx
+// if it was dropped from the mempool. Without tx (e.g. for a transaction sent by a previous run), a dropped
+// transaction is returned as an error, so that the next run sends it again.
+func (s TxSettings) awaitCanonical(ctx context.Context, client L1Client, l2TxHash common.Hash, action string, txHash common.Hash, tx *types.Transaction, receipt *types.Receipt) (*types.Receipt, error) {
+	waiter := newHeadWaiter(ctx, client, s.PollInterval)
+	defer waiter.close()
+	for {
+		target := receipt.BlockNumber.Uint64() + s.ReorgCheckBlocks
+		for {
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if head >= target {
+				break
+			}
+			if err := waiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		current, err := client.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			if current.BlockHash == receipt.BlockHash {
+				return current, nil
+			}
+			if current.Status != types.ReceiptStatusSuccessful {
+				return nil, fmt.Errorf("tx %s was reorged into block %d and failed there", txHash, current.BlockNumber)
+			}
+			s.logger().Printf("%s was reorged into block %d, checking it again\n", txHash.String(), current.BlockNumber)
+			receipt = current
+			continue
+		} else if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+
+		s.logger().Printf("%s was reorged out of block %d\n", txHash.String(), receipt.BlockNumber)
+		if _, _, err := client.TransactionByHash(ctx, txHash); errors.Is(err, ethereum.NotFound) {
+			if tx == nil {
+				return nil, fmt.Errorf("tx %s was reorged out and dropped, rerun to send it again", txHash)
+			}
+			s.logger().Printf("Resubmitting %s\n", txHash.String())
+			if err := client.SendTransaction(ctx, tx); err != nil {
+				return nil, fmt.Errorf("error resubmitting reorged tx %s: %w", txHash, err)
+			}
+		} else if err != nil {
+			return nil, err
+		}
+		if receipt, err = s.waitForConfirmation(ctx, client, l2TxHash, action, txHash); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// minReplacementBump is the fee increase, in percent, nodes require to accept a replacement transaction.
+const minReplacementBump = 10
+
+// ErrTxNotPending is returned when replacing a transaction that is no longer pending.
+var ErrTxNotPending = errors.New("transaction is not pending")
+
+// ReplaceTx sends a transaction with the same nonce as the pending transaction txHash, paying fees bumped
+// far enough for nodes to accept it as a replacement. Fees set in opts are used instead if they are high
+// enough. The replacement repeats the original call, or with cancel, is an empty self-transfer that
+// cancels it.
+func ReplaceTx(ctx context.Context, client *ethclient.Client, opts *bind.TransactOpts, txHash common.Hash, cancel bool) (*types.Transaction, error) {
+	tx, isPending, err := client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tx %s: %w", txHash, err)
+	}
+	if !isPending {
+		return nil, fmt.Errorf("%w: %s", ErrTxNotPending, txHash)
+	}
+
+	to, data, value, gas := tx.To(), tx.Data(), tx.Value(), tx.Gas()
+	if cancel {
+		to, data, value, gas = &opts.From, nil, new(big.Int), params.TxGas
+	}
+
+	var replacement types.TxData
+	if tx.Type() == types.LegacyTxType {
+		suggested, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying gas price: %w", err)
+		}
+		gasPrice, err := replacementFee("gas price", tx.GasPrice(), suggested, opts.GasPrice)
+		if err != nil {
+			return nil, err
+		}
+		replacement = &types.LegacyTx{Nonce: tx.Nonce(), GasPrice: gasPrice, Gas: gas, To: to, Value: value, Data: data}
+	} else {
+		suggestedTip, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error querying gas tip cap: %w", err)
+		}
+		head, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error querying L1 head: %w", err)
+		}
+		tipCap, err := replacementFee("priority fee", tx.GasTipCap(), suggestedTip, opts.GasTipCap)
+		if err != nil {
+			return nil, err
+		}
+		suggestedFee := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tipCap)
+		feeCap, err := replacementFee("max fee", tx.GasFeeCap(), suggestedFee, opts.GasFeeCap)
+		if err != nil {
+			return nil, err
+		}
+		replacement = &types.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tipCap,
+			GasFeeCap:  feeCap,
+			Gas:        gas,
+			To:         to,
+			Value:      value,
+			Data:       data,
+			AccessList: tx.AccessList(),
+		}
+		if cancel {
+			replacement.(*types.DynamicFeeTx).AccessList = nil
+		}
+	}
+
+	signed, err := opts.Signer(opts.From, types.NewTx(replacement))
+	if err != nil {
+		return nil, fmt.Errorf("error signing replacement tx: %w", err)
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("error sending replacement tx: %w", err)
+	}
+	return signed, nil
+}
+
+// replacementFee returns the fee override if set, or the higher of the suggested fee and the original fee
+// bumped by minReplacementBump, which an override must not fall short of either.
+func replacementFee(name string, original, suggested, override *big.Int) (*big.Int, error) {
+	min := new(big.Int).Mul(original, big.NewInt(100+minReplacementBump))
+	min.Div(min, big.NewInt(100))
+	min.Add(min, common.Big1)
+	if override != nil {
+		if override.Cmp(min) < 0 {
+			return nil, fmt.Errorf("%s %s is too low to replace the pending tx, which requires at least %s", name, override, min)
+		}
+		return override, nil
+	}
+	if suggested.Cmp(min) > 0 {
+		return suggested, nil
+	}
+	return min, nil
+}
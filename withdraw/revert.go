@@ -0,0 +1,102 @@
+package withdraw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RevertError is returned when a portal or dispute game call reverts with data that could be decoded.
+// Known portal revert reasons match the corresponding errors of this package with errors.Is.
+type RevertError struct {
+	Reason string
+	err    error
+}
+
+func (e *RevertError) Is(target error) bool {
+	kind, ok := revertErrors[e.Reason]
+	return ok && kind == target
+}
+
+func (e *RevertError) Error() string {
+	return fmt.Sprintf("execution reverted: %s", e.Reason)
+}
+
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+var (
+	revertABIsOnce sync.Once
+	revertABIs     []*abi.ABI
+)
+
+// loadRevertABIs returns the ABIs of all contracts a withdrawal interacts with, whose custom errors
+// may surface in a revert.
+func loadRevertABIs() []*abi.ABI {
+	revertABIsOnce.Do(func() {
+		for _, load := range []func() (*abi.ABI, error){
+			bindings.OptimismPortalMetaData.GetAbi,
+			bindingspreview.OptimismPortal2MetaData.GetAbi,
+			bindings.DisputeGameFactoryMetaData.GetAbi,
+			func() (*abi.ABI, error) { return snapshots.LoadFaultDisputeGameABI(), nil },
+		} {
+			if parsed, err := load(); err == nil {
+				revertABIs = append(revertABIs, parsed)
+			}
+		}
+	})
+	return revertABIs
+}
+
+// decodeRevert converts err into a RevertError if it carries revert data that is either a revert
+// reason string, a panic code, or a custom error of one of the withdrawal contracts. Any other error
+// is returned unchanged.
+func decodeRevert(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil || len(data) < 4 {
+		return err
+	}
+
+	if reason, unpackErr := abi.UnpackRevert(data); unpackErr == nil {
+		return &RevertError{Reason: reason, err: err}
+	}
+
+	for _, parsed := range loadRevertABIs() {
+		for _, customErr := range parsed.Errors {
+			if string(customErr.ID[:4]) != string(data[:4]) {
+				continue
+			}
+			values, unpackErr := customErr.Inputs.Unpack(data[4:])
+			if unpackErr != nil {
+				continue
+			}
+			args := make([]string, len(values))
+			for i, v := range values {
+				args[i] = fmt.Sprintf("%v", v)
+			}
+			return &RevertError{Reason: fmt.Sprintf("%s(%s)", customErr.Name, strings.Join(args, ", ")), err: err}
+		}
+	}
+	return err
+}
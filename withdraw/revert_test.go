@@ -0,0 +1,114 @@
+package withdraw
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// dataError is an RPC error carrying revert data, as returned by eth_call and eth_estimateGas.
+type dataError struct {
+	data interface{}
+}
+
+func (e dataError) Error() string          { return "execution reverted" }
+func (e dataError) ErrorCode() int         { return 3 }
+func (e dataError) ErrorData() interface{} { return e.data }
+
+// revertData returns the revert data of a call failing with the error of signature sig and the given
+// arguments.
+func revertData(t *testing.T, sig string, args abi.Arguments, values ...interface{}) string {
+	t.Helper()
+	packed, err := args.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hexutil.Encode(append(crypto.Keccak256([]byte(sig))[:4], packed...))
+}
+
+// stringArgs are the arguments of the Error(string) revert.
+func stringArgs() abi.Arguments {
+	stringType, _ := abi.NewType("string", "", nil)
+	return abi.Arguments{{Type: stringType}}
+}
+
+func TestDecodeRevert(t *testing.T) {
+	uintType, _ := abi.NewType("uint256", "", nil)
+	plain := errors.New("connection refused")
+	tests := []struct {
+		name string
+		err  error
+		// wantReason is the reason of the returned RevertError, or empty if err is returned unchanged.
+		wantReason string
+		wantIs     error
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "not a revert",
+			err:  plain,
+		},
+		{
+			name: "no revert data",
+			err:  dataError{data: nil},
+		},
+		{
+			name: "invalid revert data",
+			err:  dataError{data: "0x12"},
+		},
+		{
+			name:       "known revert reason",
+			err:        dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: proven withdrawal has not matured yet")},
+			wantReason: "OptimismPortal: proven withdrawal has not matured yet",
+			wantIs:     ErrChallengePeriodActive,
+		},
+		{
+			name:       "unknown revert reason",
+			err:        dataError{data: revertData(t, "Error(string)", stringArgs(), "something else")},
+			wantReason: "something else",
+		},
+		{
+			name:       "panic",
+			err:        dataError{data: revertData(t, "Panic(uint256)", abi.Arguments{{Type: uintType}}, big.NewInt(1))},
+			wantReason: "assert(false)",
+		},
+		{
+			name:       "custom error",
+			err:        dataError{data: revertData(t, "CallPaused()", nil)},
+			wantReason: "CallPaused()",
+		},
+		{
+			name: "unknown custom error",
+			err:  dataError{data: revertData(t, "Unknown()", nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeRevert(tt.err)
+			var revert *RevertError
+			if !errors.As(err, &revert) {
+				if tt.wantReason != "" {
+					t.Fatalf("decodeRevert() = %v, want reason %q", err, tt.wantReason)
+				}
+				if err != tt.err {
+					t.Fatalf("decodeRevert() = %v, want %v unchanged", err, tt.err)
+				}
+				return
+			}
+			if revert.Reason != tt.wantReason {
+				t.Fatalf("decodeRevert() reason = %q, want %q", revert.Reason, tt.wantReason)
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Fatalf("decodeRevert() = %v, want it to match %v", err, tt.wantIs)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("decodeRevert() = %v, want it to wrap %v", err, tt.err)
+			}
+		})
+	}
+}
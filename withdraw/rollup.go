@@ -0,0 +1,29 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// checkRollupOutput checks the output root the rollup node computes for L2 block number block to be
+// root, as proposed on L1 in source, before a proof against it is sent. It does nothing without a
+// rollup client.
+func checkRollupOutput(ctx context.Context, rollup RollupClient, block uint64, root common.Hash, source string) error {
+	if rollup == nil {
+		return nil
+	}
+	var output struct {
+		OutputRoot common.Hash `json:"outputRoot"`
+	}
+	if err := rollup.CallContext(ctx, &output, "optimism_outputAtBlock", hexutil.Uint64(block)); err != nil {
+		return fmt.Errorf("error querying output root of L2 block %d from the rollup node: %w", block, err)
+	}
+	if output.OutputRoot != root {
+		return fmt.Errorf("%w: %s has root %s for L2 block %d, but the rollup node computes %s",
+			ErrOutputRootMismatch, source, root, block, output.OutputRoot)
+	}
+	return nil
+}
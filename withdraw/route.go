@@ -0,0 +1,113 @@
+package withdraw
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// relayABI holds the L1CrossDomainMessenger and L1StandardBridge methods withdrawals through the standard
+// bridge call, to decode the amounts they transfer.
+const relayABI = `[
+	{"inputs":[{"name":"_nonce","type":"uint256"},{"name":"_sender","type":"address"},{"name":"_target","type":"address"},{"name":"_value","type":"uint256"},{"name":"_minGasLimit","type":"uint256"},{"name":"_message","type":"bytes"}],"name":"relayMessage","outputs":[],"stateMutability":"payable","type":"function"},
+	{"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"},{"name":"_extraData","type":"bytes"}],"name":"finalizeBridgeETH","outputs":[],"stateMutability":"payable","type":"function"},
+	{"inputs":[{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"},{"name":"_extraData","type":"bytes"}],"name":"finalizeETHWithdrawal","outputs":[],"stateMutability":"payable","type":"function"},
+	{"inputs":[{"name":"_localToken","type":"address"},{"name":"_remoteToken","type":"address"},{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"},{"name":"_extraData","type":"bytes"}],"name":"finalizeBridgeERC20","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"_l1Token","type":"address"},{"name":"_l2Token","type":"address"},{"name":"_from","type":"address"},{"name":"_to","type":"address"},{"name":"_amount","type":"uint256"},{"name":"_extraData","type":"bytes"}],"name":"finalizeERC20Withdrawal","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+var (
+	relayABIOnce sync.Once
+	relayParsed  *abi.ABI
+)
+
+func loadRelayABI() *abi.ABI {
+	relayABIOnce.Do(func() {
+		if parsed, err := abi.JSON(strings.NewReader(relayABI)); err == nil {
+			relayParsed = &parsed
+		}
+	})
+	return relayParsed
+}
+
+// BridgeTransfer is an ETH or ERC-20 transfer of the standard bridge.
+type BridgeTransfer struct {
+	// Token is the L1 token, or the zero address for ETH.
+	Token    common.Address
+	From, To common.Address
+	Amount   *big.Int
+}
+
+// Route describes how a withdrawal was routed: directly through the L2ToL1MessagePasser, through the
+// CrossDomainMessenger, or through the standard bridge on top of it. Withdrawals initiated by aggregators
+// or custom bridge contracts have these contracts as their sender, so they are attributed to the end user
+// by decoding the nested calls.
+type Route struct {
+	// Sender and Target are the L2 sender and L1 target of the message relayed by the CrossDomainMessenger,
+	// or of the withdrawal itself if it wasn't sent through the messenger.
+	Sender, Target common.Address
+	Messenger      bool
+	// Bridged is set for withdrawals relaying a transfer of the standard bridge.
+	Bridged *BridgeTransfer
+}
+
+// DecodeRoute decodes the route of the withdrawal from sender to target with the given data.
+func DecodeRoute(sender, target common.Address, data []byte) Route {
+	r := Route{Sender: sender, Target: target}
+	relay := loadRelayABI()
+	args := unpackCall(relay, data, "relayMessage")
+	if args == nil {
+		return r
+	}
+	r.Messenger = true
+	r.Sender, r.Target = args[1].(common.Address), args[2].(common.Address)
+	message := args[5].([]byte)
+	if args := unpackCall(relay, message, "finalizeBridgeETH", "finalizeETHWithdrawal"); args != nil {
+		r.Bridged = &BridgeTransfer{From: args[0].(common.Address), To: args[1].(common.Address), Amount: args[2].(*big.Int)}
+	} else if args := unpackCall(relay, message, "finalizeBridgeERC20", "finalizeERC20Withdrawal"); args != nil {
+		r.Bridged = &BridgeTransfer{Token: args[0].(common.Address), From: args[2].(common.Address), To: args[3].(common.Address), Amount: args[4].(*big.Int)}
+	}
+	return r
+}
+
+// From returns the L2 account the withdrawal is attributed to: the sender of its bridge transfer, or of
+// its message.
+func (r Route) From() common.Address {
+	if r.Bridged != nil {
+		return r.Bridged.From
+	}
+	return r.Sender
+}
+
+// To returns the L1 account the withdrawal is attributed to: the recipient of its bridge transfer, or the
+// target of its message.
+func (r Route) To() common.Address {
+	if r.Bridged != nil {
+		return r.Bridged.To
+	}
+	return r.Target
+}
+
+// unpackCall returns the arguments of data if it calls one of the given methods of parsed.
+func unpackCall(parsed *abi.ABI, data []byte, methods ...string) []interface{} {
+	if parsed == nil || len(data) < 4 {
+		return nil
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return nil
+	}
+	for _, name := range methods {
+		if method.Name == name {
+			args, err := method.Inputs.Unpack(data[4:])
+			if err != nil {
+				return nil
+			}
+			return args
+		}
+	}
+	return nil
+}
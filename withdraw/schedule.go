@@ -0,0 +1,183 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// Scheduler is implemented by withdraw helpers that can estimate when the next step of a withdrawal
+// becomes possible. Estimates are lower bounds: retrying earlier can't succeed, retrying later may.
+type Scheduler interface {
+	// EarliestProveTime estimates when an output or dispute game covering the withdrawal will be proposed.
+	EarliestProveTime(ctx context.Context) (time.Time, error)
+	// EarliestFinalizeTime returns when the proven withdrawal will have matured.
+	EarliestFinalizeTime(ctx context.Context) (time.Time, error)
+}
+
+func (w *Withdrawer) EarliestProveTime(ctx context.Context) (time.Time, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return time.Time{}, err
+	}
+	var latest, interval, l2WithdrawalBlock *big.Int
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() (err error) {
+		if l2WithdrawalBlock, err = w.receipt.block(gctx, w.L2Client, w.L2TxHash); err != nil {
+			return fmt.Errorf("error querying withdrawal tx block: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		err := callBatch(gctx, w.L1Client,
+			batchCall{abi: oracleABI, to: w.OracleAddress, method: "latestBlockNumber", out: &latest},
+			batchCall{abi: oracleABI, to: w.OracleAddress, method: "SUBMISSION_INTERVAL", out: &interval, constant: true},
+		)
+		if err != nil {
+			return fmt.Errorf("error querying output proposals: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return time.Time{}, err
+	}
+
+	// outputs are proposed every interval blocks, the first one at or after the withdrawal block covers it
+	next := new(big.Int).Set(latest)
+	if next.Cmp(l2WithdrawalBlock) < 0 {
+		missing := new(big.Int).Sub(l2WithdrawalBlock, latest)
+		proposals := new(big.Int).Div(new(big.Int).Add(missing, new(big.Int).Sub(interval, common.Big1)), interval)
+		next.Add(latest, proposals.Mul(proposals, interval))
+	}
+	ts, err := w.Oracle.ComputeL2Timestamp(opts, next)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error computing L2 timestamp of block %d: %w", next, err)
+	}
+	return time.Unix(ts.Int64(), 0), nil
+}
+
+func (w *Withdrawer) EarliestFinalizeTime(ctx context.Context) (time.Time, error) {
+	proofTime, err := w.GetProvenWithdrawalTime(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if proofTime == 0 {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrNotProven, w.L2TxHash)
+	}
+	finalizationPeriod, err := w.finalizationPeriod(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(proofTime+finalizationPeriod.Uint64()), 0), nil
+}
+
+// finalizationPeriod returns the oracle's FINALIZATION_PERIOD_SECONDS, queried once per run.
+func (w *Withdrawer) finalizationPeriod(ctx context.Context) (*big.Int, error) {
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	var period *big.Int
+	err = callBatch(ctx, w.L1Client,
+		batchCall{abi: oracleABI, to: w.OracleAddress, method: "FINALIZATION_PERIOD_SECONDS", out: &period, constant: true})
+	if err != nil {
+		return nil, fmt.Errorf("error querying finalization period: %w", err)
+	}
+	return period, nil
+}
+
+func (w *FPWithdrawer) EarliestProveTime(ctx context.Context) (time.Time, error) {
+	return nextGameTime(ctx, w.Factory)
+}
+
+func (w *FPWithdrawer) EarliestFinalizeTime(ctx context.Context) (time.Time, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return faultProofMaturity(ctx, w.L1Client, w.Adapter, w.PortalAddress, hash, w.Opts.From)
+}
+
+func (w *SuperRootWithdrawer) EarliestProveTime(ctx context.Context) (time.Time, error) {
+	return nextGameTime(ctx, w.Factory)
+}
+
+func (w *SuperRootWithdrawer) EarliestFinalizeTime(ctx context.Context) (time.Time, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return faultProofMaturity(ctx, w.L1Client, w.adapter(), w.PortalAddress, hash, w.Opts.From)
+}
+
+// nextGameTime estimates when the next dispute game will be created, assuming games are created at the
+// same cadence as the last two.
+func nextGameTime(ctx context.Context, factory *bindings.DisputeGameFactory) (time.Time, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	count, err := factory.GameCount(opts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error querying game count: %w", err)
+	}
+	if count.Cmp(common.Big2) < 0 {
+		return time.Time{}, fmt.Errorf("not enough dispute games to estimate the proposal cadence")
+	}
+	last, err := factory.GameAtIndex(opts, new(big.Int).Sub(count, common.Big1))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error querying latest game: %w", err)
+	}
+	previous, err := factory.GameAtIndex(opts, new(big.Int).Sub(count, common.Big2))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error querying previous game: %w", err)
+	}
+	return time.Unix(int64(2*last.Timestamp-previous.Timestamp), 0), nil
+}
+
+// faultProofMaturity returns when the withdrawal with the given hash, proven by submitter, can be
+// finalized: once the proof has matured, and the dispute game it was proven against has resolved and
+// its finality delay has passed.
+func faultProofMaturity(ctx context.Context, l1 L1Client, portal PortalAdapter, portalAddress common.Address, hash common.Hash, submitter common.Address) (time.Time, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	proven, err := portal.ProvenWithdrawal(opts, hash, submitter)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error querying proven withdrawal: %w", err)
+	}
+	if proven.Timestamp == 0 {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrNotProven, hash)
+	}
+	portalABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	if err != nil {
+		return time.Time{}, err
+	}
+	gameABI := snapshots.LoadFaultDisputeGameABI()
+	var maturityDelay, finalityDelay *big.Int
+	var resolvedAt, createdAt, maxClockDuration uint64
+	err = callBatch(ctx, l1,
+		batchCall{abi: portalABI, to: portalAddress, method: "proofMaturityDelaySeconds", out: &maturityDelay, constant: true},
+		batchCall{abi: portalABI, to: portalAddress, method: "disputeGameFinalityDelaySeconds", out: &finalityDelay, constant: true},
+		batchCall{abi: gameABI, to: proven.DisputeGame, method: "resolvedAt", out: &resolvedAt},
+		batchCall{abi: gameABI, to: proven.DisputeGame, method: "createdAt", out: &createdAt, constant: true},
+		batchCall{abi: gameABI, to: proven.DisputeGame, method: "maxClockDuration", out: &maxClockDuration, constant: true},
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error querying dispute game: %w", err)
+	}
+	if resolvedAt == 0 {
+		// unchallenged games resolve once the root claim's clock runs out
+		resolvedAt = createdAt + maxClockDuration
+	}
+
+	matured := proven.Timestamp + maturityDelay.Uint64()
+	if final := resolvedAt + finalityDelay.Uint64(); final > matured {
+		matured = final
+	}
+	return time.Unix(int64(matured), 0), nil
+}
@@ -0,0 +1,66 @@
+package withdraw
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrSpendCapReached is returned instead of sending a transaction whose fee could take the fees paid
+// during a run over the spending cap.
+var ErrSpendCapReached = errors.New("spending cap reached")
+
+// SpendCap tracks the fees paid across a run, refusing transactions whose maximum fee could exceed
+// the cap. It is safe for concurrent use.
+type SpendCap struct {
+	mu    sync.Mutex
+	limit *big.Int
+	// spent is the fees paid by confirmed transactions, reserved the maximum fees of sent ones.
+	spent    *big.Int
+	reserved *big.Int
+}
+
+// NewSpendCap creates a spending cap of limit wei.
+func NewSpendCap(limit *big.Int) *SpendCap {
+	return &SpendCap{limit: limit, spent: new(big.Int), reserved: new(big.Int)}
+}
+
+// Spent returns the fees paid so far.
+func (c *SpendCap) Spent() *big.Int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(c.spent)
+}
+
+// Reserve reserves the maximum fee of tx, or returns ErrSpendCapReached if it could exceed the cap.
+// The returned amount is passed to Release or Settle once tx failed to send or was confirmed.
+func (c *SpendCap) Reserve(tx *types.Transaction) (*big.Int, error) {
+	maxFee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := new(big.Int).Add(c.spent, c.reserved)
+	if total.Add(total, maxFee).Cmp(c.limit) > 0 {
+		return nil, fmt.Errorf("%w: paid %s wei of %s wei, and the tx could cost up to %s wei", ErrSpendCapReached, c.spent, c.limit, maxFee)
+	}
+	c.reserved.Add(c.reserved, maxFee)
+	return maxFee, nil
+}
+
+// Release releases a reservation of a transaction that wasn't sent.
+func (c *SpendCap) Release(reserved *big.Int) {
+	c.Settle(reserved, new(big.Int))
+}
+
+// Settle replaces a reservation, which may be nil for transactions sent by a previous run, with the
+// fee actually paid.
+func (c *SpendCap) Settle(reserved, fee *big.Int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if reserved != nil {
+		c.reserved.Sub(c.reserved, reserved)
+	}
+	c.spent.Add(c.spent, fee)
+}
@@ -0,0 +1,89 @@
+package withdraw
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSpendCap(t *testing.T) {
+	// each transaction could cost up to 100_000 wei
+	tx := types.NewTx(&types.DynamicFeeTx{Gas: 1_000, GasFeeCap: big.NewInt(100)})
+	type op struct {
+		// settle, if set, is the fee the previous reservation is settled with, instead of reserving.
+		settle  *big.Int
+		release bool
+		wantErr error
+	}
+	tests := []struct {
+		name      string
+		limit     int64
+		ops       []op
+		wantSpent int64
+	}{
+		{
+			name:      "within the cap",
+			limit:     250_000,
+			ops:       []op{{}, {settle: big.NewInt(40_000)}, {}, {settle: big.NewInt(60_000)}},
+			wantSpent: 100_000,
+		},
+		{
+			name:      "reservations count against the cap",
+			limit:     150_000,
+			ops:       []op{{}, {wantErr: ErrSpendCapReached}},
+			wantSpent: 0,
+		},
+		{
+			name:      "paid fees count against the cap",
+			limit:     150_000,
+			ops:       []op{{}, {settle: big.NewInt(60_000)}, {wantErr: ErrSpendCapReached}},
+			wantSpent: 60_000,
+		},
+		{
+			name:      "released reservations free the cap",
+			limit:     150_000,
+			ops:       []op{{}, {release: true}, {}, {settle: big.NewInt(1_000)}},
+			wantSpent: 1_000,
+		},
+		{
+			name:      "transaction exceeding the cap",
+			limit:     50_000,
+			ops:       []op{{wantErr: ErrSpendCapReached}},
+			wantSpent: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSpendCap(big.NewInt(tt.limit))
+			var reserved *big.Int
+			for i, o := range tt.ops {
+				switch {
+				case o.settle != nil:
+					c.Settle(reserved, o.settle)
+				case o.release:
+					c.Release(reserved)
+				default:
+					var err error
+					reserved, err = c.Reserve(tx)
+					if !errors.Is(err, o.wantErr) {
+						t.Fatalf("op %d: Reserve() error = %v, want %v", i, err, o.wantErr)
+					}
+				}
+			}
+			if spent := c.Spent(); spent.Cmp(big.NewInt(tt.wantSpent)) != 0 {
+				t.Fatalf("Spent() = %s, want %d", spent, tt.wantSpent)
+			}
+		})
+	}
+}
+
+func TestSpendCapSettleUnreserved(t *testing.T) {
+	// transactions sent by a previous run are settled without a reservation
+	c := NewSpendCap(big.NewInt(100))
+	c.Settle(nil, big.NewInt(30))
+	if spent := c.Spent(); spent.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("Spent() = %s, want 30", spent)
+	}
+}
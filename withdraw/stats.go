@@ -0,0 +1,219 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/packages/contracts-bedrock/snapshots"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// statsBatchSize bounds the number of calls batched per request when loading proposal timings, as RPCs
+// limit the size of batches.
+const statsBatchSize = 50
+
+// ProposalTiming is the timing of an output proposal or dispute game, which makes the withdrawals
+// initiated in the L2 blocks it covers, the ones after the previous proposal, provable.
+type ProposalTiming struct {
+	L2Block uint64
+	// Blocks is the number of L2 blocks covered, and FirstL2Time and LastL2Time the timestamps of the
+	// first and last of them.
+	Blocks                  uint64
+	FirstL2Time, LastL2Time uint64
+	// ProvableAt is when the withdrawals in the covered blocks became provable, the time of this or a
+	// later proposal covering them, whichever was first.
+	ProvableAt uint64
+	// FinalizableAfter is how long after ProvableAt the withdrawals, if proven right away, became
+	// finalizable, or 0 if unknown, for dispute games that haven't resolved yet.
+	FinalizableAfter time.Duration
+}
+
+// RecentOutputTimings returns the timings of the last count output proposals of the L2OutputOracle at
+// oracleAddress, oldest first.
+func RecentOutputTimings(ctx context.Context, l1 L1Client, oracleAddress common.Address, count int) ([]ProposalTiming, error) {
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	var latest, period *big.Int
+	err = callBatch(ctx, l1,
+		batchCall{abi: oracleABI, to: oracleAddress, method: "latestOutputIndex", out: &latest},
+		batchCall{abi: oracleABI, to: oracleAddress, method: "FINALIZATION_PERIOD_SECONDS", out: &period, constant: true},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying output proposals: %w", err)
+	}
+
+	// the output before the first one analyzed is needed for the blocks the first one covers
+	first := latest.Int64() - int64(count)
+	if first < 0 {
+		first = 0
+	}
+	n := int(latest.Int64()-first) + 1
+	outputs := make([]bindings.TypesOutputProposal, n)
+	l2Times := make([]*big.Int, n)
+	for start := 0; start < n; start += statsBatchSize / 2 {
+		var calls []batchCall
+		for i := start; i < n && i < start+statsBatchSize/2; i++ {
+			calls = append(calls, batchCall{abi: oracleABI, to: oracleAddress, method: "getL2Output", args: []interface{}{big.NewInt(first + int64(i))}, out: &outputs[i]})
+		}
+		if err := callBatch(ctx, l1, calls...); err != nil {
+			return nil, fmt.Errorf("error querying outputs: %w", err)
+		}
+		calls = nil
+		for i := start; i < n && i < start+statsBatchSize/2; i++ {
+			calls = append(calls, batchCall{abi: oracleABI, to: oracleAddress, method: "computeL2Timestamp", args: []interface{}{outputs[i].L2BlockNumber}, out: &l2Times[i]})
+		}
+		if err := callBatch(ctx, l1, calls...); err != nil {
+			return nil, fmt.Errorf("error computing L2 timestamps: %w", err)
+		}
+	}
+
+	proposals := make([]proposal, n)
+	for i, o := range outputs {
+		proposals[i] = proposal{
+			l2Block:          o.L2BlockNumber.Uint64(),
+			l2Time:           l2Times[i].Uint64(),
+			proposedAt:       o.Timestamp.Uint64(),
+			finalizableAfter: time.Duration(period.Uint64()) * time.Second,
+		}
+	}
+	return timings(proposals), nil
+}
+
+// RecentGameTimings returns the timings of the last count dispute games of the respected game type
+// created by the DisputeGameFactory at factoryAddress, oldest first, skipping games the challenger won.
+// At most 10 times count games are looked at, in case other game types are created too.
+func RecentGameTimings(ctx context.Context, l1 L1Client, l2 ethereum.ChainReader, factoryAddress, portalAddress common.Address, count int) ([]ProposalTiming, error) {
+	factoryABI, err := bindings.DisputeGameFactoryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	portalABI, err := bindingspreview.OptimismPortal2MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	gameABI := snapshots.LoadFaultDisputeGameABI()
+	var gameCount, maturityDelay, finalityDelay *big.Int
+	var respected uint32
+	err = callBatch(ctx, l1,
+		batchCall{abi: factoryABI, to: factoryAddress, method: "gameCount", out: &gameCount},
+		batchCall{abi: portalABI, to: portalAddress, method: "respectedGameType", out: &respected},
+		batchCall{abi: portalABI, to: portalAddress, method: "proofMaturityDelaySeconds", out: &maturityDelay, constant: true},
+		batchCall{abi: portalABI, to: portalAddress, method: "disputeGameFinalityDelaySeconds", out: &finalityDelay, constant: true},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dispute games: %w", err)
+	}
+
+	type game struct {
+		GameType  uint32
+		Timestamp uint64
+		Proxy     common.Address
+	}
+	// the game before the first one analyzed is needed for the blocks the first one covers
+	var games []game
+	for next, scanned := gameCount.Int64()-1, 0; next >= 0 && len(games) <= count && scanned < 10*count; {
+		batch := make([]game, 0, statsBatchSize)
+		var calls []batchCall
+		for ; next >= 0 && len(calls) < statsBatchSize; next-- {
+			batch = append(batch, game{})
+			calls = append(calls, batchCall{abi: factoryABI, to: factoryAddress, method: "gameAtIndex", args: []interface{}{big.NewInt(next)}, out: &batch[len(batch)-1]})
+		}
+		if err := callBatch(ctx, l1, calls...); err != nil {
+			return nil, fmt.Errorf("error querying dispute games: %w", err)
+		}
+		for _, g := range batch {
+			if g.GameType == respected && len(games) <= count {
+				games = append(games, g)
+			}
+		}
+		scanned += len(batch)
+	}
+
+	var proposals []proposal
+	for start := 0; start < len(games); start += statsBatchSize / 3 {
+		end := start + statsBatchSize/3
+		if end > len(games) {
+			end = len(games)
+		}
+		blocks := make([]*big.Int, end-start)
+		resolvedAt := make([]uint64, end-start)
+		status := make([]uint8, end-start)
+		var calls []batchCall
+		for i, g := range games[start:end] {
+			calls = append(calls,
+				batchCall{abi: gameABI, to: g.Proxy, method: "l2BlockNumber", out: &blocks[i]},
+				batchCall{abi: gameABI, to: g.Proxy, method: "resolvedAt", out: &resolvedAt[i]},
+				batchCall{abi: gameABI, to: g.Proxy, method: "status", out: &status[i]},
+			)
+		}
+		if err := callBatch(ctx, l1, calls...); err != nil {
+			return nil, fmt.Errorf("error querying dispute games: %w", err)
+		}
+		for i, g := range games[start:end] {
+			if GameStatus(status[i]) == GameChallengerWins {
+				continue
+			}
+			p := proposal{l2Block: blocks[i].Uint64(), proposedAt: g.Timestamp}
+			if GameStatus(status[i]) == GameDefenderWins {
+				// withdrawals proven right away mature with the proof, or once the game's finality delay passed
+				p.finalizableAfter = time.Duration(maturityDelay.Uint64()) * time.Second
+				if final := time.Duration(resolvedAt[i]+finalityDelay.Uint64()-g.Timestamp) * time.Second; final > p.finalizableAfter {
+					p.finalizableAfter = final
+				}
+			}
+			header, err := l2.HeaderByNumber(ctx, blocks[i])
+			if err != nil {
+				return nil, fmt.Errorf("error querying L2 block %d: %w", blocks[i], err)
+			}
+			p.l2Time = header.Time
+			proposals = append(proposals, p)
+		}
+	}
+	return timings(proposals), nil
+}
+
+// proposal is an output proposal or dispute game.
+type proposal struct {
+	l2Block, l2Time  uint64
+	proposedAt       uint64
+	finalizableAfter time.Duration
+}
+
+// timings returns the timings of proposals, oldest first, the first one only serving as the start of
+// the blocks covered by the second.
+func timings(proposals []proposal) []ProposalTiming {
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].l2Block < proposals[j].l2Block })
+	var result []ProposalTiming
+	for i := len(proposals) - 1; i > 0; i-- {
+		p, prev := proposals[i], proposals[i-1]
+		if p.l2Block == prev.l2Block {
+			continue
+		}
+		t := ProposalTiming{
+			L2Block:          p.l2Block,
+			Blocks:           p.l2Block - prev.l2Block,
+			FirstL2Time:      prev.l2Time + (p.l2Time-prev.l2Time)/(p.l2Block-prev.l2Block),
+			LastL2Time:       p.l2Time,
+			ProvableAt:       p.proposedAt,
+			FinalizableAfter: p.finalizableAfter,
+		}
+		// blocks are provable with the first proposal covering them, which may be a later one
+		if len(result) > 0 && result[len(result)-1].ProvableAt < t.ProvableAt {
+			t.ProvableAt = result[len(result)-1].ProvableAt
+			t.FinalizableAfter = result[len(result)-1].FinalizableAfter
+		}
+		result = append(result, t)
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
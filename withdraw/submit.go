@@ -0,0 +1,350 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/op-service/txmgr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/base-org/withdrawer/price"
+)
+
+// ErrBaseFeeTooHigh is returned instead of sending a transaction while the L1 base fee exceeds the
+// configured maximum.
+var ErrBaseFeeTooHigh = errors.New("L1 base fee too high")
+
+// ErrInsufficientFunds is returned instead of sending a transaction the signer's L1 balance can't pay for.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// l1BlockTime and l1EpochTime are the L1 slot and epoch times, used to extend the confirmation timeout
+// by the time waited for extra confirmations or the safe or finalized tag.
+const (
+	l1BlockTime = 12 * time.Second
+	l1EpochTime = 32 * l1BlockTime
+)
+
+// TxSettings configures how the withdraw helpers send prove and finalize transactions.
+type TxSettings struct {
+	// Journal, if set, records sent transactions.
+	Journal Journal
+	// PollInterval is the initial interval between checks for transaction confirmation, 5 seconds if unset.
+	// Polls back off exponentially up to 30 seconds, or PollInterval if longer.
+	PollInterval time.Duration
+	// ConfirmTimeout is how long a sent transaction is waited for, 5 minutes if unset. It is extended by
+	// the time needed for the Confirmations, ReorgCheckBlocks and ConfirmTag to be reached.
+	ConfirmTimeout time.Duration
+	// TxManager, if set, sends the transactions built with the helper's Opts (which must then not
+	// send them itself), bumping their fees until they are confirmed.
+	TxManager txmgr.TxManager
+	// MaxBaseFee, if set, is the L1 base fee above which no transactions are sent.
+	MaxBaseFee *big.Int
+	// GasBuffer, if above 1, multiplies the gas estimates of the transactions the helpers bind
+	// themselves. Bindings passed to the helpers should be created with WithGasBuffer instead.
+	GasBuffer float64
+	// PriceFeed, if set, is used to also report the fees paid in USD.
+	PriceFeed price.Feed
+	// SpendCap, if set, caps the fees paid across all helpers sharing it.
+	SpendCap *SpendCap
+	// PrivateRelay, if set, is sent transactions with eth_sendPrivateTransaction. Transactions that
+	// aren't included within PrivateFallbackBlocks (10 if unset) blocks are sent publicly.
+	PrivateRelay          *rpc.Client
+	PrivateFallbackBlocks uint64
+	// NoWait returns right after sending transactions, without a receipt, leaving it to the caller to
+	// track their confirmation.
+	NoWait bool
+	// Confirmations is the number of blocks that must follow the block including a transaction before
+	// it is considered confirmed, guarding against shallow L1 reorgs.
+	Confirmations uint64
+	// ConfirmTag, if set to rpc.SafeBlockNumber or rpc.FinalizedBlockNumber, additionally requires
+	// the block including a transaction to be at or below the block with that tag.
+	ConfirmTag rpc.BlockNumber
+	// ReorgCheckBlocks, if set, is the number of blocks after which a confirmed transaction is checked
+	// to still be canonical, and waited for again or resubmitted if it was reorged out.
+	ReorgCheckBlocks uint64
+	// L2BlockTag, if set to rpc.SafeBlockNumber or rpc.FinalizedBlockNumber, requires the L2 block
+	// including the withdrawal to be at or below the L2 block with that tag before it is provable, so
+	// that no proof is generated for a withdrawal that could still be reorged out on L2.
+	L2BlockTag rpc.BlockNumber
+	// Logger, if set, receives the progress messages, which are printed to stdout otherwise.
+	Logger Logger
+	// Progress, if set, is sent the progress of the actions. Events are dropped while the channel is
+	// full, so it should be buffered.
+	Progress chan<- ProgressEvent
+	// Hooks, if set, are called as transactions are sent and confirmed.
+	Hooks *Hooks
+	// Nonces, if set, assigns the nonces of transactions sent concurrently with other helpers sharing it,
+	// overriding the nonce in the helper's Opts. It is not used with a TxManager.
+	Nonces *NonceManager
+	// L1Explorer, if set, is the base URL of the L1 block explorer (e.g. https://etherscan.io) linked
+	// to in the messages reporting sent transactions.
+	L1Explorer string
+	// BuildOnly, if set, is passed the transaction built for each action, unsigned, instead of it being
+	// sent, e.g. to hand it to other tooling. The action then returns the hash of the unsent transaction.
+	BuildOnly func(l2TxHash common.Hash, action string, tx *types.Transaction)
+}
+
+// submitMessages are printed once a transaction for the action was sent.
+var submitMessages = map[string]string{
+	"prove":    "Proved withdrawal for %s: %s\n",
+	"finalize": "Completed withdrawal for %s: %s\n",
+}
+
+// txRef returns the hash of an L1 transaction, followed by its explorer URL if there is an L1Explorer.
+func (s TxSettings) txRef(hash common.Hash) string {
+	if s.L1Explorer == "" {
+		return hash.String()
+	}
+	return fmt.Sprintf("%s (%s/tx/%s)", hash.String(), strings.TrimSuffix(s.L1Explorer, "/"), hash.String())
+}
+
+// submit sends the transaction for action, created by send with opts, and waits up to ConfirmTimeout for
+// it to be confirmed. If the journal records a transaction for the action that is still pending, it waits
+// for that transaction instead of sending a new one. With a tx manager, send only builds the transaction,
+// which the tx manager then sends and resubmits with bumped fees until it is confirmed. Nothing is sent
+// while the L1 base fee exceeds MaxBaseFee, if the signer can't pay for the transaction, or if it could
+// exceed the SpendCap. With a
+// private relay, the transaction is only sent publicly if the relay doesn't get it included. With
+// BuildOnly, it is only built.
+func (s TxSettings) submit(ctx context.Context, client L1Client, opts *bind.TransactOpts, l2TxHash common.Hash, action string, send func(opts *bind.TransactOpts) (*types.Transaction, error)) (common.Hash, *types.Receipt, error) {
+	if s.BuildOnly != nil {
+		buildOpts := *opts
+		buildOpts.Context, buildOpts.NoSend = ctx, true
+		buildOpts.Signer = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		}
+		tx, err := send(&buildOpts)
+		if err != nil {
+			return common.Hash{}, nil, decodeRevert(err)
+		}
+		s.BuildOnly(l2TxHash, action, tx)
+		return tx.Hash(), nil, nil
+	}
+	txHash, receipt, err := s.sendAndConfirm(ctx, client, opts, l2TxHash, action, send)
+	s.Hooks.done(l2TxHash, action, receipt, err)
+	return txHash, receipt, err
+}
+
+// sendAndConfirm implements submit, apart from calling the hooks for its outcome.
+func (s TxSettings) sendAndConfirm(ctx context.Context, client L1Client, opts *bind.TransactOpts, l2TxHash common.Hash, action string, send func(opts *bind.TransactOpts) (*types.Transaction, error)) (common.Hash, *types.Receipt, error) {
+	// Wait 5 mins max for confirmation by default, plus the time for the extra confirmations to be mined
+	// and the block to become safe or finalized
+	timeout := s.ConfirmTimeout
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+	timeout += time.Duration(s.Confirmations+s.ReorgCheckBlocks) * l1BlockTime
+	switch s.ConfirmTag {
+	case rpc.SafeBlockNumber:
+		timeout += 2 * l1EpochTime
+	case rpc.FinalizedBlockNumber:
+		timeout += 3 * l1EpochTime
+	}
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// the bindings estimate gas and send under the context of opts
+	callOpts := *opts
+	callOpts.Context = ctx
+	opts = &callOpts
+
+	if s.Journal != nil {
+		pending, err := s.Journal.PendingTx(l2TxHash, action)
+		if err != nil {
+			return common.Hash{}, nil, fmt.Errorf("error reading journal: %w", err)
+		}
+		if pending != (common.Hash{}) {
+			_, isPending, err := client.TransactionByHash(ctx, pending)
+			if err != nil && !errors.Is(err, ethereum.NotFound) {
+				return common.Hash{}, nil, fmt.Errorf("error querying previously sent tx %s: %w", pending, err)
+			}
+			// mined transactions either succeeded, in which case we wouldn't get here, or need to be sent again,
+			// as do transactions dropped from the mempool
+			if err == nil && isPending {
+				if s.NoWait {
+					s.logger().Printf("%s tx %s sent by a previous run is still pending\n", action, pending.String())
+					return pending, nil, nil
+				}
+				s.logger().Printf("Resuming %s tx %s sent by a previous run\n", action, pending.String())
+				receipt, err := s.waitForConfirmation(ctxWithTimeout, client, l2TxHash, action, pending)
+				if err == nil && s.ReorgCheckBlocks > 0 {
+					receipt, err = s.awaitCanonical(ctxWithTimeout, client, l2TxHash, action, pending, nil, receipt)
+				}
+				if err == nil {
+					s.reportFee(ctx, action, receipt, nil)
+				}
+				return pending, receipt, err
+			}
+		}
+	}
+
+	if err := s.checkBaseFee(ctx, client); err != nil {
+		return common.Hash{}, nil, err
+	}
+
+	buildOpts := opts
+	if !opts.NoSend {
+		// build the transaction first, to check the signer can pay for it and its maximum fee against the
+		// cap, and to send it privately or with a coordinated nonce
+		noSend := *opts
+		noSend.NoSend = true
+		buildOpts = &noSend
+	}
+	releaseNonce := func(bool) {}
+	if s.Nonces != nil && buildOpts != opts {
+		nonce, release, err := s.Nonces.acquire(ctx)
+		if err != nil {
+			return common.Hash{}, nil, err
+		}
+		buildOpts.Nonce, releaseNonce = new(big.Int).SetUint64(nonce), release
+	}
+	tx, err := send(buildOpts)
+	if err != nil {
+		releaseNonce(false)
+		return common.Hash{}, nil, decodeRevert(err)
+	}
+	if err := checkBalance(ctx, client, opts.From, tx); err != nil {
+		releaseNonce(false)
+		return common.Hash{}, nil, err
+	}
+	var reserved *big.Int
+	if s.SpendCap != nil {
+		if reserved, err = s.SpendCap.Reserve(tx); err != nil {
+			releaseNonce(false)
+			return common.Hash{}, nil, err
+		}
+	}
+	if s.TxManager != nil {
+		return s.sendWithTxManager(ctx, l2TxHash, action, tx, reserved)
+	}
+	var privateDeadline uint64
+	if buildOpts != opts {
+		if s.PrivateRelay != nil {
+			privateDeadline, err = s.sendPrivate(ctx, client, tx)
+		} else {
+			err = client.SendTransaction(ctx, tx)
+		}
+		releaseNonce(err == nil)
+		if err != nil {
+			if s.SpendCap != nil {
+				s.SpendCap.Release(reserved)
+			}
+			return common.Hash{}, nil, decodeRevert(err)
+		}
+	}
+	if s.Journal != nil {
+		if err := s.Journal.RecordTx(l2TxHash, action, tx.Hash()); err != nil {
+			s.logger().Printf("Failed to record %s tx %s in the journal: %v\n", action, tx.Hash().String(), err)
+		}
+	}
+
+	s.report(ProgressEvent{L2TxHash: l2TxHash, Action: action, Stage: StageSubmitted, Tx: tx.Hash()}, submitMessages[action], l2TxHash.String(), s.txRef(tx.Hash()))
+	s.Hooks.txSubmitted(l2TxHash, action, tx.Hash())
+	if s.NoWait {
+		return tx.Hash(), nil, nil
+	}
+
+	var receipt *types.Receipt
+	if privateDeadline != 0 {
+		err = s.awaitPrivateInclusion(ctxWithTimeout, client, tx, privateDeadline)
+	}
+	if err == nil {
+		receipt, err = s.waitForConfirmation(ctxWithTimeout, client, l2TxHash, action, tx.Hash())
+	}
+	if err == nil && s.ReorgCheckBlocks > 0 {
+		receipt, err = s.awaitCanonical(ctxWithTimeout, client, l2TxHash, action, tx.Hash(), tx, receipt)
+	}
+	if errors.Is(err, context.Canceled) {
+		s.logger().Printf("Interrupted, %s tx %s is still pending and will be resumed by the next run\n", action, tx.Hash().String())
+	} else if err == nil {
+		s.reportFee(ctx, action, receipt, reserved)
+	}
+	return tx.Hash(), receipt, err
+}
+
+// sendWithTxManager sends the unsent transaction tx with the tx manager, which picks its nonce and
+// fees. As the tx manager may replace the transaction, only the confirmed one is recorded. The
+// reservation of the spending cap, if any, is based on the fees of tx, which the tx manager may bump.
+func (s TxSettings) sendWithTxManager(ctx context.Context, l2TxHash common.Hash, action string, tx *types.Transaction, reserved *big.Int) (common.Hash, *types.Receipt, error) {
+	s.report(ProgressEvent{L2TxHash: l2TxHash, Action: action, Stage: StageSubmitting}, "Sending %s tx for %s\n", action, l2TxHash.String())
+	receipt, err := s.TxManager.Send(ctx, txmgr.TxCandidate{
+		TxData:   tx.Data(),
+		To:       tx.To(),
+		GasLimit: tx.Gas(),
+		Value:    tx.Value(),
+	})
+	if err != nil {
+		return common.Hash{}, nil, decodeRevert(err)
+	}
+	if s.Journal != nil {
+		if err := s.Journal.RecordTx(l2TxHash, action, receipt.TxHash); err != nil {
+			s.logger().Printf("Failed to record %s tx %s in the journal: %v\n", action, receipt.TxHash.String(), err)
+		}
+	}
+	s.report(ProgressEvent{L2TxHash: l2TxHash, Action: action, Stage: StageSubmitted, Tx: receipt.TxHash}, submitMessages[action], l2TxHash.String(), s.txRef(receipt.TxHash))
+	s.Hooks.txSubmitted(l2TxHash, action, receipt.TxHash)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt.TxHash, nil, errors.New("unsuccessful withdrawal receipt status")
+	}
+	s.reportFee(ctx, action, receipt, reserved)
+	return receipt.TxHash, receipt, nil
+}
+
+// reportFee prints the fee paid for the confirmed transaction of action, and settles its reservation
+// of the spending cap.
+func (s TxSettings) reportFee(ctx context.Context, action string, receipt *types.Receipt, reserved *big.Int) {
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if s.SpendCap != nil {
+		s.SpendCap.Settle(reserved, fee)
+	}
+	s.logger().Printf("Paid %s in fees for the %s tx\n", price.FormatETH(ctx, s.PriceFeed, fee), action)
+}
+
+// balanceReader is implemented by L1 clients that can query balances, such as *ethclient.Client.
+type balanceReader interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// checkBalance returns ErrInsufficientFunds if the balance of from can't pay for the gas limit of tx at its
+// maximum fee, plus its value, as the L1 node would reject it. Clients that can't query balances skip the check.
+func checkBalance(ctx context.Context, client L1Client, from common.Address, tx *types.Transaction) error {
+	reader, ok := client.(balanceReader)
+	if !ok {
+		return nil
+	}
+	balance, err := reader.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return fmt.Errorf("error querying balance of %s: %w", from, err)
+	}
+	if cost := tx.Cost(); balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w: %s needs ~%s for the tx, but has %s", ErrInsufficientFunds, from,
+			price.FormatETH(ctx, nil, cost), price.FormatETH(ctx, nil, balance))
+	}
+	return nil
+}
+
+// checkBaseFee returns ErrBaseFeeTooHigh if the current L1 base fee exceeds MaxBaseFee.
+func (s TxSettings) checkBaseFee(ctx context.Context, client L1Client) error {
+	if s.MaxBaseFee == nil {
+		return nil
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error querying L1 head: %w", err)
+	}
+	if head.BaseFee != nil && head.BaseFee.Cmp(s.MaxBaseFee) > 0 {
+		return fmt.Errorf("%w: %s wei exceeds maximum of %s wei", ErrBaseFeeTooHigh, head.BaseFee, s.MaxBaseFee)
+	}
+	return nil
+}
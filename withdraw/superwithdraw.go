@@ -0,0 +1,293 @@
+package withdraw
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	bindingspreview "github.com/ethereum-optimism/optimism/op-node/bindings/preview"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// superRootPortalABI is the interop OptimismPortal proveWithdrawalTransaction overload, which proves
+// against a super root (committing to the output roots of every chain in the dependency set) instead
+// of a single output root. All other portal methods are unchanged from OptimismPortal2.
+const superRootPortalABI = `[{"type":"function","name":"proveWithdrawalTransaction","stateMutability":"nonpayable","outputs":[],"inputs":[
+	{"name":"_tx","type":"tuple","components":[{"name":"nonce","type":"uint256"},{"name":"sender","type":"address"},{"name":"target","type":"address"},{"name":"value","type":"uint256"},{"name":"gasLimit","type":"uint256"},{"name":"data","type":"bytes"}]},
+	{"name":"_disputeGameProxy","type":"address"},
+	{"name":"_outputRootIndex","type":"uint256"},
+	{"name":"_superRootProof","type":"tuple","components":[{"name":"version","type":"bytes1"},{"name":"timestamp","type":"uint64"},{"name":"outputRoots","type":"tuple[]","components":[{"name":"chainId","type":"uint256"},{"name":"root","type":"bytes32"}]}]},
+	{"name":"_outputRootProof","type":"tuple","components":[{"name":"version","type":"bytes32"},{"name":"stateRoot","type":"bytes32"},{"name":"messagePasserStorageRoot","type":"bytes32"},{"name":"latestBlockhash","type":"bytes32"}]},
+	{"name":"_withdrawalProof","type":"bytes[]"}]}]`
+
+type superRootProof struct {
+	Version     [1]byte
+	Timestamp   uint64
+	OutputRoots []outputRootWithChainID
+}
+
+type outputRootWithChainID struct {
+	ChainId *big.Int
+	Root    [32]byte
+}
+
+// superRootResponse is the subset of the op-supervisor supervisor_superRootAtTimestamp response we use.
+type superRootResponse struct {
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+	SuperRoot common.Hash    `json:"superRoot"`
+	Version   hexutil.Bytes  `json:"version"`
+	Chains    []struct {
+		ChainID   *hexutil.Big `json:"chainID"`
+		Canonical common.Hash  `json:"canonical"`
+	} `json:"chains"`
+}
+
+type SuperRootWithdrawer struct {
+	L1Client      L1Client
+	L2Client      L2Client
+	Supervisor    SupervisorClient
+	L2TxHash      common.Hash
+	PortalAddress common.Address
+	Portal        *bindingspreview.OptimismPortal2
+	Factory       *bindings.DisputeGameFactory
+	// CrossChecker, if set, checks the data transactions rely on against a second L1 provider.
+	CrossChecker *CrossChecker
+	// Rollup, if set, is the rollup node output roots are checked against before proving.
+	Rollup RollupClient
+	Opts   *bind.TransactOpts
+	TxSettings
+
+	receipt l2Receipt
+}
+
+func (w *SuperRootWithdrawer) CheckIfProvable(ctx context.Context) error {
+	l2 := w.L2Client
+	l2WithdrawalBlock, err := w.receipt.block(ctx, w.L2Client, w.L2TxHash)
+	if err != nil {
+		return fmt.Errorf("error querying withdrawal tx block: %w", err)
+	}
+	if err := w.checkL2BlockTag(ctx, w.L2Client, l2WithdrawalBlock); err != nil {
+		return err
+	}
+	header, err := l2.HeaderByNumber(ctx, l2WithdrawalBlock)
+	if err != nil {
+		return fmt.Errorf("error querying withdrawal block header: %w", err)
+	}
+
+	latestGame, err := withdrawals.FindLatestGame(ctx, &w.Factory.DisputeGameFactoryCaller, &w.Portal.OptimismPortal2Caller)
+	if err != nil {
+		return fmt.Errorf("failed to find latest game: %w", err)
+	}
+	gameTimestamp, err := superRootTimestamp(latestGame)
+	if err != nil {
+		return err
+	}
+
+	if gameTimestamp < header.Time {
+		return fmt.Errorf("%w: the latest super root proposed in the DisputeGameFactory is at timestamp %d and is not past timestamp %d of the L2 block that includes the withdrawal",
+			ErrNotProvableYet, gameTimestamp, header.Time)
+	}
+	return nil
+}
+
+// adapter returns the adapter of Portal, whose methods other than proveWithdrawalTransaction are
+// unchanged from OptimismPortal2.
+func (w *SuperRootWithdrawer) adapter() PortalAdapter {
+	return &optimismPortal2Adapter{portal: w.Portal}
+}
+
+// superRootTimestamp returns the timestamp the super root claimed by game is at, which super root games
+// commit to in their extra data rather than an L2 block number.
+func superRootTimestamp(game *bindings.IDisputeGameFactoryGameSearchResult) (uint64, error) {
+	if len(game.ExtraData) < 32 {
+		return 0, fmt.Errorf("dispute game %d has %d bytes of extra data, expected at least 32", game.Index, len(game.ExtraData))
+	}
+	return new(big.Int).SetBytes(game.ExtraData[0:32]).Uint64(), nil
+}
+
+func (w *SuperRootWithdrawer) WithdrawalHash(ctx context.Context) (common.Hash, error) {
+	return w.receipt.withdrawalHash(ctx, w.L2Client, w.L2TxHash)
+}
+
+func (w *SuperRootWithdrawer) GetProvenWithdrawalTime(ctx context.Context) (uint64, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	provenWithdrawal, err := w.Portal.ProvenWithdrawals(&bind.CallOpts{Context: ctx}, hash, w.Opts.From)
+	if err != nil {
+		return 0, err
+	}
+
+	return provenWithdrawal.Timestamp, nil
+}
+
+func (w *SuperRootWithdrawer) ProveWithdrawal(ctx context.Context) (common.Hash, error) {
+	l2 := w.L2Client
+
+	chainID, err := l2.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error querying L2 chain ID: %w", err)
+	}
+
+	latestGame, err := withdrawals.FindLatestGame(ctx, &w.Factory.DisputeGameFactoryCaller, &w.Portal.OptimismPortal2Caller)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to find latest game: %w", err)
+	}
+	timestamp, err := superRootTimestamp(latestGame)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	var superRoot superRootResponse
+	if err := w.Supervisor.CallContext(ctx, &superRoot, "supervisor_superRootAtTimestamp", hexutil.Uint64(timestamp)); err != nil {
+		return common.Hash{}, fmt.Errorf("error querying super root at timestamp %d: %w", timestamp, err)
+	}
+	if superRoot.SuperRoot != common.Hash(latestGame.RootClaim) {
+		return common.Hash{}, fmt.Errorf("supervisor super root %s does not match the root claim %s of dispute game %d", superRoot.SuperRoot, common.Hash(latestGame.RootClaim), latestGame.Index)
+	}
+
+	proof := superRootProof{Timestamp: uint64(superRoot.Timestamp)}
+	if len(superRoot.Version) > 0 {
+		proof.Version[0] = superRoot.Version[0]
+	}
+	outputRootIndex := -1
+	for i, chain := range superRoot.Chains {
+		proof.OutputRoots = append(proof.OutputRoots, outputRootWithChainID{ChainId: chain.ChainID.ToInt(), Root: chain.Canonical})
+		if chain.ChainID.ToInt().Cmp(chainID) == 0 {
+			outputRootIndex = i
+		}
+	}
+	if outputRootIndex < 0 {
+		return common.Hash{}, fmt.Errorf("L2 chain %d is not part of the super root at timestamp %d", chainID, timestamp)
+	}
+
+	l2BlockNumber, err := blockNumberAtTimestamp(ctx, l2, timestamp)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	w.report(ProgressEvent{L2TxHash: w.L2TxHash, Action: "prove", Stage: StageGeneratingProof}, "Generating proof for %s\n", w.L2TxHash.String())
+
+	params, err := withdrawals.ProveWithdrawalParametersForBlock(ctx, l2, w.receipt.client(w.L2Client, w.L2TxHash), l2, w.L2TxHash, l2BlockNumber, latestGame.Index)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if err := verifyWithdrawalProof(params); err != nil {
+		return common.Hash{}, err
+	}
+	if root := outputRoot(params.OutputRootProof); root != superRoot.Chains[outputRootIndex].Canonical {
+		return common.Hash{}, fmt.Errorf("computed output root %s for L2 block %d does not match the super root entry %s", root, l2BlockNumber, superRoot.Chains[outputRootIndex].Canonical)
+	}
+	if err := w.CrossChecker.checkGame(ctx, latestGame.Index, common.Hash(latestGame.RootClaim)); err != nil {
+		return common.Hash{}, err
+	}
+	// the root claim is a super root, so the output root of the chain within it is checked
+	source := fmt.Sprintf("the super root at timestamp %d", timestamp)
+	if err := checkRollupOutput(ctx, w.Rollup, l2BlockNumber.Uint64(), superRoot.Chains[outputRootIndex].Canonical, source); err != nil {
+		return common.Hash{}, err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(superRootPortalABI))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	portal := bind.NewBoundContract(w.PortalAddress, parsed, w.L1Client, WithGasBuffer(w.L1Client, w.GasBuffer), w.L1Client)
+
+	game, err := w.Factory.GameAtIndex(&bind.CallOpts{Context: ctx}, latestGame.Index)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("error querying dispute game %d: %w", latestGame.Index, err)
+	}
+
+	// create the proof
+	txHash, _, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "prove", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return portal.Transact(
+			opts,
+			"proveWithdrawalTransaction",
+			bindingspreview.TypesWithdrawalTransaction{
+				Nonce:    params.Nonce,
+				Sender:   params.Sender,
+				Target:   params.Target,
+				Value:    params.Value,
+				GasLimit: params.GasLimit,
+				Data:     params.Data,
+			},
+			game.Proxy,
+			big.NewInt(int64(outputRootIndex)),
+			proof,
+			bindingspreview.TypesOutputRootProof{
+				Version:                  params.OutputRootProof.Version,
+				StateRoot:                params.OutputRootProof.StateRoot,
+				MessagePasserStorageRoot: params.OutputRootProof.MessagePasserStorageRoot,
+				LatestBlockhash:          params.OutputRootProof.LatestBlockhash,
+			},
+			params.WithdrawalProof,
+		)
+	})
+	return txHash, err
+}
+
+func (w *SuperRootWithdrawer) IsProofFinalized(ctx context.Context) (bool, error) {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return w.Portal.FinalizedWithdrawals(&bind.CallOpts{Context: ctx}, hash)
+}
+
+func (w *SuperRootWithdrawer) CheckIfFinalizable(ctx context.Context) error {
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return err
+	}
+
+	// finalization is unchanged from OptimismPortal2, so the regular bindings can be used
+	return decodeRevert(w.Portal.CheckWithdrawal(&bind.CallOpts{Context: ctx}, hash, w.Opts.From))
+}
+
+func (w *SuperRootWithdrawer) FinalizeWithdrawal(ctx context.Context) (common.Hash, error) {
+	if err := w.CheckIfFinalizable(ctx); err != nil {
+		return common.Hash{}, err
+	}
+
+	hash, err := w.WithdrawalHash(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := w.CrossChecker.checkProven(ctx, w.L1Client, hash, w.Opts.From); err != nil {
+		return common.Hash{}, err
+	}
+
+	ev, err := w.receipt.messagePassed(ctx, w.L2Client, w.L2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txHash, l1Receipt, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "finalize", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Portal.FinalizeWithdrawalTransaction(
+			opts,
+			bindingspreview.TypesWithdrawalTransaction{
+				Nonce:    ev.Nonce,
+				Sender:   ev.Sender,
+				Target:   ev.Target,
+				Value:    ev.Value,
+				GasLimit: ev.GasLimit,
+				Data:     ev.Data,
+			},
+		)
+	})
+	if err != nil {
+		return txHash, err
+	}
+	return txHash, w.verifyFinalization(l1Receipt, hash)
+}
@@ -7,52 +7,180 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
 type WithdrawHelper interface {
-	CheckIfProvable() error
-	GetProvenWithdrawalTime() (uint64, error)
-	ProveWithdrawal() error
-	IsProofFinalized() (bool, error)
-	FinalizeWithdrawal() error
+	WithdrawalHash(ctx context.Context) (common.Hash, error)
+	CheckIfProvable(ctx context.Context) error
+	GetProvenWithdrawalTime(ctx context.Context) (uint64, error)
+	ProveWithdrawal(ctx context.Context) (common.Hash, error)
+	IsProofFinalized(ctx context.Context) (bool, error)
+	CheckIfFinalizable(ctx context.Context) error
+	FinalizeWithdrawal(ctx context.Context) (common.Hash, error)
 }
 
-func txBlock(ctx context.Context, l2c *rpc.Client, l2TxHash common.Hash) (*big.Int, error) {
+// GetWithdrawalHash returns the hash of the withdrawal initiated by the L2 transaction l2TxHash.
+func GetWithdrawalHash(ctx context.Context, l2c *rpc.Client, l2TxHash common.Hash) (common.Hash, error) {
 	l2 := ethclient.NewClient(l2c)
-	// Figure out when our withdrawal was included
 	receipt, err := l2.TransactionReceipt(ctx, l2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	ev, err := withdrawals.ParseMessagePassed(receipt)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: %s emits no MessagePassed event", ErrNotWithdrawal, l2TxHash)
+	}
+
+	return withdrawals.WithdrawalHash(ev)
+}
+
+// checkL2BlockTag checks the L2 block block including the withdrawal to be at or below the L2BlockTag
+// block, if set.
+func (s TxSettings) checkL2BlockTag(ctx context.Context, l2 L2Client, block *big.Int) error {
+	if s.L2BlockTag == 0 {
+		return nil
+	}
+	tagged, err := l2.HeaderByNumber(ctx, big.NewInt(s.L2BlockTag.Int64()))
+	if err != nil {
+		return fmt.Errorf("error querying %s L2 block: %w", s.L2BlockTag, err)
+	}
+	if tagged.Number.Cmp(block) < 0 {
+		return fmt.Errorf("%w: L2 block %d that includes the withdrawal is not %s yet (at %d)", ErrNotProvableYet, block, s.L2BlockTag, tagged.Number)
+	}
+	return nil
+}
+
+// blockNumberAtTimestamp returns the number of the last L2 block at or before the given timestamp, binary
+// searching the block headers, as the block time may have changed over the life of the chain.
+func blockNumberAtTimestamp(ctx context.Context, l2 L2Client, timestamp uint64) (*big.Int, error) {
+	head, err := l2.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	if receipt.Status != types.ReceiptStatusSuccessful {
-		return nil, errors.New("unsuccessful withdrawal receipt status")
+	if head.Time < timestamp {
+		return nil, fmt.Errorf("L2 head %d (time %d) is older than timestamp %d", head.Number.Uint64(), head.Time, timestamp)
+	}
+	// the block is searched in [lo, hi], the time of blocks after hi being past timestamp
+	lo, hi := uint64(0), head.Number.Uint64()
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		header, err := l2.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return nil, fmt.Errorf("error querying L2 block %d: %w", mid, err)
+		}
+		if header.Time <= timestamp {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo == 0 {
+		genesis, err := l2.HeaderByNumber(ctx, new(big.Int))
+		if err != nil {
+			return nil, fmt.Errorf("error querying L2 genesis block: %w", err)
+		}
+		if genesis.Time > timestamp {
+			return nil, fmt.Errorf("timestamp %d is before the L2 genesis block (time %d)", timestamp, genesis.Time)
+		}
 	}
-	return receipt.BlockNumber, nil
+	return new(big.Int).SetUint64(lo), nil
 }
 
-func waitForConfirmation(ctx context.Context, client *ethclient.Client, tx common.Hash) error {
+// defaultPollInterval is the initial interval between checks for transaction confirmation if none is
+// configured.
+const defaultPollInterval = 5 * time.Second
+
+// waitForConfirmation waits for tx to be mined and followed by Confirmations blocks, and for its block to
+// be at or below the ConfirmTag block, if set. The receipt is queried again on every poll, or every new
+// block over WebSocket, so a transaction reorged out while waiting is waited for again. The progress is
+// reported for the action of the withdrawal initiated in l2TxHash.
+func (s TxSettings) waitForConfirmation(ctx context.Context, client L1Client, l2TxHash common.Hash, action string, tx common.Hash) (*types.Receipt, error) {
+	ev := ProgressEvent{L2TxHash: l2TxHash, Action: action, Tx: tx}
+	waiter := newHeadWaiter(ctx, client, s.PollInterval)
+	defer waiter.close()
 	for {
 		receipt, err := client.TransactionReceipt(ctx, tx)
 		if err == ethereum.NotFound {
-			fmt.Printf("waiting for tx confirmation\n")
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(5 * time.Second):
-			}
+			ev.Stage = StageWaitingForInclusion
+			s.report(ev, "waiting for tx confirmation\n")
 		} else if err != nil {
-			return err
+			return nil, err
 		} else if receipt.Status != types.ReceiptStatusSuccessful {
-			return errors.New("unsuccessful withdrawal receipt status")
+			return nil, errors.New("unsuccessful withdrawal receipt status")
 		} else {
-			break
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				return nil, err
+			}
+			target := receipt.BlockNumber.Uint64() + s.Confirmations
+			ev.Stage, ev.Block = StageWaitingForConfirmation, receipt.BlockNumber.Uint64()
+			if head < target {
+				s.report(ev, "waiting for %d more confirmations of %s\n", target-head, tx.String())
+			} else if s.ConfirmTag == 0 {
+				ev.Stage = StageConfirmed
+				s.report(ev, "%s confirmed\n", tx.String())
+				return receipt, nil
+			} else {
+				tagged, err := client.HeaderByNumber(ctx, big.NewInt(s.ConfirmTag.Int64()))
+				if err != nil {
+					return nil, fmt.Errorf("error querying %s block: %w", s.ConfirmTag, err)
+				}
+				if tagged.Number.Cmp(receipt.BlockNumber) >= 0 {
+					ev.Stage = StageConfirmed
+					s.report(ev, "%s confirmed (%s)\n", tx.String(), s.ConfirmTag)
+					return receipt, nil
+				}
+				s.report(ev, "waiting for block %d of %s to become %s (at %d)\n", receipt.BlockNumber, tx.String(), s.ConfirmTag, tagged.Number)
+			}
+		}
+		if err := waiter.wait(ctx); err != nil {
+			return nil, err
 		}
 	}
-	fmt.Printf("%s confirmed\n", tx.String())
-	return nil
+}
+
+var failedRelayedMessageTopic = crypto.Keccak256Hash([]byte("FailedRelayedMessage(bytes32)"))
+
+// verifyFinalization checks the finalization receipt to make sure the withdrawal's call to its L1 target
+// actually succeeded. The portal marks a withdrawal as finalized even if that call fails, in which case
+// the funds are not delivered. Without a receipt, as the confirmation wasn't waited for, there is nothing to check.
+func (s TxSettings) verifyFinalization(receipt *types.Receipt, withdrawalHash common.Hash) error {
+	if receipt == nil {
+		return nil
+	}
+	// the WithdrawalFinalized event is identical in OptimismPortal and OptimismPortal2
+	portal, err := bindings.NewOptimismPortalFilterer(common.Address{}, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range receipt.Logs {
+		ev, err := portal.ParseWithdrawalFinalized(*l)
+		if err != nil || common.Hash(ev.WithdrawalHash) != withdrawalHash {
+			continue
+		}
+		if !ev.Success {
+			return fmt.Errorf("%w: withdrawal %s was finalized but the call to its L1 target failed - the withdrawn funds were NOT delivered", ErrDeliveryFailed, withdrawalHash)
+		}
+		// withdrawals sent through the bridge are relayed by the L1CrossDomainMessenger, which swallows failures of the
+		// inner call and holds on to the funds so the message can be replayed
+		for _, l := range receipt.Logs {
+			if len(l.Topics) > 1 && l.Topics[0] == failedRelayedMessageTopic {
+				return fmt.Errorf("%w: withdrawal %s was finalized but the L1CrossDomainMessenger at %s failed to relay message %s - the funds are held by the messenger until the message is replayed",
+					ErrDeliveryFailed, withdrawalHash, l.Address, l.Topics[1])
+			}
+		}
+		s.logger().Printf("Verified withdrawal %s was delivered to its L1 target\n", withdrawalHash.String())
+		return nil
+	}
+	return fmt.Errorf("no WithdrawalFinalized event for withdrawal %s found in finalization receipt %s", withdrawalHash, receipt.TxHash)
 }
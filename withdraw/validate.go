@@ -0,0 +1,72 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CheckWithdrawalTx checks the L2 transaction l2TxHash to initiate a withdrawal, so that wrong hashes are
+// reported upfront, with a hint of what the transaction is instead, rather than failing deep in the flow.
+// The returned error wraps ErrNotWithdrawal, or ErrWithdrawalFailed if it reverted. l1, if set, is used
+// to tell L1 transactions passed by mistake apart.
+func CheckWithdrawalTx(ctx context.Context, l1 L1Client, l2 L2Client, l2TxHash common.Hash) error {
+	receipt, err := l2.TransactionReceipt(ctx, l2TxHash)
+	if errors.Is(err, ethereum.NotFound) {
+		if l1 != nil {
+			if _, _, err := l1.TransactionByHash(ctx, l2TxHash); err == nil {
+				return fmt.Errorf("%w: %s is an L1 transaction, pass the hash of the L2 transaction initiating the withdrawal, not of a deposit, prove or finalize transaction", ErrNotWithdrawal, l2TxHash)
+			}
+		}
+		return fmt.Errorf("%w: %s was not found on L2, check it is the hash of the L2 transaction initiating the withdrawal, on the network selected with --network", ErrNotWithdrawal, l2TxHash)
+	}
+	if err != nil {
+		return fmt.Errorf("error querying withdrawal receipt: %w", err)
+	}
+	for _, l := range receipt.Logs {
+		if len(l.Topics) > 0 && l.Topics[0] == withdrawals.MessagePassedTopic && l.Address == predeploys.L2ToL1MessagePasserAddr {
+			return nil
+		}
+	}
+
+	// the transaction is only needed to explain what it is instead
+	getter, ok := l2.(interface {
+		TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	})
+	if !ok {
+		return notWithdrawal(l2TxHash, receipt, nil)
+	}
+	tx, _, err := getter.TransactionByHash(ctx, l2TxHash)
+	if err != nil {
+		return fmt.Errorf("error querying withdrawal tx: %w", err)
+	}
+	return notWithdrawal(l2TxHash, receipt, tx)
+}
+
+// notWithdrawal returns the error for the L2 transaction l2TxHash, which emitted no MessagePassed event,
+// explaining what it is if tx is set.
+func notWithdrawal(l2TxHash common.Hash, receipt *types.Receipt, tx *types.Transaction) error {
+	switch {
+	case tx == nil:
+	case tx.Type() == types.DepositTxType:
+		return fmt.Errorf("%w: %s is a deposit from L1, which is credited on L2 without being proven or finalized", ErrNotWithdrawal, l2TxHash)
+	case tx.To() == nil:
+		return fmt.Errorf("%w: %s deploys a contract", ErrNotWithdrawal, l2TxHash)
+	case len(tx.Data()) == 0 && *tx.To() != predeploys.L2StandardBridgeAddr && *tx.To() != predeploys.L2ToL1MessagePasserAddr:
+		return fmt.Errorf("%w: %s is a plain ETH transfer to %s, withdrawals are initiated through the L2StandardBridge (%s) or the L2ToL1MessagePasser (%s)",
+			ErrNotWithdrawal, l2TxHash, tx.To(), predeploys.L2StandardBridgeAddr, predeploys.L2ToL1MessagePasserAddr)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: %s reverted", ErrWithdrawalFailed, l2TxHash)
+	}
+	if tx != nil {
+		return fmt.Errorf("%w: %s calls %s without initiating a withdrawal, it emits no MessagePassed event of the L2ToL1MessagePasser", ErrNotWithdrawal, l2TxHash, tx.To())
+	}
+	return fmt.Errorf("%w: %s emits no MessagePassed event of the L2ToL1MessagePasser", ErrNotWithdrawal, l2TxHash)
+}
@@ -0,0 +1,206 @@
+package withdraw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum-optimism/optimism/op-node/withdrawals"
+	"github.com/ethereum-optimism/optimism/op-service/predeploys"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ViemWithdrawal is a withdrawal in the JSON shape of the ones returned by viem's getWithdrawals, so that
+// frontends built with viem and the withdrawer can hand withdrawals to each other. TransactionHash, the L2
+// transaction initiating the withdrawal, isn't part of viem's shape, and is optional when importing.
+type ViemWithdrawal struct {
+	Nonce           *ViemBigInt    `json:"nonce"`
+	Sender          common.Address `json:"sender"`
+	Target          common.Address `json:"target"`
+	Value           *ViemBigInt    `json:"value"`
+	GasLimit        *ViemBigInt    `json:"gasLimit"`
+	Data            hexutil.Bytes  `json:"data"`
+	WithdrawalHash  common.Hash    `json:"withdrawalHash"`
+	TransactionHash *common.Hash   `json:"transactionHash,omitempty"`
+}
+
+// ViemBigInt is a bigint as serialized from viem: written as a decimal string, and read from a decimal or
+// hex string, optionally with the n suffix of bigint literals, or a number.
+type ViemBigInt big.Int
+
+func (b *ViemBigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*big.Int)(b).String())
+}
+
+func (b *ViemBigInt) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSuffix(strings.Trim(string(data), `"`), "n")
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 {
+		return fmt.Errorf("invalid bigint %s", data)
+	}
+	*b = ViemBigInt(*v)
+	return nil
+}
+
+// Int returns b as a big.Int.
+func (b *ViemBigInt) Int() *big.Int {
+	if b == nil {
+		return nil
+	}
+	return (*big.Int)(b)
+}
+
+// NewViemWithdrawal converts the MessagePassed event ev, emitted by l2TxHash, to a ViemWithdrawal.
+func NewViemWithdrawal(l2TxHash common.Hash, ev *bindings.L2ToL1MessagePasserMessagePassed) *ViemWithdrawal {
+	return &ViemWithdrawal{
+		Nonce:           (*ViemBigInt)(ev.Nonce),
+		Sender:          ev.Sender,
+		Target:          ev.Target,
+		Value:           (*ViemBigInt)(ev.Value),
+		GasLimit:        (*ViemBigInt)(ev.GasLimit),
+		Data:            ev.Data,
+		WithdrawalHash:  ev.WithdrawalHash,
+		TransactionHash: &l2TxHash,
+	}
+}
+
+// GetViemWithdrawals returns the withdrawals initiated by the L2 transaction l2TxHash, as viem's
+// getWithdrawals does for its receipt.
+func GetViemWithdrawals(ctx context.Context, l2 *ethclient.Client, l2TxHash common.Hash) ([]*ViemWithdrawal, error) {
+	receipt, err := l2.TransactionReceipt(ctx, l2TxHash)
+	if err != nil {
+		return nil, err
+	}
+	events, err := messagePassedEvents(receipt)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("transaction %s initiates no withdrawal", l2TxHash)
+	}
+	result := make([]*ViemWithdrawal, len(events))
+	for i, ev := range events {
+		result[i] = NewViemWithdrawal(l2TxHash, ev)
+	}
+	return result, nil
+}
+
+// ParseViemWithdrawals parses a ViemWithdrawal, or a list of them, and checks their withdrawal hashes.
+func ParseViemWithdrawals(data []byte) ([]*ViemWithdrawal, error) {
+	var result []*ViemWithdrawal
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &result); err != nil {
+			return nil, err
+		}
+	} else {
+		var w ViemWithdrawal
+		if err := json.Unmarshal(trimmed, &w); err != nil {
+			return nil, err
+		}
+		result = append(result, &w)
+	}
+	for _, w := range result {
+		if err := w.check(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// check checks the withdrawal to have all fields, and its hash to match them.
+func (w *ViemWithdrawal) check() error {
+	if w.Nonce == nil || w.Value == nil || w.GasLimit == nil {
+		return errors.New("withdrawal is missing nonce, value or gasLimit")
+	}
+	hash, err := withdrawals.WithdrawalHash(w.event())
+	if err != nil {
+		return err
+	}
+	if w.WithdrawalHash != (common.Hash{}) && w.WithdrawalHash != hash {
+		return fmt.Errorf("withdrawalHash %s doesn't match the withdrawal fields, which hash to %s", w.WithdrawalHash, hash)
+	}
+	w.WithdrawalHash = hash
+	return nil
+}
+
+func (w *ViemWithdrawal) event() *bindings.L2ToL1MessagePasserMessagePassed {
+	return &bindings.L2ToL1MessagePasserMessagePassed{
+		Nonce:    w.Nonce.Int(),
+		Sender:   w.Sender,
+		Target:   w.Target,
+		Value:    w.Value.Int(),
+		GasLimit: w.GasLimit.Int(),
+		Data:     w.Data,
+	}
+}
+
+// FindL2Tx returns the L2 transaction initiating the withdrawal: its TransactionHash, or else the
+// transaction emitting the MessagePassed event with its nonce, found in the logs of the whole chain, which
+// not all RPCs allow. As helpers prove the first withdrawal of a transaction, it must be that one.
+func (w *ViemWithdrawal) FindL2Tx(ctx context.Context, l2 *ethclient.Client) (common.Hash, error) {
+	var l2TxHash common.Hash
+	if w.TransactionHash != nil {
+		l2TxHash = *w.TransactionHash
+	} else {
+		logs, err := l2.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: common.Big0,
+			Addresses: []common.Address{predeploys.L2ToL1MessagePasserAddr},
+			Topics:    [][]common.Hash{{withdrawals.MessagePassedTopic}, {common.BigToHash(w.Nonce.Int())}},
+		})
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error searching the L2 logs for the withdrawal, add its transactionHash: %w", err)
+		}
+		if len(logs) == 0 {
+			return common.Hash{}, fmt.Errorf("no L2 transaction initiates withdrawal %s", w.WithdrawalHash)
+		}
+		l2TxHash = logs[0].TxHash
+	}
+
+	receipt, err := l2.TransactionReceipt(ctx, l2TxHash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	events, err := messagePassedEvents(receipt)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	for i, ev := range events {
+		if ev.WithdrawalHash != w.WithdrawalHash {
+			continue
+		}
+		if i > 0 {
+			return common.Hash{}, fmt.Errorf("withdrawal %s is not the first one initiated by %s, which is the only one supported", w.WithdrawalHash, l2TxHash)
+		}
+		return l2TxHash, nil
+	}
+	return common.Hash{}, fmt.Errorf("transaction %s doesn't initiate withdrawal %s", l2TxHash, w.WithdrawalHash)
+}
+
+// messagePassedEvents returns the MessagePassed events emitted in receipt.
+func messagePassedEvents(receipt *types.Receipt) ([]*bindings.L2ToL1MessagePasserMessagePassed, error) {
+	passer, err := bindings.NewL2ToL1MessagePasserFilterer(common.Address{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	var events []*bindings.L2ToL1MessagePasserMessagePassed
+	for _, l := range receipt.Logs {
+		if l.Address != predeploys.L2ToL1MessagePasserAddr || len(l.Topics) == 0 || l.Topics[0] != withdrawals.MessagePassedTopic {
+			continue
+		}
+		ev, err := passer.ParseMessagePassed(*l)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse log: %w", err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
@@ -0,0 +1,115 @@
+package withdraw
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxHeadWait bounds the wait for a new head notification, in case the subscription silently stalls.
+const maxHeadWait = time.Minute
+
+// maxPollInterval caps the backoff between polls, unless the poll interval itself is longer.
+const maxPollInterval = 30 * time.Second
+
+// blockMargin is how long after a block is expected a poll is made, for the node to have imported it.
+const blockMargin = time.Second
+
+// headWaiter waits between checks for transaction confirmation: until the next block if the client
+// supports subscriptions (i.e. over WebSocket), or with polls backing off exponentially from the poll
+// interval otherwise. As receipts only change with new blocks, polls are made right after the block
+// expected last before they are due, once the L1 block time is known.
+type headWaiter struct {
+	client   L1Client
+	interval time.Duration
+	// delay is the backoff before the next poll.
+	delay time.Duration
+	heads chan *types.Header
+	sub   ethereum.Subscription
+	// blockTime is the L1 block time and lastBlock the time of a known block, zero until queried.
+	blockTime time.Duration
+	lastBlock time.Time
+	queried   bool
+}
+
+func newHeadWaiter(ctx context.Context, client L1Client, pollInterval time.Duration) *headWaiter {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	w := &headWaiter{client: client, interval: pollInterval, delay: pollInterval, heads: make(chan *types.Header, 1)}
+	// fails right away over HTTP, which doesn't support subscriptions
+	if sub, err := client.SubscribeNewHead(ctx, w.heads); err == nil {
+		w.sub = sub
+	}
+	return w
+}
+
+// wait returns once the next check is due.
+func (w *headWaiter) wait(ctx context.Context) error {
+	if w.sub != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.heads:
+			return nil
+		case <-time.After(max(w.interval, maxHeadWait)):
+			return nil
+		case <-w.sub.Err():
+			// the connection dropped, poll from now on
+			w.sub = nil
+		}
+	}
+	if !w.queried {
+		w.queried = true
+		w.queryBlockTime(ctx)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(w.next(time.Now())):
+		return nil
+	}
+}
+
+// next returns the time until the next poll, and backs off for the one after.
+func (w *headWaiter) next(now time.Time) time.Duration {
+	due := now.Add(w.delay)
+	w.delay = min(2*w.delay, max(w.interval, maxPollInterval))
+	if w.blockTime == 0 {
+		return due.Sub(now)
+	}
+	// the poll is made right after the last block expected before it is due, or the next block if none is
+	poll := w.lastBlock.Add(blockMargin)
+	if due.After(poll) {
+		poll = poll.Add(due.Sub(poll) / w.blockTime * w.blockTime)
+	}
+	for !poll.After(now) {
+		poll = poll.Add(w.blockTime)
+	}
+	return poll.Sub(now)
+}
+
+// queryBlockTime queries the time of the latest L1 block and the block time, from the time elapsed since
+// its parent. Polls aren't aligned to blocks if they can't be queried.
+func (w *headWaiter) queryBlockTime(ctx context.Context) {
+	head, err := w.client.HeaderByNumber(ctx, nil)
+	if err != nil || head.Number.Sign() == 0 {
+		return
+	}
+	parent, err := w.client.HeaderByNumber(ctx, new(big.Int).Sub(head.Number, big.NewInt(1)))
+	if err != nil || head.Time <= parent.Time {
+		return
+	}
+	w.blockTime = time.Duration(head.Time-parent.Time) * time.Second
+	w.lastBlock = time.Unix(int64(head.Time), 0)
+}
+
+// close ends the subscription, if any.
+func (w *headWaiter) close() {
+	if w.sub != nil {
+		w.sub.Unsubscribe()
+	}
+}
@@ -0,0 +1,54 @@
+package withdraw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeadWaiterNext(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name     string
+		interval time.Duration
+		// blockTime and lastBlock are the known L1 block time and time of the latest block, if any.
+		blockTime time.Duration
+		lastBlock time.Time
+		want      []time.Duration
+	}{
+		{
+			name:     "backs off up to the maximum",
+			interval: 5 * time.Second,
+			want:     []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		{
+			name:     "interval above the maximum",
+			interval: time.Minute,
+			want:     []time.Duration{time.Minute, time.Minute},
+		},
+		{
+			name:      "aligned to blocks",
+			interval:  5 * time.Second,
+			blockTime: 12 * time.Second,
+			lastBlock: now.Add(-3 * time.Second),
+			// right after the next block until the delay spans several blocks
+			want: []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 22 * time.Second, 22 * time.Second},
+		},
+		{
+			name:      "next block if none is expected before the poll is due",
+			interval:  time.Second,
+			blockTime: 12 * time.Second,
+			lastBlock: now.Add(-time.Second),
+			want:      []time.Duration{12 * time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &headWaiter{interval: tt.interval, delay: tt.interval, blockTime: tt.blockTime, lastBlock: tt.lastBlock}
+			for i, want := range tt.want {
+				if got := w.next(now); got != want {
+					t.Fatalf("next() call %d = %v, want %v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package withdraw
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"math/big"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/op-node/bindings"
@@ -11,208 +11,215 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/ethclient/gethclient"
-	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/sync/errgroup"
 )
 
 type Withdrawer struct {
-	Ctx      context.Context
-	L1Client *ethclient.Client
-	L2Client *rpc.Client
+	L1Client L1Client
+	L2Client L2Client
 	L2TxHash common.Hash
-	Portal   *bindings.OptimismPortal
+	Portal   PortalAdapter
 	Oracle   *bindings.L2OutputOracle
-	Opts     *bind.TransactOpts
+	// OracleAddress is the address of Oracle, whose constants and latest output are queried in batches.
+	OracleAddress common.Address
+	// CrossChecker, if set, checks the data transactions rely on against a second L1 provider.
+	CrossChecker *CrossChecker
+	// Rollup, if set, is the rollup node output roots are checked against before proving.
+	Rollup RollupClient
+	Opts   *bind.TransactOpts
+	TxSettings
+
+	receipt l2Receipt
 }
 
-func (w *Withdrawer) CheckIfProvable() error {
+func (w *Withdrawer) CheckIfProvable(ctx context.Context) error {
 	// check to make sure it is possible to prove the provided withdrawal
-	submissionInterval, err := w.Oracle.SUBMISSIONINTERVAL(&bind.CallOpts{})
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
 	if err != nil {
-		return fmt.Errorf("error querying output proposal submission interval: %w", err)
-	}
-
-	l2BlockTime, err := w.Oracle.L2BLOCKTIME(&bind.CallOpts{})
-	if err != nil {
-		return fmt.Errorf("error querying output proposal L2 block time: %w", err)
+		return err
 	}
-
-	l2OutputBlock, err := w.Oracle.LatestBlockNumber(&bind.CallOpts{})
-	if err != nil {
-		return fmt.Errorf("error querying latest proposed block: %w", err)
+	// the L1 and L2 queries are independent, so they are made concurrently
+	var submissionInterval, l2BlockTime, l2OutputBlock, l2WithdrawalBlock *big.Int
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		err := callBatch(gctx, w.L1Client,
+			batchCall{abi: oracleABI, to: w.OracleAddress, method: "SUBMISSION_INTERVAL", out: &submissionInterval, constant: true},
+			batchCall{abi: oracleABI, to: w.OracleAddress, method: "L2_BLOCK_TIME", out: &l2BlockTime, constant: true},
+			batchCall{abi: oracleABI, to: w.OracleAddress, method: "latestBlockNumber", out: &l2OutputBlock},
+		)
+		if err != nil {
+			return fmt.Errorf("error querying output proposals: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() (err error) {
+		if l2WithdrawalBlock, err = w.receipt.block(gctx, w.L2Client, w.L2TxHash); err != nil {
+			return fmt.Errorf("error querying withdrawal tx block: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return err
 	}
-
-	l2WithdrawalBlock, err := txBlock(w.Ctx, w.L2Client, w.L2TxHash)
-	if err != nil {
-		return fmt.Errorf("error querying withdrawal tx block: %w", err)
+	if err := w.checkL2BlockTag(ctx, w.L2Client, l2WithdrawalBlock); err != nil {
+		return err
 	}
 
 	if l2OutputBlock.Uint64() < l2WithdrawalBlock.Uint64() {
-		return fmt.Errorf("the latest L2 output is %d and is not past L2 block %d that includes the withdrawal, no withdrawal can be proved yet - please wait for the next proposal submission, which happens every %v",
-			l2OutputBlock.Uint64(), l2WithdrawalBlock.Uint64(), time.Duration(submissionInterval.Int64()*l2BlockTime.Int64())*time.Second)
+		return fmt.Errorf("%w: the latest L2 output is %d and is not past L2 block %d that includes the withdrawal, no withdrawal can be proved yet - please wait for the next proposal submission, which happens every %v",
+			ErrNotProvableYet, l2OutputBlock.Uint64(), l2WithdrawalBlock.Uint64(), time.Duration(submissionInterval.Int64()*l2BlockTime.Int64())*time.Second)
 	}
 	return nil
 }
 
-func (w *Withdrawer) GetProvenWithdrawalTime() (uint64, error) {
-	l2 := ethclient.NewClient(w.L2Client)
-	receipt, err := l2.TransactionReceipt(w.Ctx, w.L2TxHash)
-	if err != nil {
-		return 0, err
-	}
-
-	ev, err := withdrawals.ParseMessagePassed(receipt)
-	if err != nil {
-		return 0, err
-	}
+func (w *Withdrawer) WithdrawalHash(ctx context.Context) (common.Hash, error) {
+	return w.receipt.withdrawalHash(ctx, w.L2Client, w.L2TxHash)
+}
 
-	hash, err := withdrawals.WithdrawalHash(ev)
+func (w *Withdrawer) GetProvenWithdrawalTime(ctx context.Context) (uint64, error) {
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
 		return 0, err
 	}
 
-	provenWithdrawal, err := w.Portal.ProvenWithdrawals(&bind.CallOpts{}, hash)
+	proven, err := w.Portal.ProvenWithdrawal(&bind.CallOpts{Context: ctx}, hash, w.Opts.From)
 	if err != nil {
 		return 0, err
 	}
-
-	return provenWithdrawal.Timestamp.Uint64(), nil
+	return proven.Timestamp, nil
 }
 
-func (w *Withdrawer) ProveWithdrawal() error {
-	l2 := ethclient.NewClient(w.L2Client)
-	l2g := gethclient.New(w.L2Client)
+func (w *Withdrawer) ProofParameters(ctx context.Context) (withdrawals.ProvenWithdrawalParameters, error) {
+	l2 := w.L2Client
 
-	l2OutputBlock, err := w.Oracle.LatestBlockNumber(&bind.CallOpts{})
+	l2OutputBlock, err := w.Oracle.LatestBlockNumber(&bind.CallOpts{Context: ctx})
 	if err != nil {
-		return err
+		return withdrawals.ProvenWithdrawalParameters{}, err
 	}
 
 	// We generate a proof for the latest L2 output, which shouldn't require archive-node data if it's recent enough.
-	header, err := l2.HeaderByNumber(w.Ctx, l2OutputBlock)
+	header, err := l2.HeaderByNumber(ctx, l2OutputBlock)
 	if err != nil {
-		return err
+		return withdrawals.ProvenWithdrawalParameters{}, err
 	}
-	params, err := withdrawals.ProveWithdrawalParameters(w.Ctx, l2g, l2, l2, w.L2TxHash, header, &w.Oracle.L2OutputOracleCaller)
+	return withdrawals.ProveWithdrawalParameters(ctx, l2, w.receipt.client(w.L2Client, w.L2TxHash), l2, w.L2TxHash, header, &w.Oracle.L2OutputOracleCaller)
+}
+
+func (w *Withdrawer) ProveWithdrawal(ctx context.Context) (common.Hash, error) {
+	w.report(ProgressEvent{L2TxHash: w.L2TxHash, Action: "prove", Stage: StageGeneratingProof}, "Generating proof for %s\n", w.L2TxHash.String())
+	params, err := w.ProofParameters(ctx)
 	if err != nil {
-		return err
+		return common.Hash{}, err
+	}
+	if err := verifyWithdrawalProof(params); err != nil {
+		return common.Hash{}, err
+	}
+	if err := w.CrossChecker.checkOutput(ctx, params.L2OutputIndex, outputRoot(params.OutputRootProof)); err != nil {
+		return common.Hash{}, err
+	}
+	if w.Rollup != nil {
+		proposal, err := w.Oracle.GetL2Output(&bind.CallOpts{Context: ctx}, params.L2OutputIndex)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error querying output %d: %w", params.L2OutputIndex, err)
+		}
+		source := fmt.Sprintf("output %d", params.L2OutputIndex)
+		if err := checkRollupOutput(ctx, w.Rollup, proposal.L2BlockNumber.Uint64(), proposal.OutputRoot, source); err != nil {
+			return common.Hash{}, err
+		}
 	}
 
 	// Create the prove tx
-	tx, err := w.Portal.ProveWithdrawalTransaction(
-		w.Opts,
-		bindings.TypesWithdrawalTransaction{
-			Nonce:    params.Nonce,
-			Sender:   params.Sender,
-			Target:   params.Target,
-			Value:    params.Value,
-			GasLimit: params.GasLimit,
-			Data:     params.Data,
-		},
-		params.L2OutputIndex,
-		params.OutputRootProof,
-		params.WithdrawalProof,
-	)
+	txHash, _, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "prove", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Portal.ProveWithdrawalTransaction(opts, params)
+	})
+	return txHash, err
+}
+
+func (w *Withdrawer) IsProofFinalized(ctx context.Context) (bool, error) {
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	fmt.Printf("Proved withdrawal for %s: %s\n", w.L2TxHash.String(), tx.Hash().String())
-
-	// Wait 5 mins max for confirmation
-	ctxWithTimeout, cancel := context.WithTimeout(w.Ctx, 5*time.Minute)
-	defer cancel()
-	return waitForConfirmation(ctxWithTimeout, w.L1Client, tx.Hash())
-}
-
-func (w *Withdrawer) IsProofFinalized() (bool, error) {
-	return w.Portal.FinalizedWithdrawals(&bind.CallOpts{}, w.L2TxHash)
+	return w.Portal.IsWithdrawalFinalized(&bind.CallOpts{Context: ctx}, hash)
 }
 
-func (w *Withdrawer) FinalizeWithdrawal() error {
-	l2 := ethclient.NewClient(w.L2Client)
-	l2g := gethclient.New(w.L2Client)
+func (w *Withdrawer) CheckIfFinalizable(ctx context.Context) error {
+	l2 := w.L2Client
 
 	// Figure out when our withdrawal was included
-	receipt, err := l2.TransactionReceipt(w.Ctx, w.L2TxHash)
+	receipt, err := w.receipt.get(ctx, w.L2Client, w.L2TxHash)
 	if err != nil {
 		return fmt.Errorf("cannot get receipt for withdrawal tx %s: %v", w.L2TxHash, err)
 	}
 	if receipt.Status != types.ReceiptStatusSuccessful {
-		return errors.New("unsuccessful withdrawal receipt status")
+		return ErrWithdrawalFailed
 	}
 
-	l2WithdrawalBlock, err := l2.HeaderByNumber(w.Ctx, receipt.BlockNumber)
+	l2WithdrawalBlock, err := l2.HeaderByNumber(ctx, receipt.BlockNumber)
 	if err != nil {
 		return fmt.Errorf("error getting header by number for block %s: %v", receipt.BlockNumber, err)
 	}
 
 	// Figure out what the Output oracle on L1 has seen so far
-	l2OutputBlockNr, err := w.Oracle.LatestBlockNumber(&bind.CallOpts{})
+	l2OutputBlockNr, err := w.Oracle.LatestBlockNumber(&bind.CallOpts{Context: ctx})
 	if err != nil {
 		return err
 	}
 
-	l2OutputBlock, err := l2.HeaderByNumber(w.Ctx, l2OutputBlockNr)
+	l2OutputBlock, err := l2.HeaderByNumber(ctx, l2OutputBlockNr)
 	if err != nil {
 		return fmt.Errorf("error getting header by number for latest block %s: %v", l2OutputBlockNr, err)
 	}
 
 	// Check if the L2 output is even old enough to include the withdrawal
 	if l2OutputBlock.Number.Uint64() < l2WithdrawalBlock.Number.Uint64() {
-		return fmt.Errorf("the latest L2 output is %d and is not past L2 block %d that includes the withdrawal yet, no withdrawal can be completed yet", l2OutputBlock.Number.Uint64(), l2WithdrawalBlock.Number.Uint64())
+		return fmt.Errorf("%w: the latest L2 output is %d and is not past L2 block %d that includes the withdrawal yet, no withdrawal can be completed yet", ErrNotProvableYet, l2OutputBlock.Number.Uint64(), l2WithdrawalBlock.Number.Uint64())
 	}
 
-	l1Head, err := w.L1Client.HeaderByNumber(w.Ctx, nil)
+	l1Head, err := w.L1Client.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return err
 	}
 
 	// Check if the withdrawal may be completed yet
-	finalizationPeriod, err := w.Oracle.FINALIZATIONPERIODSECONDS(&bind.CallOpts{})
+	finalizationPeriod, err := w.finalizationPeriod(ctx)
 	if err != nil {
 		return err
 	}
 
 	if l2WithdrawalBlock.Time+finalizationPeriod.Uint64() >= l1Head.Time {
-		return fmt.Errorf("withdrawal tx %s was included in L2 block %d (time %d) but L1 only knows of L2 proposal %d (time %d) at head %d (time %d) which has not reached output confirmation yet (period is %d)",
-			w.L2TxHash, l2WithdrawalBlock.Number.Uint64(), l2WithdrawalBlock.Time, l2OutputBlock.Number.Uint64(), l2OutputBlock.Time, l1Head.Number.Uint64(), l1Head.Time, finalizationPeriod.Uint64())
+		return fmt.Errorf("%w: withdrawal tx %s was included in L2 block %d (time %d) but L1 only knows of L2 proposal %d (time %d) at head %d (time %d) which has not reached output confirmation yet (period is %d)",
+			ErrChallengePeriodActive, w.L2TxHash, l2WithdrawalBlock.Number.Uint64(), l2WithdrawalBlock.Time, l2OutputBlock.Number.Uint64(), l2OutputBlock.Time, l1Head.Number.Uint64(), l1Head.Time, finalizationPeriod.Uint64())
 	}
+	return nil
+}
 
-	// We generate a proof for the latest L2 output, which shouldn't require archive-node data if it's recent enough.
-	// Note that for the `FinalizeWithdrawalTransaction` function, this proof isn't needed. We simply use some of the
-	// params for the `WithdrawalTransaction` type generated in the bindings.
-	header, err := l2.HeaderByNumber(w.Ctx, l2OutputBlockNr)
+func (w *Withdrawer) FinalizeWithdrawal(ctx context.Context) (common.Hash, error) {
+	if err := w.CheckIfFinalizable(ctx); err != nil {
+		return common.Hash{}, err
+	}
+
+	hash, err := w.WithdrawalHash(ctx)
 	if err != nil {
-		return err
+		return common.Hash{}, err
+	}
+	if err := w.CrossChecker.checkProven(ctx, w.L1Client, hash, w.Opts.From); err != nil {
+		return common.Hash{}, err
 	}
 
-	params, err := withdrawals.ProveWithdrawalParameters(w.Ctx, l2g, l2, l2, w.L2TxHash, header, &w.Oracle.L2OutputOracleCaller)
+	// the `FinalizeWithdrawalTransaction` function doesn't need a proof, only the withdrawal itself
+	params, err := w.receipt.withdrawalParams(ctx, w.L2Client, w.L2TxHash)
 	if err != nil {
-		return err
+		return common.Hash{}, err
 	}
 
 	// Create the withdrawal tx
-	tx, err := w.Portal.FinalizeWithdrawalTransaction(
-		w.Opts,
-		bindings.TypesWithdrawalTransaction{
-			Nonce:    params.Nonce,
-			Sender:   params.Sender,
-			Target:   params.Target,
-			Value:    params.Value,
-			GasLimit: params.GasLimit,
-			Data:     params.Data,
-		},
-	)
+	txHash, l1Receipt, err := w.submit(ctx, w.L1Client, w.Opts, w.L2TxHash, "finalize", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return w.Portal.FinalizeWithdrawalTransaction(opts, params)
+	})
 	if err != nil {
-		return err
+		return txHash, err
 	}
-
-	fmt.Printf("Completed withdrawal for %s: %s\n", w.L2TxHash.String(), tx.Hash().String())
-
-	// Wait 5 mins max for confirmation
-	ctxWithTimeout, cancel := context.WithTimeout(w.Ctx, 5*time.Minute)
-	defer cancel()
-	return waitForConfirmation(ctxWithTimeout, w.L1Client, tx.Hash())
+	return txHash, w.verifyFinalization(l1Receipt, hash)
 }
@@ -0,0 +1,294 @@
+package withdraw
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/op-node/bindings"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/mock/gomock"
+
+	"github.com/base-org/withdrawer/withdraw/mocks"
+)
+
+var testOracle = common.HexToAddress("0x7777777777777777777777777777777777777777")
+
+// finalizationPeriod is the FINALIZATION_PERIOD_SECONDS of the test oracle.
+const finalizationPeriod = 7 * 24 * 60 * 60
+
+// oracleTest configures the L1 and L2 state a Withdrawer is tested against.
+type oracleTest struct {
+	// receipt is the receipt of the withdrawal transaction, or receiptErr the error querying it.
+	receipt    *types.Receipt
+	receiptErr error
+	// latestBlock is the L2 block of the latest output, or latestErr the error querying it.
+	latestBlock uint64
+	latestErr   error
+	// l1Time is the time of the L1 head.
+	l1Time uint64
+}
+
+// newTestWithdrawer returns a Withdrawer of w against the L2OutputOracle state of tt, proving and
+// finalizing through portal.
+func newTestWithdrawer(t *testing.T, w *testWithdrawal, tt oracleTest, portal PortalAdapter) (*Withdrawer, *mocks.MockL1Client) {
+	ctrl := gomock.NewController(t)
+	l1 := mocks.NewMockL1Client(ctrl)
+	l2 := mocks.NewMockL2Client(ctrl)
+	w.expectL2(l2, tt.receipt, tt.receiptErr)
+
+	oracleABI, err := bindings.L2OutputOracleMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle := contract{
+		abi: oracleABI,
+		outputs: map[string][]interface{}{
+			"SUBMISSION_INTERVAL":         {big.NewInt(1800)},
+			"L2_BLOCK_TIME":               {big.NewInt(2)},
+			"FINALIZATION_PERIOD_SECONDS": {big.NewInt(finalizationPeriod)},
+			"latestBlockNumber":           {new(big.Int).SetUint64(tt.latestBlock)},
+			"getL2OutputIndexAfter":       {big.NewInt(3)},
+		},
+		errs: map[string]error{},
+	}
+	if tt.latestErr != nil {
+		oracle.errs["latestBlockNumber"] = tt.latestErr
+	}
+	expectCalls(t, l1, map[common.Address]contract{testOracle: oracle})
+	l1.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100), Time: tt.l1Time}, nil).AnyTimes()
+
+	oracleBinding, err := bindings.NewL2OutputOracle(testOracle, l1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Withdrawer{
+		L1Client:      l1,
+		L2Client:      l2,
+		L2TxHash:      w.txHash,
+		Portal:        portal,
+		Oracle:        oracleBinding,
+		OracleAddress: testOracle,
+		Opts:          &bind.TransactOpts{From: testSender},
+		TxSettings:    testSettings,
+	}, l1
+}
+
+// failedReceipt returns a copy of receipt with a failed status.
+func failedReceipt(receipt *types.Receipt) *types.Receipt {
+	failed := *receipt
+	failed.Status = types.ReceiptStatusFailed
+	return &failed
+}
+
+func TestWithdrawerCheckIfProvable(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		test    oracleTest
+		wantErr error
+	}{
+		{
+			name: "provable",
+			test: oracleTest{receipt: w.receipt, latestBlock: 20},
+		},
+		{
+			name: "output at the withdrawal block",
+			test: oracleTest{receipt: w.receipt, latestBlock: 10},
+		},
+		{
+			name:    "no output past the withdrawal",
+			test:    oracleTest{receipt: w.receipt, latestBlock: 9},
+			wantErr: ErrNotProvableYet,
+		},
+		{
+			name:    "receipt error",
+			test:    oracleTest{receiptErr: errRPC, latestBlock: 20},
+			wantErr: errRPC,
+		},
+		{
+			name:    "failed withdrawal",
+			test:    oracleTest{receipt: failedReceipt(w.receipt), latestBlock: 20},
+			wantErr: ErrWithdrawalFailed,
+		},
+		{
+			name:    "oracle error",
+			test:    oracleTest{receipt: w.receipt, latestErr: errRPC},
+			wantErr: errRPC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, _ := newTestWithdrawer(t, w, tt.test, &fakePortal{})
+			err := withdrawer.CheckIfProvable(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckIfProvable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithdrawerCheckIfFinalizable(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	// the time the withdrawal block is finalizable after
+	finalizable := l2Header(10).Time + finalizationPeriod
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		test    oracleTest
+		wantErr error
+		// wantMessage is whether the error is only reported by message, not wrapped.
+		wantMessage bool
+	}{
+		{
+			name: "finalizable",
+			test: oracleTest{receipt: w.receipt, latestBlock: 20, l1Time: finalizable + 1},
+		},
+		{
+			name:    "challenge period active",
+			test:    oracleTest{receipt: w.receipt, latestBlock: 20, l1Time: finalizable},
+			wantErr: ErrChallengePeriodActive,
+		},
+		{
+			name:    "no output past the withdrawal",
+			test:    oracleTest{receipt: w.receipt, latestBlock: 9, l1Time: finalizable + 1},
+			wantErr: ErrNotProvableYet,
+		},
+		{
+			name:    "failed withdrawal",
+			test:    oracleTest{receipt: failedReceipt(w.receipt), latestBlock: 20, l1Time: finalizable + 1},
+			wantErr: ErrWithdrawalFailed,
+		},
+		{
+			name:        "receipt error",
+			test:        oracleTest{receiptErr: errRPC, latestBlock: 20, l1Time: finalizable + 1},
+			wantErr:     errRPC,
+			wantMessage: true,
+		},
+		{
+			name:    "oracle error",
+			test:    oracleTest{receipt: w.receipt, latestErr: errRPC, l1Time: finalizable + 1},
+			wantErr: errRPC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, _ := newTestWithdrawer(t, w, tt.test, &fakePortal{})
+			err := withdrawer.CheckIfFinalizable(context.Background())
+			if tt.wantMessage {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr.Error()) {
+					t.Fatalf("CheckIfFinalizable() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckIfFinalizable() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithdrawerProveWithdrawal(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	errRPC := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		portal  *fakePortal
+		sendErr error
+		status  uint64
+		wantErr error
+	}{
+		{
+			name:   "proven",
+			portal: &fakePortal{},
+			status: types.ReceiptStatusSuccessful,
+		},
+		{
+			name:    "estimate reverted",
+			portal:  &fakePortal{sendErr: dataError{data: revertData(t, "Error(string)", stringArgs(), "OptimismPortal: withdrawal has already been finalized")}},
+			wantErr: ErrAlreadyFinalized,
+		},
+		{
+			name:    "send error",
+			portal:  &fakePortal{},
+			sendErr: errRPC,
+			wantErr: errRPC,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawer, l1 := newTestWithdrawer(t, w, oracleTest{receipt: w.receipt, latestBlock: 20}, tt.portal)
+			expectSend(l1, tt.sendErr, tt.status, nil)
+			txHash, err := withdrawer.ProveWithdrawal(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ProveWithdrawal() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if txHash != testTx().Hash() {
+				t.Fatalf("ProveWithdrawal() = %s, want %s", txHash, testTx().Hash())
+			}
+			// the withdrawal is proven against output 3, the first after the latest L2 output block
+			want := w.params(3)
+			if got := tt.portal.proved; got == nil || got.L2OutputIndex.Cmp(want.L2OutputIndex) != 0 || got.OutputRootProof != want.OutputRootProof || got.Value.Cmp(want.Value) != 0 {
+				t.Fatalf("proved %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithdrawerFinalizeWithdrawal(t *testing.T) {
+	w := newTestWithdrawal(t, 10, 20)
+	finalizable := l2Header(10).Time + finalizationPeriod + 1
+	tests := []struct {
+		name    string
+		l1Time  uint64
+		status  uint64
+		logs    []*types.Log
+		wantErr error
+		// wantFinalized is whether a finalize transaction is sent.
+		wantFinalized bool
+	}{
+		{
+			name:          "finalized",
+			l1Time:        finalizable,
+			status:        types.ReceiptStatusSuccessful,
+			logs:          []*types.Log{finalizedLog(t, w.hash, true)},
+			wantFinalized: true,
+		},
+		{
+			name:          "delivery failed",
+			l1Time:        finalizable,
+			status:        types.ReceiptStatusSuccessful,
+			logs:          []*types.Log{finalizedLog(t, w.hash, false)},
+			wantErr:       ErrDeliveryFailed,
+			wantFinalized: true,
+		},
+		{
+			name:    "challenge period active",
+			l1Time:  finalizable - 1,
+			wantErr: ErrChallengePeriodActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portal := &fakePortal{}
+			withdrawer, l1 := newTestWithdrawer(t, w, oracleTest{receipt: w.receipt, latestBlock: 20, l1Time: tt.l1Time}, portal)
+			expectSend(l1, nil, tt.status, tt.logs)
+			_, err := withdrawer.FinalizeWithdrawal(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FinalizeWithdrawal() error = %v, want %v", err, tt.wantErr)
+			}
+			if finalized := portal.finalized != nil; finalized != tt.wantFinalized {
+				t.Fatalf("finalize tx sent = %v, want %v", finalized, tt.wantFinalized)
+			}
+			if portal.finalized != nil && portal.finalized.Nonce.Cmp(w.event.Nonce) != 0 {
+				t.Fatalf("finalized withdrawal with nonce %s, want %s", portal.finalized.Nonce, w.event.Nonce)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/term"
+
+	"github.com/base-org/withdrawer/pkg/withdrawer"
+)
+
+// runWizard implements the wizard subcommand, which asks for the network, the withdrawal and how to sign
+// step by step, with explanations, then runs the next step of the withdrawal as the default command would.
+// The equivalent command line is printed, without secrets, for running it again later.
+func runWizard(args []string) {
+	fs := flag.NewFlagSet("wizard", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		log.Crit("The wizard subcommand must be run in a terminal, pass the flags of the default command in scripts")
+	}
+	in := bufio.NewReader(os.Stdin)
+	fmt.Println("This wizard proves or finalizes a withdrawal from an OP Stack chain to Ethereum, whichever is next.")
+	fmt.Println("A withdrawal is proven once the L2 block it was made in is proposed on L1 (usually within an")
+	fmt.Println("hour), and can be finalized once the challenge period has passed (about 7 days on mainnets).")
+	fmt.Println("Run the wizard again to perform each step. Press Ctrl-C at any time to quit.")
+
+	var names []string
+	for name := range withdrawer.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("\nWhich network did you withdraw from?")
+	for i, name := range names {
+		fmt.Printf("  %d) %s\n", i+1, name)
+	}
+	name := names[askChoice(in, len(names), indexOf(names, "base-mainnet"))]
+	n := withdrawer.Networks[name]
+
+	fmt.Println("\nThe withdrawer sends transactions to Ethereum (the L1), so it needs the URL of an Ethereum RPC")
+	fmt.Println("node, such as one from Alchemy, Infura or QuickNode. Make sure it is for the right chain: Sepolia")
+	fmt.Println("for testnets.")
+	rpc := ask(in, "L1 RPC URL", func(s string) error {
+		if !strings.Contains(s, "://") {
+			return fmt.Errorf("expected a URL like https://eth-mainnet.example.com/<key>")
+		}
+		return nil
+	})
+
+	fmt.Println("\nThe withdrawal is the L2 transaction you withdrew in, e.g. through the bridge. Its hash can be")
+	fmt.Printf("found in your wallet's history or on %s.\n", n.L2Explorer)
+	withdrawal := ask(in, "Withdrawal transaction hash", func(s string) error {
+		if b, err := hexutil.Decode(s); err != nil || len(b) != common.HashLength {
+			return fmt.Errorf("expected a 0x-prefixed hash of 64 hex characters")
+		}
+		return nil
+	})
+
+	fmt.Println("\nThe prove and finalize transactions must be signed by an account with ETH on L1 to pay for gas.")
+	fmt.Println("It doesn't have to be the account that withdrew: the funds are always sent to the withdrawal's")
+	fmt.Println("recipient. How do you want to sign?")
+	fmt.Println("  1) Ledger hardware wallet (recommended, the key never leaves the device)")
+	fmt.Println("  2) Mnemonic (seed phrase), typed here without being shown")
+	fmt.Println("  3) Private key, typed here without being shown")
+	runArgs := []string{"--network", name, "--rpc", rpc, "--withdrawal", withdrawal}
+	if n.FaultProofs {
+		runArgs = append(runArgs, "--fault-proofs")
+	}
+	// the secrets are only passed to the default command, not printed or kept in the shell history
+	shown := make([]string, len(runArgs))
+	for i, arg := range runArgs {
+		shown[i] = shellQuote(arg)
+	}
+	switch askChoice(in, 3, 0) {
+	case 0:
+		fmt.Println("\nConnect the Ledger, unlock it and open the Ethereum app. The default derivation path is the")
+		fmt.Println("first account of Ledger Live.")
+		hdPath := askDefault(in, "Derivation path", "m/44'/60'/0'/0/0")
+		runArgs = append(runArgs, "--ledger", "--hd-path", hdPath)
+		shown = append(shown, "--ledger", "--hd-path", shellQuote(hdPath))
+	case 1:
+		mnemonic := askSecret("Mnemonic")
+		hdPath := askDefault(in, "Derivation path", "m/44'/60'/0'/0/0")
+		runArgs = append(runArgs, "--mnemonic", mnemonic, "--hd-path", hdPath)
+		shown = append(shown, "--mnemonic", "<mnemonic>", "--hd-path", shellQuote(hdPath))
+	case 2:
+		key := askSecret("Private key")
+		// the key isn't exposed on the command line, which is what --i-accept-key-risk is about
+		runArgs = append(runArgs, "--private-key", key, "--i-accept-key-risk")
+		shown = append(shown, "--private-key", "<private key>")
+	}
+
+	fmt.Println("\nThe equivalent command is:")
+	fmt.Printf("  withdrawer %s\n", strings.Join(shown, " "))
+	if strings.ToLower(askDefault(in, "Run it now? (yes/no)", "yes")) != "yes" {
+		return
+	}
+	fmt.Println()
+	runWithdraw(runArgs)
+}
+
+// ask prompts for a value until a non-empty one passing check is entered.
+func ask(in *bufio.Reader, prompt string, check func(string) error) string {
+	for {
+		value := askDefault(in, prompt, "")
+		if value == "" {
+			continue
+		}
+		if err := check(value); err != nil {
+			fmt.Printf("Invalid value, %v\n", err)
+			continue
+		}
+		return value
+	}
+}
+
+// askDefault prompts for a value, returning def if none is entered.
+func askDefault(in *bufio.Reader, prompt, def string) string {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", prompt, def)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil {
+		log.Crit("Error reading answer", "error", err)
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def
+	}
+	return line
+}
+
+// askChoice prompts for one of n numbered options, returning the index of the chosen one, def by default.
+func askChoice(in *bufio.Reader, n, def int) int {
+	for {
+		i, err := strconv.Atoi(askDefault(in, "Choice", strconv.Itoa(def+1)))
+		if err == nil && i >= 1 && i <= n {
+			return i - 1
+		}
+		fmt.Printf("Invalid choice, expected a number from 1 to %d\n", n)
+	}
+}
+
+// askSecret prompts for a value without echoing it.
+func askSecret(prompt string) string {
+	for {
+		fmt.Printf("%s (hidden): ", prompt)
+		value, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Println()
+		if err != nil {
+			log.Crit("Error reading answer", "error", err)
+		}
+		if s := strings.TrimSpace(string(value)); s != "" {
+			return s
+		}
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return 0
+}
+
+// shellQuote quotes arg if the shell would split or expand it.
+func shellQuote(arg string) string {
+	if strings.ContainsAny(arg, " '\"$&;|<>*?()\\`") {
+		return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return arg
+}